@@ -3,6 +3,8 @@ package main
 import (
 	game "UTC_IA04/cmd/simu"
 	"UTC_IA04/cmd/ui"
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/simulation"
 	"image/color"
 	"strconv"
 
@@ -73,10 +75,10 @@ func main() {
 		Y:             secondRow,
 		Width:         fieldWidth,
 		Height:        fieldHeight,
-		Options:       []string{"Protocole test 1", "Protocole test 2", "Protocole test 3", "Protocole test 4"},
+		Options:       append(drones.ProtocolNames(), simulation.MAP_PROTOCOLS),
 		SelectedIndex: 0,
 		OnSelect: func(index int) {
-			println("Selected Protocol:", index+1)
+			println("Selected Protocol:", g.DropdownProtocole.Options[index])
 		},
 	}
 
@@ -110,7 +112,7 @@ func main() {
 			g.Sim.UpdateMap(chosenMap)
 			g.Sim.UpdateCrowdSize(g.PeopleCount)
 			g.Sim.UpdateDroneSize(g.DroneCount)
-			g.Sim.UpdateDroneProtocole(g.DropdownProtocole.SelectedIndex + 1)
+			g.Sim.UpdateDroneProtocole(g.DropdownProtocole.Options[g.DropdownProtocole.SelectedIndex])
 			g.Sim.InitDronesProtocols()
 
 			g.Mode = game.Simulation
@@ -146,7 +148,7 @@ func main() {
 			g.Sim.UpdateMap(chosenMap)
 			g.Sim.UpdateCrowdSize(g.PeopleCount)
 			g.Sim.UpdateDroneSize(g.DroneCount)
-			g.Sim.UpdateDroneProtocole(g.DropdownProtocole.SelectedIndex + 1)
+			g.Sim.UpdateDroneProtocole(g.DropdownProtocole.Options[g.DropdownProtocole.SelectedIndex])
 			g.Sim.InitDronesProtocols()
 
 			g.Mode = game.SimulationDebug
@@ -181,6 +183,18 @@ func main() {
 		},
 	}
 
+	g.EvacuateButton = ui.Button{
+		X:      windowWidth * 0.75, // 75% from left
+		Y:      windowHeight * 0.5, // 50% from top
+		Width:  windowWidth * 0.2,  // 20% of window width
+		Height: fieldHeight,
+		Text:   "Evacuate",
+		OnClick: func() {
+			g.Sim.TriggerEvacuation(nil)
+			g.EvacuateButton.Text = "Evacuating"
+		},
+	}
+
 	ebiten.SetWindowSize(int(windowWidth), int(windowHeight))
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeDisabled)
 	ebiten.SetWindowTitle("Simulation Drones")
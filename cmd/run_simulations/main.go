@@ -1,12 +1,18 @@
 package main
 
 import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
 	"UTC_IA04/pkg/simulation"
+	"flag"
 	"fmt"
 	"image/color"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"gonum.org/v1/plot"
@@ -15,10 +21,26 @@ import (
 )
 
 type SimulationConfig struct {
-	NumDrones int
-	NumPeople int
-	Protocol  int
-	MapName   string
+	NumDrones    int
+	NumPeople    int
+	Protocol     string // Registered protocol name, or a comma-separated mix
+	DroneTypes   string // Drone types of configs/drones given in turn, comma-separated
+	MapName      string
+	Weather      string
+	Evacuation   string
+	StaticZones  bool // Keep the patrol zones of the creation instead of rebalancing them
+	Reservations bool // Drones book a charging pad before flying to a station
+	Fleet        simulation.FleetSchedule
+	Faults       string            // Fault scenario of configs/faults
+	FaultRates   models.FaultRates // Random failures, on top of the scenario
+	IdealRadio   bool              // Deliver the messages at once, without any loss
+	Sensor       string            // Sensor model of configs/sensors, empty for the default camera
+	Confirmation drones.ConfirmationPolicy
+	Kits         int // First aid kits per medical tent for the drones
+	AEDs         int // AEDs per medical tent for the drones
+	Relays       int // Drones taken off patrol to link the cut-off zones, 0 to never
+	Separation   drones.SeparationRule
+	Planner      bool // Plan the patrol on a belief map shared over the radio
 }
 
 type AggregatedMetrics struct {
@@ -31,9 +53,57 @@ type AggregatedMetrics struct {
 	Runtime         time.Duration
 	TotalTicks      int
 	RescueStats     simulation.SimulationRescueStats
+	Evacuated       bool
+	EvacuationTime  float64
+	PeakDensity     float64
+	ExitThroughput  map[string]float64
+	CrowdRisk       simulation.CrowdRiskStats
+	Search          simulation.SearchStats
+	Coverage        simulation.CoverageStats
+	Charging        simulation.ChargingStats
+	Fleet           simulation.FleetStats
+	Faults          simulation.FaultStats
+	Radio           simulation.RadioStats
+	Bus             simulation.BusStats
+	Sensor          simulation.SensorStats
+	Confirmation    simulation.ConfirmationStats
+	Delivery        simulation.DeliveryStats
+	Relays          simulation.RelayStats
+	Separation      simulation.SeparationStats
+	Patrol          simulation.PatrolStats
 }
 
 func main() {
+	evacuation := flag.String("evacuation", "", "evacuation scenario of configs/evacuation played in every run")
+	protocolList := flag.String("protocols", strings.Join(drones.ProtocolNames(), ";"),
+		"drone protocols to compare, separated by ';', each one can mix protocols with ',' (e.g. \"multi-hop,basic\")")
+	typeList := flag.String("drone-types", drones.DefaultDroneType.Name,
+		"fleet compositions to compare, separated by ';', each one gives the types of configs/drones to the drones in turn with ',' (e.g. \"scout,relay,carrier\")")
+	staticZones := flag.Bool("static-zones", false, "keep the patrol zones of the creation instead of rebalancing them among the flying drones")
+	reservations := flag.Bool("reservations", false, "let the drones book a charging pad before flying to a station")
+	minAirborne := flag.Int("min-airborne", 0, "drones the fleet scheduler keeps in the air, 0 to let each drone charge on its own")
+	minCoverage := flag.Float64("min-coverage", 0, "share of the map (0-1) the fleet scheduler keeps in sight")
+	hotSwap := flag.Bool("hot-swap", false, "swap the battery for a charged spare at the stations instead of charging on a pad")
+	spares := flag.Int("spares", 0, "spare batteries per station and drone type in hot-swap mode, one per pad if 0")
+	faults := flag.String("faults", "", "fault scenario of configs/faults played in every run")
+	faultRate := flag.Float64("fault-rate", 0, "probability per tick that a flying drone, or a rescue point, fails, for each kind of failure")
+	idealRadio := flag.Bool("ideal-radio", false, "deliver the drone messages at once, without latency, loss nor bandwidth limit")
+	sensor := flag.String("sensor", "", "sensor model of configs/sensors given to the drones, the default camera if empty")
+	confirmThreshold := flag.Float64("confirm-threshold", 0, "confidence (0-1) under which a drone confirms a read before reporting it, 0 to report every read")
+	confirmTicks := flag.Int("confirm-ticks", 5, "ticks a drone loiters over an uncertain incident, or waits for its peer, to confirm it")
+	confirmPeer := flag.Bool("confirm-peer", false, "ask the closest neighbour to confirm an uncertain incident instead of loitering")
+	kits := flag.Int("kits", simulation.FIRST_AID_KITS_PER_TENT, "first aid kits each medical tent keeps for the kit-delivery drones")
+	aeds := flag.Int("aeds", simulation.AEDS_PER_TENT, "AEDs each medical tent keeps for the kit-delivery drones")
+	relays := flag.Int("relays", 0, "drones the simulation may take off patrol to link the cut-off zones to a rescue point, 0 to never")
+	separationDist := flag.Float64("separation", 0, "minimum distance (cells) between two drones of the same altitude layer, 0 to let them share a cell")
+	layers := flag.Int("layers", 1, "altitude layers the drones are spread over, the separation holds within a layer")
+	planner := flag.Bool("planner", false, "plan the patrol on a belief map shared over the radio instead of sweeping the zones")
+	flag.Parse()
+	faultRates := models.FaultRates{Crash: *faultRate, Sensor: *faultRate, Radio: *faultRate, GPSDrift: *faultRate, RescuePointOffline: *faultRate}
+	fleet := simulation.FleetSchedule{MinAirborne: *minAirborne, MinCoverage: *minCoverage, HotSwap: *hotSwap, SpareBatteries: *spares}
+	confirmation := drones.ConfirmationPolicy{Threshold: *confirmThreshold, Ticks: *confirmTicks, AskPeer: *confirmPeer}
+	separation := drones.SeparationRule{MinDistance: *separationDist, Layers: *layers}
+
 	// Create results directory in the current project directory
 	resultsDir := filepath.Join(".", "results")
 	if err := os.MkdirAll(resultsDir, 0755); err != nil {
@@ -47,32 +117,106 @@ func main() {
 	// Configuration parameters
 	droneConfigs := []int{2, 5, 10}
 	peopleConfigs := []int{200, 500, 1000}
-	protocolConfigs := []int{1, 2, 3, 4}
+	protocolConfigs := strings.Split(*protocolList, ";")
+	typeConfigs := strings.Split(*typeList, ";")
+	standardType := drones.DefaultDroneType.Name
 	mapConfigs := []string{"festival_layout_1", "festival_layout_2", "festival_layout_3"}
+	weatherConfigs := []string{"mild", "heatwave"}
 
 	// Run simulations for each configuration
 	for _, drones := range droneConfigs {
 		for _, people := range peopleConfigs {
 			for _, protocol := range protocolConfigs {
-				for _, mapName := range mapConfigs {
-					config := SimulationConfig{
-						NumDrones: drones,
-						NumPeople: people,
-						Protocol:  protocol,
-						MapName:   mapName,
-					}
+				for _, droneTypes := range typeConfigs {
+					for _, mapName := range mapConfigs {
+						for _, weather := range weatherConfigs {
+							config := SimulationConfig{
+								NumDrones:    drones,
+								NumPeople:    people,
+								Protocol:     protocol,
+								DroneTypes:   droneTypes,
+								MapName:      mapName,
+								Weather:      weather,
+								Evacuation:   *evacuation,
+								StaticZones:  *staticZones,
+								Reservations: *reservations,
+								Fleet:        fleet,
+								Faults:       *faults,
+								FaultRates:   faultRates,
+								IdealRadio:   *idealRadio,
+								Sensor:       *sensor,
+								Confirmation: confirmation,
+								Kits:         *kits,
+								Relays:       *relays,
+								AEDs:         *aeds,
+								Separation:   separation,
+								Planner:      *planner,
+							}
 
-					dirName := fmt.Sprintf("%dd_%dp_p%d_%s", drones, people, protocol, mapName)
-					configDir := filepath.Join(resultsDir, dirName)
-					fmt.Printf("\n===== Starting configuration: %s =====\n", dirName)
+							dirName := fmt.Sprintf("%dd_%dp_%s_%s_%s", drones, people, strings.ReplaceAll(protocol, ",", "+"), mapName, weather)
+							if config.DroneTypes != standardType {
+								dirName += "_types-" + strings.ReplaceAll(config.DroneTypes, ",", "+")
+							}
+							if config.Evacuation != "" {
+								dirName += "_evac-" + config.Evacuation
+							}
+							if config.StaticZones {
+								dirName += "_static-zones"
+							}
+							if config.Reservations {
+								dirName += "_reservations"
+							}
+							if config.Fleet.MinAirborne > 0 {
+								dirName += fmt.Sprintf("_min-airborne-%d", config.Fleet.MinAirborne)
+							}
+							if config.Fleet.MinCoverage > 0 {
+								dirName += fmt.Sprintf("_min-coverage-%.0f", config.Fleet.MinCoverage*100)
+							}
+							if config.Fleet.HotSwap {
+								dirName += "_hot-swap"
+							}
+							if config.Faults != "" {
+								dirName += "_faults-" + config.Faults
+							}
+							if *faultRate > 0 {
+								dirName += fmt.Sprintf("_fault-rate-%g", *faultRate)
+							}
+							if config.IdealRadio {
+								dirName += "_ideal-radio"
+							}
+							if config.Sensor != "" {
+								dirName += "_sensor-" + config.Sensor
+							}
+							if config.Confirmation.Enabled() {
+								dirName += fmt.Sprintf("_confirm-%.0f-%d", config.Confirmation.Threshold*100, config.Confirmation.Ticks)
+								if config.Confirmation.AskPeer {
+									dirName += "-peer"
+								}
+							}
+							if config.Kits != simulation.FIRST_AID_KITS_PER_TENT || config.AEDs != simulation.AEDS_PER_TENT {
+								dirName += fmt.Sprintf("_kits-%d-%d", config.Kits, config.AEDs)
+							}
+							if config.Relays > 0 {
+								dirName += fmt.Sprintf("_relays-%d", config.Relays)
+							}
+							if config.Separation.Enabled() {
+								dirName += fmt.Sprintf("_sep-%.1f-%d", config.Separation.MinDistance, max(config.Separation.Layers, 1))
+							}
+							if config.Planner {
+								dirName += "_planner"
+							}
+							configDir := filepath.Join(resultsDir, dirName)
+							fmt.Printf("\n===== Starting configuration: %s =====\n", dirName)
 
-					// Create directory for this configuration
-					if err := os.MkdirAll(configDir, 0755); err != nil {
-						fmt.Printf("Error creating directory for configuration: %v\n", err)
-						continue
-					}
+							// Create directory for this configuration
+							if err := os.MkdirAll(configDir, 0755); err != nil {
+								fmt.Printf("Error creating directory for configuration: %v\n", err)
+								continue
+							}
 
-					runSimulationSeries(config, configDir)
+							runSimulationSeries(config, configDir)
+						}
+					}
 				}
 			}
 		}
@@ -110,10 +254,47 @@ func runSingleSimulation(config SimulationConfig, runNum int) AggregatedMetrics
 	sim := simulation.NewSimulation(0, 0, 0)
 
 	// Configure simulation
+	sim.UpdateWeather(config.Weather)
 	sim.UpdateMap(config.MapName)
+	if config.Evacuation != "" {
+		sim.UpdateEvacuation(config.Evacuation)
+	}
 	sim.UpdateDroneSize(config.NumDrones)
 	sim.UpdateCrowdSize(config.NumPeople)
 	sim.UpdateDroneProtocole(config.Protocol)
+	if config.DroneTypes != drones.DefaultDroneType.Name {
+		sim.UpdateDroneTypes(config.DroneTypes)
+	}
+	sim.UpdateZoneRebalancing(!config.StaticZones)
+	sim.UpdateChargingReservations(config.Reservations)
+	sim.UpdateFleetSchedule(config.Fleet)
+	if config.IdealRadio {
+		sim.UpdateRadioModel(simulation.IdealRadioModel)
+	}
+	if config.Sensor != "" {
+		sim.UpdateSensorModel(config.Sensor)
+	}
+	if config.Confirmation.Enabled() {
+		sim.UpdateConfirmationPolicy(config.Confirmation)
+	}
+	if config.Kits != simulation.FIRST_AID_KITS_PER_TENT || config.AEDs != simulation.AEDS_PER_TENT {
+		sim.UpdateKitStock(config.Kits, config.AEDs)
+	}
+	if config.Relays > 0 {
+		sim.UpdateRelayPlacement(config.Relays)
+	}
+	if config.Separation.Enabled() {
+		sim.UpdateSeparation(config.Separation)
+	}
+	if config.Planner {
+		sim.UpdatePatrolPlanner(true)
+	}
+	if config.Faults != "" {
+		sim.UpdateFaults(config.Faults)
+	}
+	if config.FaultRates != (models.FaultRates{}) {
+		sim.UpdateFaultRates(config.FaultRates)
+	}
 	sim.InitDronesProtocols()
 
 	tick := 0
@@ -135,7 +316,7 @@ func runSingleSimulation(config SimulationConfig, runNum int) AggregatedMetrics
 
 	// Collect final statistics
 	stats := sim.GetStatistics()
-	return AggregatedMetrics{
+	metrics := AggregatedMetrics{
 		TotalPeople:     float64(stats.TotalPeople),
 		InDistress:      float64(stats.InDistress),
 		CasesTreated:    float64(stats.CasesTreated),
@@ -145,7 +326,31 @@ func runSingleSimulation(config SimulationConfig, runNum int) AggregatedMetrics
 		Runtime:         time.Since(startTime),
 		TotalTicks:      tick,
 		RescueStats:     sim.SimulationRescueStats,
+		ExitThroughput:  make(map[string]float64),
+		CrowdRisk:       sim.CrowdRiskStats,
+		Search:          sim.SearchStats,
+		Coverage:        sim.CoverageStats,
+		Charging:        sim.ChargingStats,
+		Fleet:           sim.FleetStats,
+		Faults:          sim.FaultStats,
+		Radio:           sim.RadioStats,
+		Bus:             sim.BusStats,
+		Sensor:          sim.SensorStats,
+		Confirmation:    sim.ConfirmationStats,
+		Delivery:        sim.DeliveryStats,
+		Relays:          sim.RelayStats,
+		Separation:      sim.SeparationStats,
+		Patrol:          sim.PatrolStats,
+	}
+	if evac := sim.Evacuation; evac != nil && evac.Active {
+		metrics.Evacuated = true
+		metrics.EvacuationTime = float64(stats.EvacuationTime)
+		metrics.PeakDensity = float64(evac.PeakDensity)
+		for exit, count := range evac.Throughput {
+			metrics.ExitThroughput[exit] = float64(count)
+		}
 	}
+	return metrics
 }
 
 func isSimulationComplete(sim *simulation.Simulation) bool {
@@ -163,7 +368,7 @@ func isSimulationComplete(sim *simulation.Simulation) bool {
 
 	allDronesCharging := true
 	for _, drone := range sim.Drones {
-		if !drone.IsCharging {
+		if !drone.Landed() && !drone.Crashed {
 			allDronesCharging = false
 			break
 		}
@@ -183,7 +388,8 @@ Average Battery: %.2f%%
 Average Coverage: %.2f%%
 Runtime: %v
 Total Ticks: %d
-`,
+
+%s`,
 		runNum,
 		metrics.TotalPeople,
 		metrics.InDistress,
@@ -193,6 +399,7 @@ Total Ticks: %d
 		metrics.AverageCoverage,
 		metrics.Runtime,
 		metrics.TotalTicks,
+		formatSeverityBreakdown(metrics.RescueStats)+"\n"+formatNeedsBreakdown(metrics.RescueStats)+"\n"+formatAttendance(metrics.RescueStats)+formatCrowdRisk(metrics.CrowdRisk)+formatSearch(metrics.Search)+formatCoverage(metrics.Coverage)+formatCharging(metrics.Charging)+formatFleet(metrics.Fleet)+formatRadio(metrics.Radio)+formatBus(metrics.Bus)+formatSensor(metrics.Sensor)+formatConfirmation(metrics.Confirmation)+formatDelivery(metrics.Delivery)+formatRelays(metrics.Relays)+formatSeparation(metrics.Separation)+formatPatrol(metrics.Patrol)+formatFaults(metrics.Faults)+formatEvacuation(metrics),
 	)
 
 	filename := fmt.Sprintf("run_%d_metrics.txt", runNum)
@@ -294,6 +501,16 @@ func averageMetrics(metrics []AggregatedMetrics) AggregatedMetrics {
 	avg.RescueStats.PersonsInDistress = make(map[int]int)
 	avg.RescueStats.PersonsRescued = make(map[int]int)
 	avg.RescueStats.AvgRescueTime = make(map[int][]int)
+	avg.RescueStats.DistressBySeverity = make(map[models.Severity]int)
+	avg.RescueStats.RescuedBySeverity = make(map[models.Severity]int)
+	avg.RescueStats.DeadBySeverity = make(map[models.Severity]int)
+	avg.RescueStats.RescueTimeBySeverity = make(map[models.Severity][]int)
+	avg.RescueStats.POIVisits = make(map[models.POIType]int)
+	avg.RescueStats.PersonsPresent = make(map[int]int)
+	avg.ExitThroughput = make(map[string]float64)
+	avg.Faults.Injected = make(map[models.FaultType]int)
+	avg.Bus.Messages = make(map[bus.MessageType]int)
+	evacuations := 0.0
 
 	// Sum all metrics
 	for _, m := range metrics {
@@ -316,6 +533,137 @@ func averageMetrics(metrics []AggregatedMetrics) AggregatedMetrics {
 		for tick, times := range m.RescueStats.AvgRescueTime {
 			avg.RescueStats.AvgRescueTime[tick] = append(avg.RescueStats.AvgRescueTime[tick], times...)
 		}
+		for severity, value := range m.RescueStats.DistressBySeverity {
+			avg.RescueStats.DistressBySeverity[severity] += value
+		}
+		for severity, value := range m.RescueStats.RescuedBySeverity {
+			avg.RescueStats.RescuedBySeverity[severity] += value
+		}
+		for severity, value := range m.RescueStats.DeadBySeverity {
+			avg.RescueStats.DeadBySeverity[severity] += value
+		}
+		for severity, times := range m.RescueStats.RescueTimeBySeverity {
+			avg.RescueStats.RescueTimeBySeverity[severity] = append(avg.RescueStats.RescueTimeBySeverity[severity], times...)
+		}
+		avg.RescueStats.WrongDistressTypes += m.RescueStats.WrongDistressTypes
+		for tick, value := range m.RescueStats.PersonsPresent {
+			avg.RescueStats.PersonsPresent[tick] += value
+		}
+		for poiType, value := range m.RescueStats.POIVisits {
+			avg.RescueStats.POIVisits[poiType] += value
+		}
+		if m.Evacuated {
+			evacuations++
+			avg.EvacuationTime += m.EvacuationTime
+			avg.PeakDensity += m.PeakDensity
+			for exit, count := range m.ExitThroughput {
+				avg.ExitThroughput[exit] += count
+			}
+		}
+		avg.CrowdRisk.PeakDensity += m.CrowdRisk.PeakDensity
+		avg.CrowdRisk.PeakPressure += m.CrowdRisk.PeakPressure
+		avg.CrowdRisk.CriticalCellTicks += m.CrowdRisk.CriticalCellTicks
+		avg.CrowdRisk.Alerts += m.CrowdRisk.Alerts
+		avg.CrowdRisk.CriticalAlerts += m.CrowdRisk.CriticalAlerts
+		avg.Search.Missions += m.Search.Missions
+		avg.Search.Found += m.Search.Found
+		avg.Search.Cancelled += m.Search.Cancelled
+		avg.Search.FalseSightings += m.Search.FalseSightings
+		avg.Search.TimeToFind = append(avg.Search.TimeToFind, m.Search.TimeToFind...)
+		avg.Coverage.AreaGap += m.Coverage.AreaGap
+		avg.Coverage.PeakAreaGap += m.Coverage.PeakAreaGap
+		avg.Coverage.CrowdGap += m.Coverage.CrowdGap
+		avg.Coverage.Rebalances += m.Coverage.Rebalances
+		avg.Coverage.Handovers += m.Coverage.Handovers
+		avg.Charging.Charges += m.Charging.Charges
+		avg.Charging.PadTicks += m.Charging.PadTicks
+		avg.Charging.BusyPadTicks += m.Charging.BusyPadTicks
+		avg.Charging.WaitTicks += m.Charging.WaitTicks
+		avg.Charging.Waits = append(avg.Charging.Waits, m.Charging.Waits...)
+		avg.Charging.MaxQueue = max(avg.Charging.MaxQueue, m.Charging.MaxQueue)
+		avg.Charging.Swaps += m.Charging.Swaps
+		avg.Fleet.Airborne += m.Fleet.Airborne
+		avg.Fleet.TicksBelowMin += m.Fleet.TicksBelowMin
+		avg.Fleet.EarlyCharges += m.Fleet.EarlyCharges
+		if avg.Fleet.Ticks == 0 || m.Fleet.LowestAirborne < avg.Fleet.LowestAirborne {
+			avg.Fleet.LowestAirborne = m.Fleet.LowestAirborne
+		}
+		avg.Fleet.Ticks += m.Fleet.Ticks
+		for faultType, injected := range m.Faults.Injected {
+			avg.Faults.Injected[faultType] += injected
+		}
+		avg.Faults.DegradedDroneTicks += m.Faults.DegradedDroneTicks
+		avg.Faults.IsolatedDroneTicks += m.Faults.IsolatedDroneTicks
+		// Trous de couverture pondérés par leurs ticks
+		avg.Faults.GapUnderFaults += m.Faults.GapUnderFaults * float64(m.Faults.FaultTicks)
+		avg.Faults.NominalGap += m.Faults.NominalGap * float64(m.Faults.NominalTicks)
+		avg.Faults.FaultTicks += m.Faults.FaultTicks
+		avg.Faults.NominalTicks += m.Faults.NominalTicks
+		avg.Radio.Sent += m.Radio.Sent
+		avg.Radio.Delivered += m.Radio.Delivered
+		avg.Radio.Faded += m.Radio.Faded
+		avg.Radio.Obstructed += m.Radio.Obstructed
+		avg.Radio.OutOfRange += m.Radio.OutOfRange
+		avg.Radio.Hops += m.Radio.Hops
+		avg.Radio.QueuedTicks += m.Radio.QueuedTicks
+		avg.Radio.LatencyTicks += m.Radio.LatencyTicks
+		avg.Radio.Reports += m.Radio.Reports
+		avg.Radio.LostReports += m.Radio.LostReports
+		for messageType, sent := range m.Bus.Messages {
+			avg.Bus.Messages[messageType] += sent
+		}
+		avg.Bus.Handovers += m.Bus.Handovers
+		avg.Bus.HandoversAcked += m.Bus.HandoversAcked
+		avg.Bus.Reports += m.Bus.Reports
+		avg.Bus.ReportsAcked += m.Bus.ReportsAcked
+		avg.Bus.Expired += m.Bus.Expired
+		avg.Bus.AckTicks += m.Bus.AckTicks
+		avg.Sensor.Detections += m.Sensor.Detections
+		avg.Sensor.DistressReads += m.Sensor.DistressReads
+		avg.Sensor.MissedDistress += m.Sensor.MissedDistress
+		avg.Sensor.FalseReads += m.Sensor.FalseReads
+		avg.Sensor.FalseAlarms += m.Sensor.FalseAlarms
+		avg.Sensor.WastedRescuerTicks += m.Sensor.WastedRescuerTicks
+		avg.Sensor.NightTicks += m.Sensor.NightTicks
+		avg.Confirmation.Suspicions += m.Confirmation.Suspicions
+		avg.Confirmation.Confirmed += m.Confirmation.Confirmed
+		avg.Confirmation.Dismissed += m.Confirmation.Dismissed
+		avg.Confirmation.PeerChecks += m.Confirmation.PeerChecks
+		avg.Confirmation.ConfirmTicks += m.Confirmation.ConfirmTicks
+		avg.Confirmation.Dispatched += m.Confirmation.Dispatched
+		avg.Confirmation.FalseDispatches += m.Confirmation.FalseDispatches
+		avg.Confirmation.DispatchTicks += m.Confirmation.DispatchTicks
+		avg.Confirmation.Timed += m.Confirmation.Timed
+		avg.Delivery.Loaded += m.Delivery.Loaded
+		avg.Delivery.OutOfStock += m.Delivery.OutOfStock
+		avg.Delivery.Delivered += m.Delivery.Delivered
+		avg.Delivery.WrongKit += m.Delivery.WrongKit
+		avg.Delivery.Wasted += m.Delivery.Wasted
+		avg.Delivery.Late += m.Delivery.Late
+		avg.Delivery.DeliveryTicks += m.Delivery.DeliveryTicks
+		avg.Delivery.ExtraTicks += m.Delivery.ExtraTicks
+		avg.Delivery.Stabilised += m.Delivery.Stabilised
+		avg.Delivery.KitRescued += m.Delivery.KitRescued
+		avg.Delivery.KitDeaths += m.Delivery.KitDeaths
+		avg.Delivery.KitsLeft += m.Delivery.KitsLeft
+		avg.Relays.Plans += m.Relays.Plans
+		avg.Relays.Posts += m.Relays.Posts
+		avg.Relays.Unreachable += m.Relays.Unreachable
+		avg.Relays.RelayTicks += m.Relays.RelayTicks
+		avg.Relays.IsolatedTicks += m.Relays.IsolatedTicks
+		avg.Separation.NearMisses += m.Separation.NearMisses
+		avg.Separation.NearMissTicks += m.Separation.NearMissTicks
+		avg.Separation.Refused += m.Separation.Refused
+		avg.Separation.Manoeuvres += m.Separation.Manoeuvres
+		avg.Separation.Holds += m.Separation.Holds
+		avg.Separation.Climbs += m.Separation.Climbs
+		avg.Patrol.Incidents += m.Patrol.Incidents
+		avg.Patrol.DetectionTicks += m.Patrol.DetectionTicks
+		avg.Patrol.Waypoints += m.Patrol.Waypoints
+		avg.Patrol.Shared += m.Patrol.Shared
+		avg.Patrol.Merged += m.Patrol.Merged
+		avg.RescueStats.DehydratedTicks += m.RescueStats.DehydratedTicks
+		avg.RescueStats.IntoxicatedTicks += m.RescueStats.IntoxicatedTicks
 	}
 
 	// Calculate averages
@@ -335,6 +683,132 @@ func averageMetrics(metrics []AggregatedMetrics) AggregatedMetrics {
 	for tick := range avg.RescueStats.PersonsRescued {
 		avg.RescueStats.PersonsRescued[tick] = int(float64(avg.RescueStats.PersonsRescued[tick]) / count)
 	}
+	for severity := range avg.RescueStats.DistressBySeverity {
+		avg.RescueStats.DistressBySeverity[severity] = int(math.Round(float64(avg.RescueStats.DistressBySeverity[severity]) / count))
+	}
+	for severity := range avg.RescueStats.RescuedBySeverity {
+		avg.RescueStats.RescuedBySeverity[severity] = int(math.Round(float64(avg.RescueStats.RescuedBySeverity[severity]) / count))
+	}
+	for severity := range avg.RescueStats.DeadBySeverity {
+		avg.RescueStats.DeadBySeverity[severity] = int(math.Round(float64(avg.RescueStats.DeadBySeverity[severity]) / count))
+	}
+	avg.RescueStats.WrongDistressTypes = int(math.Round(float64(avg.RescueStats.WrongDistressTypes) / count))
+	if evacuations > 0 {
+		avg.Evacuated = true
+		avg.EvacuationTime /= evacuations
+		avg.PeakDensity /= evacuations
+		for exit := range avg.ExitThroughput {
+			avg.ExitThroughput[exit] /= evacuations
+		}
+	}
+	for tick := range avg.RescueStats.PersonsPresent {
+		avg.RescueStats.PersonsPresent[tick] = int(float64(avg.RescueStats.PersonsPresent[tick]) / count)
+	}
+	for poiType := range avg.RescueStats.POIVisits {
+		avg.RescueStats.POIVisits[poiType] = int(math.Round(float64(avg.RescueStats.POIVisits[poiType]) / count))
+	}
+	avg.RescueStats.DehydratedTicks = int(math.Round(float64(avg.RescueStats.DehydratedTicks) / count))
+	avg.RescueStats.IntoxicatedTicks = int(math.Round(float64(avg.RescueStats.IntoxicatedTicks) / count))
+	avg.CrowdRisk.PeakDensity /= count
+	avg.CrowdRisk.PeakPressure /= count
+	avg.CrowdRisk.CriticalCellTicks = int(math.Round(float64(avg.CrowdRisk.CriticalCellTicks) / count))
+	avg.CrowdRisk.Alerts = int(math.Round(float64(avg.CrowdRisk.Alerts) / count))
+	avg.CrowdRisk.CriticalAlerts = int(math.Round(float64(avg.CrowdRisk.CriticalAlerts) / count))
+	avg.Search.Missions = int(math.Round(float64(avg.Search.Missions) / count))
+	avg.Search.Found = int(math.Round(float64(avg.Search.Found) / count))
+	avg.Search.Cancelled = int(math.Round(float64(avg.Search.Cancelled) / count))
+	avg.Search.FalseSightings = int(math.Round(float64(avg.Search.FalseSightings) / count))
+	avg.Coverage.AreaGap /= count
+	avg.Coverage.PeakAreaGap /= count
+	avg.Coverage.CrowdGap /= count
+	avg.Coverage.Rebalances = int(math.Round(float64(avg.Coverage.Rebalances) / count))
+	avg.Coverage.Handovers = int(math.Round(float64(avg.Coverage.Handovers) / count))
+	avg.Charging.Charges = int(math.Round(float64(avg.Charging.Charges) / count))
+	avg.Charging.PadTicks = int(math.Round(float64(avg.Charging.PadTicks) / count))
+	avg.Charging.BusyPadTicks = int(math.Round(float64(avg.Charging.BusyPadTicks) / count))
+	avg.Charging.WaitTicks = int(math.Round(float64(avg.Charging.WaitTicks) / count))
+	avg.Charging.Swaps = int(math.Round(float64(avg.Charging.Swaps) / count))
+	avg.Fleet.Ticks = int(math.Round(float64(avg.Fleet.Ticks) / count))
+	avg.Fleet.Airborne /= count
+	avg.Fleet.TicksBelowMin = int(math.Round(float64(avg.Fleet.TicksBelowMin) / count))
+	avg.Fleet.EarlyCharges = int(math.Round(float64(avg.Fleet.EarlyCharges) / count))
+	if avg.Faults.FaultTicks > 0 {
+		avg.Faults.GapUnderFaults /= float64(avg.Faults.FaultTicks)
+	}
+	if avg.Faults.NominalTicks > 0 {
+		avg.Faults.NominalGap /= float64(avg.Faults.NominalTicks)
+	}
+	for faultType, injected := range avg.Faults.Injected {
+		avg.Faults.Injected[faultType] = int(math.Round(float64(injected) / count))
+	}
+	avg.Faults.FaultTicks = int(math.Round(float64(avg.Faults.FaultTicks) / count))
+	avg.Faults.NominalTicks = int(math.Round(float64(avg.Faults.NominalTicks) / count))
+	avg.Faults.DegradedDroneTicks = int(math.Round(float64(avg.Faults.DegradedDroneTicks) / count))
+	avg.Faults.IsolatedDroneTicks = int(math.Round(float64(avg.Faults.IsolatedDroneTicks) / count))
+	// Le délai moyen et le taux de livraison restent des rapports des totaux
+	avg.Radio.Sent = int(math.Round(float64(avg.Radio.Sent) / count))
+	avg.Radio.Delivered = int(math.Round(float64(avg.Radio.Delivered) / count))
+	avg.Radio.Faded = int(math.Round(float64(avg.Radio.Faded) / count))
+	avg.Radio.Obstructed = int(math.Round(float64(avg.Radio.Obstructed) / count))
+	avg.Radio.OutOfRange = int(math.Round(float64(avg.Radio.OutOfRange) / count))
+	avg.Radio.Hops = int(math.Round(float64(avg.Radio.Hops) / count))
+	avg.Radio.QueuedTicks = int(math.Round(float64(avg.Radio.QueuedTicks) / count))
+	avg.Radio.LatencyTicks = int(math.Round(float64(avg.Radio.LatencyTicks) / count))
+	avg.Radio.Reports = int(math.Round(float64(avg.Radio.Reports) / count))
+	avg.Radio.LostReports = int(math.Round(float64(avg.Radio.LostReports) / count))
+	for messageType, sent := range avg.Bus.Messages {
+		avg.Bus.Messages[messageType] = int(math.Round(float64(sent) / count))
+	}
+	avg.Bus.Handovers = int(math.Round(float64(avg.Bus.Handovers) / count))
+	avg.Bus.HandoversAcked = int(math.Round(float64(avg.Bus.HandoversAcked) / count))
+	avg.Bus.Reports = int(math.Round(float64(avg.Bus.Reports) / count))
+	avg.Bus.ReportsAcked = int(math.Round(float64(avg.Bus.ReportsAcked) / count))
+	avg.Bus.Expired = int(math.Round(float64(avg.Bus.Expired) / count))
+	avg.Bus.AckTicks = int(math.Round(float64(avg.Bus.AckTicks) / count))
+	avg.Sensor.Detections = int(math.Round(float64(avg.Sensor.Detections) / count))
+	avg.Sensor.DistressReads = int(math.Round(float64(avg.Sensor.DistressReads) / count))
+	avg.Sensor.MissedDistress = int(math.Round(float64(avg.Sensor.MissedDistress) / count))
+	avg.Sensor.FalseReads = int(math.Round(float64(avg.Sensor.FalseReads) / count))
+	avg.Sensor.FalseAlarms = int(math.Round(float64(avg.Sensor.FalseAlarms) / count))
+	avg.Sensor.WastedRescuerTicks = int(math.Round(float64(avg.Sensor.WastedRescuerTicks) / count))
+	avg.Sensor.NightTicks = int(math.Round(float64(avg.Sensor.NightTicks) / count))
+	avg.Confirmation.Suspicions = int(math.Round(float64(avg.Confirmation.Suspicions) / count))
+	avg.Confirmation.Confirmed = int(math.Round(float64(avg.Confirmation.Confirmed) / count))
+	avg.Confirmation.Dismissed = int(math.Round(float64(avg.Confirmation.Dismissed) / count))
+	avg.Confirmation.PeerChecks = int(math.Round(float64(avg.Confirmation.PeerChecks) / count))
+	avg.Confirmation.ConfirmTicks = int(math.Round(float64(avg.Confirmation.ConfirmTicks) / count))
+	avg.Confirmation.Dispatched = int(math.Round(float64(avg.Confirmation.Dispatched) / count))
+	avg.Confirmation.FalseDispatches = int(math.Round(float64(avg.Confirmation.FalseDispatches) / count))
+	avg.Confirmation.DispatchTicks = int(math.Round(float64(avg.Confirmation.DispatchTicks) / count))
+	avg.Confirmation.Timed = int(math.Round(float64(avg.Confirmation.Timed) / count))
+	avg.Delivery.Loaded = int(math.Round(float64(avg.Delivery.Loaded) / count))
+	avg.Delivery.OutOfStock = int(math.Round(float64(avg.Delivery.OutOfStock) / count))
+	avg.Delivery.Delivered = int(math.Round(float64(avg.Delivery.Delivered) / count))
+	avg.Delivery.WrongKit = int(math.Round(float64(avg.Delivery.WrongKit) / count))
+	avg.Delivery.Wasted = int(math.Round(float64(avg.Delivery.Wasted) / count))
+	avg.Delivery.Late = int(math.Round(float64(avg.Delivery.Late) / count))
+	avg.Delivery.DeliveryTicks = int(math.Round(float64(avg.Delivery.DeliveryTicks) / count))
+	avg.Delivery.ExtraTicks = int(math.Round(float64(avg.Delivery.ExtraTicks) / count))
+	avg.Delivery.Stabilised = int(math.Round(float64(avg.Delivery.Stabilised) / count))
+	avg.Delivery.KitRescued = int(math.Round(float64(avg.Delivery.KitRescued) / count))
+	avg.Delivery.KitDeaths = int(math.Round(float64(avg.Delivery.KitDeaths) / count))
+	avg.Delivery.KitsLeft = int(math.Round(float64(avg.Delivery.KitsLeft) / count))
+	avg.Relays.Plans = int(math.Round(float64(avg.Relays.Plans) / count))
+	avg.Relays.Posts = int(math.Round(float64(avg.Relays.Posts) / count))
+	avg.Relays.Unreachable = int(math.Round(float64(avg.Relays.Unreachable) / count))
+	avg.Relays.RelayTicks = int(math.Round(float64(avg.Relays.RelayTicks) / count))
+	avg.Relays.IsolatedTicks = int(math.Round(float64(avg.Relays.IsolatedTicks) / count))
+	avg.Separation.NearMisses = int(math.Round(float64(avg.Separation.NearMisses) / count))
+	avg.Separation.NearMissTicks = int(math.Round(float64(avg.Separation.NearMissTicks) / count))
+	avg.Separation.Refused = int(math.Round(float64(avg.Separation.Refused) / count))
+	avg.Separation.Manoeuvres = int(math.Round(float64(avg.Separation.Manoeuvres) / count))
+	avg.Separation.Holds = int(math.Round(float64(avg.Separation.Holds) / count))
+	avg.Separation.Climbs = int(math.Round(float64(avg.Separation.Climbs) / count))
+	avg.Patrol.Incidents = int(math.Round(float64(avg.Patrol.Incidents) / count))
+	avg.Patrol.DetectionTicks = int(math.Round(float64(avg.Patrol.DetectionTicks) / count))
+	avg.Patrol.Waypoints = int(math.Round(float64(avg.Patrol.Waypoints) / count))
+	avg.Patrol.Shared = int(math.Round(float64(avg.Patrol.Shared) / count))
+	avg.Patrol.Merged = int(math.Round(float64(avg.Patrol.Merged) / count))
 
 	return avg
 }
@@ -355,7 +829,8 @@ Performance Metrics:
 - Treatment Success Rate: %.2f%%
 - Mortality Rate: %.2f%%
 - Average Response Time: %v
-`,
+
+%s`,
 		metrics.TotalPeople,
 		metrics.InDistress,
 		metrics.CasesTreated,
@@ -367,9 +842,252 @@ Performance Metrics:
 		(metrics.CasesTreated/metrics.InDistress)*100,
 		(metrics.CasesDead/metrics.InDistress)*100,
 		metrics.Runtime/time.Duration(metrics.CasesTreated),
+		formatSeverityBreakdown(metrics.RescueStats)+"\n"+formatNeedsBreakdown(metrics.RescueStats)+"\n"+formatAttendance(metrics.RescueStats)+formatCrowdRisk(metrics.CrowdRisk)+formatSearch(metrics.Search)+formatCoverage(metrics.Coverage)+formatCharging(metrics.Charging)+formatFleet(metrics.Fleet)+formatRadio(metrics.Radio)+formatBus(metrics.Bus)+formatSensor(metrics.Sensor)+formatConfirmation(metrics.Confirmation)+formatDelivery(metrics.Delivery)+formatRelays(metrics.Relays)+formatSeparation(metrics.Separation)+formatPatrol(metrics.Patrol)+formatFaults(metrics.Faults)+formatEvacuation(metrics),
 	)
 
 	if err := os.WriteFile(filepath.Join(dirPath, "metrics.txt"), []byte(content), 0644); err != nil {
 		fmt.Printf("Error writing metrics file: %v\n", err)
 	}
 }
+
+func formatSeverityBreakdown(stats simulation.SimulationRescueStats) string {
+	content := "Outcomes by Severity:\n"
+	for _, severity := range models.Severities {
+		avgTime := 0.0
+		if times := stats.RescueTimeBySeverity[severity]; len(times) > 0 {
+			sum := 0
+			for _, t := range times {
+				sum += t
+			}
+			avgTime = float64(sum) / float64(len(times))
+		}
+		content += fmt.Sprintf("- %s: Incidents %d, Rescued %d, Dead %d, Avg Rescue Time %.2f ticks\n",
+			severity,
+			stats.DistressBySeverity[severity],
+			stats.RescuedBySeverity[severity],
+			stats.DeadBySeverity[severity],
+			avgTime,
+		)
+	}
+	content += fmt.Sprintf("- Wrong Distress Type on Arrival: %d\n", stats.WrongDistressTypes)
+	return content
+}
+
+// formatNeedsBreakdown summarizes how well the layout covers the needs of the crowd.
+func formatNeedsBreakdown(stats simulation.SimulationRescueStats) string {
+	content := "Needs:\n"
+	content += fmt.Sprintf("- Drink stand visits: %d\n", stats.POIVisits[models.DrinkStand])
+	content += fmt.Sprintf("- Food stand visits: %d\n", stats.POIVisits[models.FoodStand])
+	content += fmt.Sprintf("- Toilet visits: %d\n", stats.POIVisits[models.Toilet])
+	content += fmt.Sprintf("- Dehydrated person-ticks: %d\n", stats.DehydratedTicks)
+	content += fmt.Sprintf("- Intoxicated person-ticks: %d\n", stats.IntoxicatedTicks)
+	return content
+}
+
+// formatAttendance reports the peak of the crowd size and when it happened.
+func formatAttendance(stats simulation.SimulationRescueStats) string {
+	peak, peakTick := 0, 0
+	for tick, present := range stats.PersonsPresent {
+		if present > peak || (present == peak && tick < peakTick) {
+			peak, peakTick = present, tick
+		}
+	}
+	return fmt.Sprintf("Attendance:\n- Peak: %d people at tick %d\n", peak, peakTick)
+}
+
+// formatCrowdRisk reports the crowd crush risk, densities are in persons/m².
+func formatCrowdRisk(stats simulation.CrowdRiskStats) string {
+	content := "Crowd Risk:\n"
+	content += fmt.Sprintf("- Peak Density: %.2f p/m²\n", stats.PeakDensity)
+	content += fmt.Sprintf("- Peak Pressure: %.4f\n", stats.PeakPressure)
+	content += fmt.Sprintf("- Critical cell-ticks: %d\n", stats.CriticalCellTicks)
+	content += fmt.Sprintf("- Alerts: %d (%d critical)\n", stats.Alerts, stats.CriticalAlerts)
+	return content
+}
+
+// formatSearch reports the missing-person searches, time-to-find is averaged over the people found.
+func formatSearch(stats simulation.SearchStats) string {
+	content := "Missing People:\n"
+	content += fmt.Sprintf("- Search Missions: %d (%d found, %d cancelled)\n", stats.Missions, stats.Found, stats.Cancelled)
+	if len(stats.TimeToFind) > 0 {
+		total := 0
+		for _, ticks := range stats.TimeToFind {
+			total += ticks
+		}
+		content += fmt.Sprintf("- Average Time to Find: %.2f ticks\n", float64(total)/float64(len(stats.TimeToFind)))
+	}
+	content += fmt.Sprintf("- False Sightings: %d\n", stats.FalseSightings)
+	return content
+}
+
+// formatCoverage reports the share of the map and of the crowd seen by no drone.
+func formatCoverage(stats simulation.CoverageStats) string {
+	content := "Coverage Gap:\n"
+	content += fmt.Sprintf("- Average Area Gap: %.2f%% (peak %.2f%%)\n", stats.AreaGap*100, stats.PeakAreaGap*100)
+	content += fmt.Sprintf("- Average Crowd Gap: %.2f%%\n", stats.CrowdGap*100)
+	content += fmt.Sprintf("- Zone Rebalances: %d (%d handovers)\n", stats.Rebalances, stats.Handovers)
+	return content
+}
+
+// formatCharging reports the use of the charging pads and the time drones waited for one.
+func formatCharging(stats simulation.ChargingStats) string {
+	content := "Charging:\n"
+	content += fmt.Sprintf("- Charges: %d (%d battery swaps)\n", stats.Charges, stats.Swaps)
+	content += fmt.Sprintf("- Pad Utilisation: %.2f%%\n", stats.Utilisation()*100)
+	content += fmt.Sprintf("- Average Wait for a Pad: %.2f ticks\n", stats.AverageWait())
+	content += fmt.Sprintf("- Drone-ticks Waiting: %d (longest queue %d)\n", stats.WaitTicks, stats.MaxQueue)
+	return content
+}
+
+// formatFleet reports how many drones stayed airborne, against the minimum of the fleet schedule.
+func formatFleet(stats simulation.FleetStats) string {
+	content := "Fleet:\n"
+	content += fmt.Sprintf("- Average Airborne Drones: %.2f (lowest %d)\n", stats.Airborne, stats.LowestAirborne)
+	content += fmt.Sprintf("- Ticks Below Minimum: %d\n", stats.TicksBelowMin)
+	content += fmt.Sprintf("- Early Charges: %d\n", stats.EarlyCharges)
+	return content
+}
+
+// formatRadio reports the messages between the drones and the transmissions to the rescue points.
+func formatRadio(stats simulation.RadioStats) string {
+	content := "Radio:\n"
+	content += fmt.Sprintf("- Messages: %d sent, %.1f%% delivered\n", stats.Sent, stats.DeliveryRate()*100)
+	content += fmt.Sprintf("- Lost: %d faded, %d obstructed, %d out of range\n", stats.Faded, stats.Obstructed, stats.OutOfRange)
+	content += fmt.Sprintf("- Average Latency: %.2f ticks (%d message-ticks waiting for bandwidth)\n", stats.AverageLatency(), stats.QueuedTicks)
+	content += fmt.Sprintf("- Average Route: %.2f hops\n", stats.AverageHops())
+	content += fmt.Sprintf("- Reports to Rescue Points: %d (%d lost and retried)\n", stats.Reports, stats.LostReports)
+	return content
+}
+
+// formatBus reports the messages of the drones by type, and how their handovers and reports were acknowledged.
+func formatBus(stats simulation.BusStats) string {
+	sent := make([]string, 0, len(bus.MessageTypes))
+	for _, messageType := range bus.MessageTypes {
+		if stats.Messages[messageType] > 0 {
+			sent = append(sent, fmt.Sprintf("%d %s", stats.Messages[messageType], messageType))
+		}
+	}
+	if len(sent) == 0 {
+		sent = append(sent, "none")
+	}
+	content := "Message Bus:\n"
+	content += fmt.Sprintf("- Sent: %s\n", strings.Join(sent, ", "))
+	content += fmt.Sprintf("- Handovers: %d (%d acknowledged)\n", stats.Handovers, stats.HandoversAcked)
+	content += fmt.Sprintf("- Incident Reports: %d (%d acknowledged)\n", stats.Reports, stats.ReportsAcked)
+	content += fmt.Sprintf("- Expired without Ack: %d (sent again)\n", stats.Expired)
+	content += fmt.Sprintf("- Average Time to Ack: %.2f ticks\n", stats.AverageAck())
+	return content
+}
+
+// formatSensor reports what the cameras read, and the rescuer time lost to their false alarms.
+func formatSensor(stats simulation.SensorStats) string {
+	content := "Sensors:\n"
+	content += fmt.Sprintf("- Detections: %d (%d ticks at night)\n", stats.Detections, stats.NightTicks)
+	content += fmt.Sprintf("- Distress Reads: %d right, %d false, %d missed\n", stats.DistressReads, stats.FalseReads, stats.MissedDistress)
+	content += fmt.Sprintf("- Precision: %.1f%%, Recall: %.1f%%\n", stats.Precision()*100, stats.Recall()*100)
+	content += fmt.Sprintf("- False Alarms Reached by Rescuers: %d (%d rescuer-ticks wasted)\n", stats.FalseAlarms, stats.WastedRescuerTicks)
+	return content
+}
+
+// formatConfirmation reports the time the confirmations cost before dispatch, and the missions they spared.
+func formatConfirmation(stats simulation.ConfirmationStats) string {
+	content := "Confirmation:\n"
+	content += fmt.Sprintf("- Uncertain Reads: %d (%d confirmed, %d dismissed, %d asked to a peer)\n",
+		stats.Suspicions, stats.Confirmed, stats.Dismissed, stats.PeerChecks)
+	content += fmt.Sprintf("- Average Time to Confirm: %.2f ticks\n", stats.AverageConfirm())
+	content += fmt.Sprintf("- Average Time to Dispatch: %.2f ticks (%d people in distress)\n", stats.AverageDispatch(), stats.Timed)
+	content += fmt.Sprintf("- Wasted Missions: %d of %d (%.1f%%)\n", stats.FalseDispatches, stats.Dispatched, stats.WastedShare()*100)
+	return content
+}
+
+// formatDelivery reports the kits the drones dropped and whether the people lived until the rescuer.
+func formatDelivery(stats simulation.DeliveryStats) string {
+	content := "Kit Delivery:\n"
+	content += fmt.Sprintf("- Kits Loaded: %d (%d refused out of stock, %d left in the tents)\n", stats.Loaded, stats.OutOfStock, stats.KitsLeft)
+	content += fmt.Sprintf("- Drops: %d delivered, %d wrong kit, %d to a false alarm, %d too late\n", stats.Delivered, stats.WrongKit, stats.Wasted, stats.Late)
+	content += fmt.Sprintf("- Average Time to Drop: %.2f ticks\n", stats.AverageDelivery())
+	content += fmt.Sprintf("- Survival Time Given: %d ticks (%d people stabilised)\n", stats.ExtraTicks, stats.Stabilised)
+	content += fmt.Sprintf("- People with a Kit: %d reached by a rescuer, %d dead before\n", stats.KitRescued, stats.KitDeaths)
+	return content
+}
+
+// formatRelays reports the drones held on relay posts, to weigh against the coverage gap and the
+// dispatch delay of the runs without relays.
+func formatRelays(stats simulation.RelayStats) string {
+	content := "Relay Placement:\n"
+	content += fmt.Sprintf("- Placements: %d (%d posts, %d zones left cut off)\n", stats.Plans, stats.Posts, stats.Unreachable)
+	content += fmt.Sprintf("- Relay Drone-ticks: %d\n", stats.RelayTicks)
+	content += fmt.Sprintf("- Isolated Patrol Drone-ticks: %d\n", stats.IsolatedTicks)
+	return content
+}
+
+// formatSeparation reports the near-misses of the fleet, counted with or without the separation rule,
+// and what keeping the separation cost.
+func formatSeparation(stats simulation.SeparationStats) string {
+	content := "Separation:\n"
+	content += fmt.Sprintf("- Near-misses: %d (%d pair-ticks under %.0f cell)\n", stats.NearMisses, stats.NearMissTicks, simulation.NEAR_MISS_DISTANCE)
+	content += fmt.Sprintf("- Avoidance Manoeuvres: %d (%d holds, %d layer changes)\n", stats.Manoeuvres, stats.Holds, stats.Climbs)
+	content += fmt.Sprintf("- Moves Refused: %d\n", stats.Refused)
+	return content
+}
+
+// formatPatrol reports how soon the drones read the people in distress, to compare the planner
+// with the sweep, and the belief maps shared by the planners.
+func formatPatrol(stats simulation.PatrolStats) string {
+	content := "Patrol:\n"
+	content += fmt.Sprintf("- Incidents Detected: %d\n", stats.Incidents)
+	content += fmt.Sprintf("- Average Time to Detect: %.1f ticks\n", stats.AverageDetectionTicks())
+	content += fmt.Sprintf("- Planned Waypoints: %d\n", stats.Waypoints)
+	content += fmt.Sprintf("- Belief Shared: %d cell observations (%d new to the receiver)\n", stats.Shared, stats.Merged)
+	return content
+}
+
+// formatFaults reports the failures injected and how the fleet held up, the runs without
+// failures give the nominal isolation to compare with.
+func formatFaults(stats simulation.FaultStats) string {
+	types := make([]string, 0, len(stats.Injected))
+	for faultType := range stats.Injected {
+		types = append(types, string(faultType))
+	}
+	sort.Strings(types)
+	injected := make([]string, 0, len(types))
+	for _, faultType := range types {
+		injected = append(injected, fmt.Sprintf("%d %s", stats.Injected[models.FaultType(faultType)], faultType))
+	}
+
+	if len(injected) == 0 {
+		injected = append(injected, "none")
+	}
+	content := "Faults:\n"
+	content += fmt.Sprintf("- Injected: %s\n", strings.Join(injected, ", "))
+	content += fmt.Sprintf("- Ticks with a Failure: %d (crashes excluded)\n", stats.FaultTicks)
+	content += fmt.Sprintf("- Degraded Drone-ticks: %d\n", stats.DegradedDroneTicks)
+	content += fmt.Sprintf("- Isolated Drone-ticks: %d\n", stats.IsolatedDroneTicks)
+	content += fmt.Sprintf("- Area Gap: %.1f%% under failures, %.1f%% nominal\n", stats.GapUnderFaults*100, stats.NominalGap*100)
+	return content
+}
+
+// formatEvacuation reports the evacuation metrics, empty when no evacuation was played.
+func formatEvacuation(metrics AggregatedMetrics) string {
+	if !metrics.Evacuated {
+		return ""
+	}
+	content := "\nEvacuation:\n"
+	content += fmt.Sprintf("- Total Evacuation Time: %.2f ticks\n", metrics.EvacuationTime)
+	content += fmt.Sprintf("- Peak Density: %.2f people per cell\n", metrics.PeakDensity)
+
+	exits := make([]string, 0, len(metrics.ExitThroughput))
+	for exit := range metrics.ExitThroughput {
+		exits = append(exits, exit)
+	}
+	sort.Strings(exits)
+	for _, exit := range exits {
+		throughput := metrics.ExitThroughput[exit]
+		perTick := 0.0
+		if metrics.EvacuationTime > 0 {
+			perTick = throughput / metrics.EvacuationTime
+		}
+		content += fmt.Sprintf("- Exit %s: %.2f people (%.2f per tick)\n", exit, throughput, perTick)
+	}
+	return content
+}
@@ -14,6 +14,7 @@ import (
 	"math"
 	"os"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
@@ -105,6 +106,7 @@ type Game struct {
 	StartButtonDebug      ui.Button
 	PauseButton           ui.Button
 	SimButton             ui.Button
+	EvacuateButton        ui.Button
 	DroneField            ui.TextField
 	PeopleField           ui.TextField
 	DropdownMap           ui.Dropdown
@@ -197,6 +199,7 @@ func (g *Game) Update() error {
 	case Simulation:
 		g.SimButton.Update(float64(mx), float64(my), mousePressed)
 		g.PauseButton.Update(float64(mx), float64(my), mousePressed)
+		g.EvacuateButton.Update(float64(mx), float64(my), mousePressed)
 
 		worldX, worldY := g.transform.ScreenToWorld(float64(mx), float64(my))
 		g.updatePOIHover(worldX, worldY)
@@ -260,6 +263,7 @@ func (g *Game) Update() error {
 	case SimulationDebug:
 		g.SimButton.Update(float64(mx), float64(my), mousePressed)
 		g.PauseButton.Update(float64(mx), float64(my), mousePressed)
+		g.EvacuateButton.Update(float64(mx), float64(my), mousePressed)
 
 		worldX, worldY := g.transform.ScreenToWorld(float64(mx), float64(my))
 		g.updatePOIHover(worldX, worldY)
@@ -414,13 +418,46 @@ func (g *Game) drawDynamicLayer() {
 
 	seenPeople := make(map[int]bool)
 
+	// Draw evacuation hazard and emergency exits
+	if evac := g.Sim.ActiveEvacuation(); evac != nil {
+		if evac.Hazard != nil {
+			hazardX, hazardY := g.transform.WorldToScreen(evac.Hazard.Center.X, evac.Hazard.Center.Y)
+			drawTranslucentCircle(g.DynamicLayer, hazardX, hazardY, g.transform.scale*evac.Hazard.Radius, color.RGBA{255, 80, 0, 90})
+		}
+		for _, exit := range evac.Exits {
+			exitX, exitY := g.transform.WorldToScreen(exit.Position.X, exit.Position.Y)
+			drawRectangle(g.DynamicLayer, exitX-6, exitY-6, 12, 12, color.RGBA{0, 200, 0, 220})
+		}
+	}
+
+	// Draw open crowd alerts
+	for _, alert := range g.Sim.GetCrowdAlerts() {
+		alertX, alertY := g.transform.WorldToScreen(alert.Position.X+0.5, alert.Position.Y+0.5)
+		alertColor := color.RGBA{255, 165, 0, 100}
+		if alert.Level == models.CrowdRiskCritical {
+			alertColor = color.RGBA{220, 0, 0, 120}
+		}
+		drawTranslucentCircle(g.DynamicLayer, alertX, alertY, g.transform.scale*1.5, alertColor)
+	}
+
+	// Draw last-seen positions of the missing people
+	for _, mission := range g.Sim.GetSearchMissions() {
+		lastSeenX, lastSeenY := g.transform.WorldToScreen(mission.LastSeen.X, mission.LastSeen.Y)
+		drawRectangle(g.DynamicLayer, lastSeenX-4, lastSeenY-4, 8, 8, color.RGBA{255, 0, 255, 200})
+	}
+
 	// Draw rescuers
 	for _, drone := range g.Sim.Drones {
 		// Draw drone and its vision range
-		droneScreenX, droneScreenY := g.transform.WorldToScreen(drone.Position.X, drone.Position.Y)
-		seeRangeScreen := g.transform.scale * float64(g.Sim.DroneSeeRange)
+		truePos := drone.TruePosition()
+		droneScreenX, droneScreenY := g.transform.WorldToScreen(truePos.X, truePos.Y)
+		seeRangeScreen := g.transform.scale * float64(drone.DroneSeeRange)
 
-		if !drone.IsCharging {
+		if drone.Crashed {
+			drawCircle(g.DynamicLayer, droneScreenX, droneScreenY, 6, color.RGBA{200, 0, 0, 255})
+			continue
+		}
+		if drone.Airborne() {
 			drawTranslucentCircle(g.DynamicLayer, droneScreenX, droneScreenY, seeRangeScreen, color.RGBA{0, 0, 0, 32})
 		}
 
@@ -448,7 +485,7 @@ func (g *Game) drawDynamicLayer() {
 
 			screenX, screenY := g.transform.WorldToScreen(rescuer.Position.X, rescuer.Position.Y)
 
-			if rescuer.State == rescue.MovingToPerson && rescuer.Person != nil {
+			if (rescuer.State == rescue.MovingToPerson || rescuer.State == rescue.Treating) && rescuer.Person != nil {
 				if rescuer.Person.Position.X > rescuer.Position.X {
 					if g.RescuerLookRightImage != nil {
 						bounds := g.RescuerLookRightImage.Bounds()
@@ -595,6 +632,10 @@ func (g *Game) drawDynamicLayer() {
 
 		screenX, screenY := g.transform.WorldToScreen(person.Position.X, person.Position.Y)
 
+		if person.Lost {
+			drawTranslucentCircle(g.DynamicLayer, screenX, screenY, 12, color.RGBA{255, 0, 255, 120})
+		}
+
 		if !person.IsInDistress() {
 			if g.AttendeeImage != nil {
 				bounds := g.AttendeeImage.Bounds()
@@ -667,6 +708,7 @@ func (g *Game) drawSimulation(screen *ebiten.Image) {
 
 	g.PauseButton.Draw(screen)
 	g.SimButton.Draw(screen)
+	g.EvacuateButton.Draw(screen)
 
 	mx, my := ebiten.CursorPosition()
 	worldX, worldY := g.transform.ScreenToWorld(float64(mx), float64(my))
@@ -725,15 +767,28 @@ func (g *Game) drawSimulation(screen *ebiten.Image) {
 			"Person Info:\n"+
 				"ID: %d\n"+
 				"In Distress: %t\n"+
+				"Distress: %s (%s)\n"+
 				"Has Reached POI: %t\n"+
 				"Position: (%.1f, %.1f)\n"+
-				"CurrentDistressDuration: %d",
+				"CurrentDistressDuration: %d\n"+
+				"Hydration: %.2f  Hunger: %.2f\n"+
+				"Bladder: %.2f  Alcohol: %.2f g/L\n"+
+				"Appearance: %s%s  Lost: %t",
 			hoveredPerson.ID,
 			hoveredPerson.InDistress,
+			hoveredPerson.DistressType,
+			hoveredPerson.Severity,
 			hoveredPerson.HasReachedPOI(),
 			hoveredPerson.Position.X,
 			hoveredPerson.Position.Y,
 			hoveredPerson.CurrentDistressDuration,
+			hoveredPerson.Needs.Hydration,
+			hoveredPerson.Needs.Hunger,
+			hoveredPerson.Needs.Bladder,
+			hoveredPerson.Needs.Alcohol,
+			map[bool]string{true: "child in ", false: ""}[hoveredPerson.Appearance.Child],
+			hoveredPerson.Appearance.Clothing,
+			hoveredPerson.Lost,
 		)
 		ebitenutil.DebugPrintAt(screen, personInfo, mx+10, my+10)
 	}
@@ -742,12 +797,17 @@ func (g *Game) drawSimulation(screen *ebiten.Image) {
 		droneInfo := fmt.Sprintf(
 			"Drone Info:\n"+
 				"ID: %d\n"+
+				"Protocol: %s\n"+
 				"Position: (%.1f, %.1f)\n"+
 				"Watch Bounds: (%.1f, %.1f) - (%.1f, %.1f)\n"+
-				"Battery: %.1f\n"+
+				"Battery: %.1f%% (%.0f / %.0f Wh)\n"+
 				"Number of seen people: %d\n"+
-				"Is charging: %t\n",
+				"Is charging: %t\n"+
+				"Waiting for a pad: %t\n"+
+				"Crashed: %t\n"+
+				"Failures: %v\n",
 			hoveredDrone.ID,
+			hoveredDrone.ProtocolName(),
 			hoveredDrone.Position.X,
 			hoveredDrone.Position.Y,
 			hoveredDrone.MyWatch.CornerBottomLeft.X,
@@ -755,8 +815,13 @@ func (g *Game) drawSimulation(screen *ebiten.Image) {
 			hoveredDrone.MyWatch.CornerTopRight.X,
 			hoveredDrone.MyWatch.CornerTopRight.Y,
 			hoveredDrone.Battery,
+			hoveredDrone.Battery/100*hoveredDrone.Energy.CapacityWh,
+			hoveredDrone.Energy.CapacityWh,
 			len(hoveredDrone.SeenPeople),
 			hoveredDrone.IsCharging,
+			hoveredDrone.WaitingToCharge,
+			hoveredDrone.Crashed,
+			g.Sim.DroneFaults(hoveredDrone.ID),
 		)
 		ebitenutil.DebugPrintAt(screen, droneInfo, mx+10, my+10)
 	}
@@ -879,39 +944,62 @@ func (g *Game) drawMetricsWindow(screen *ebiten.Image) {
 	const (
 		normalSize     = 200.0 
 		expandedSize   = 300.0
-		bottomPadding  = 120.0 
 		edgePadding    = 20.0  
 		cooldownFrames = 5 
 	)
 
-	graphBaseY := screenHeight - bottomPadding
+	stats := g.Sim.GetStatistics()
+	// Main metrics, one line per subsystem
+	lines := []string{
+		fmt.Sprintf("Time:       Tick %d -- %s    Remaining: %s", g.Sim.GetCurrentTick(), g.Sim.GetRealFestivalTime(), g.Sim.GetRemaningFestivalTime()),
+		fmt.Sprintf("People:     Total: %d    Present: %d    In Distress: %d    Treated: %d    Dead: %d",
+			stats.TotalPeople, stats.PresentPeople, stats.InDistress, stats.CasesTreated, stats.CasesDead),
+		fmt.Sprintf("Needs:      Avg Hydration: %.2f    Dehydrated: %d    Intoxicated: %d", stats.AvgHydration, stats.Dehydrated, stats.Intoxicated),
+		fmt.Sprintf("Weather:    %.1f°C    %.0f%% humidity    rain %.1f mm/h    wind %.1f m/s",
+			stats.Weather.Temperature, stats.Weather.Humidity*100, stats.Weather.Rain, stats.Weather.Wind),
+		fmt.Sprintf("Evacuation: %s", map[bool]string{true: fmt.Sprintf("%d ticks", stats.EvacuationTime), false: "none"}[stats.Evacuating]),
+		fmt.Sprintf("Crowd:      Max Density: %.2f p/m²    Max Pressure: %.4f    Alerts: %d", stats.MaxDensity, stats.MaxPressure, len(stats.CrowdAlerts)),
+		fmt.Sprintf("Search:     Lost: %d    Missions: %d", stats.LostPeople, len(stats.SearchMissions)),
+		fmt.Sprintf("Drones:     Battery: %.1f%%    Coverage: %.1f%%    Gap: %.1f%%    Airborne: %d",
+			stats.AverageBattery, stats.AverageCoverage, stats.CoverageGap*100, stats.Airborne),
+		fmt.Sprintf("Charging:   Pads: %.0f%%    Wait: %.1f ticks", g.Sim.ChargingStats.Utilisation()*100, g.Sim.ChargingStats.AverageWait()),
+		fmt.Sprintf("Faults:     Active: %d", stats.ActiveFaults),
+		fmt.Sprintf("Radio:      Delivered: %.0f%%", g.Sim.RadioStats.DeliveryRate()*100),
+		fmt.Sprintf("Sensors:    False Alarms: %d    Daylight: %.0f%%", g.Sim.SensorStats.FalseAlarms, g.Sim.Daylight()*100),
+	}
 
-	metricsWidth := screenWidth * 0.95
-	metricsHeight := 80.0
+	// Les lignes sont réparties en colonnes selon la largeur, la fenêtre grandit avec leur nombre
+	const (
+		charWidth   = 6.0 // Size of a glyph of the debug font
+		lineHeight  = 16.0
+		textPadding = 10.0
+	)
+	longest := 0
+	for _, line := range lines {
+		longest = max(longest, utf8.RuneCountInString(line))
+	}
+	// La colonne des boutons reste libre à droite
+	metricsWidth := screenWidth*0.85 - 3*edgePadding
+	columnWidth := float64(longest)*charWidth + 2*textPadding
+	columns := max(1, int((metricsWidth-textPadding)/columnWidth))
+	rows := (len(lines) + columns - 1) / columns
+	metricsHeight := float64(rows)*lineHeight + 2*textPadding
 	metrics := ebiten.NewImage(int(metricsWidth), int(metricsHeight))
 	metrics.Fill(color.RGBA{30, 30, 30, 200})
+	for i, line := range lines {
+		x := textPadding + float64(i/rows)*columnWidth
+		y := textPadding + float64(i%rows)*lineHeight
+		ebitenutil.DebugPrintAt(metrics, line, int(x), int(y))
+	}
 
-	stats := g.Sim.GetStatistics()
-	// Main metrics text
-	text := fmt.Sprintf(
-		"People Metrics:  Total: %d    In Distress: %d    Treated: %d    Dead: %d        "+
-			"Drone Metrics:  Battery: %.1f%%    Coverage: %.1f%%"+"\nCurrent Tick: %d -- Current Time: %s    Remaning Time: %s",
-		stats.TotalPeople,
-		stats.InDistress,
-		stats.CasesTreated,
-		stats.CasesDead,
-		stats.AverageBattery,
-		stats.AverageCoverage,
-		g.Sim.GetCurrentTick(),
-		g.Sim.GetRealFestivalTime(),
-		g.Sim.GetRemaningFestivalTime(),
-	)
-	ebitenutil.DebugPrintAt(metrics, text, 20, 20)
-
+	metricsY := screenHeight - metricsHeight - edgePadding
 	opts := &ebiten.DrawImageOptions{}
-	opts.GeoM.Translate(screenWidth-metricsWidth-20, screenHeight*0.89)
+	opts.GeoM.Translate(edgePadding, metricsY)
 	screen.DrawImage(metrics, opts)
 
+	// Les graphes sont posés au-dessus de la fenêtre
+	graphBaseY := metricsY - 2*edgePadding
+
 	currentDensitySize := normalSize
 	if g.isDensityMapExpanded {
 		currentDensitySize = expandedSize
@@ -1004,6 +1092,11 @@ func (g *Game) drawMetricsWindowButtons(screen *ebiten.Image) {
 	g.SimButton.Height = buttonHeight
 	g.SimButton.X = screenWidth - metricsWidth - padding - 5
 	g.SimButton.Y = g.PauseButton.Y + buttonHeight + buttonSpacing
+
+	g.EvacuateButton.Width = buttonWidth
+	g.EvacuateButton.Height = buttonHeight
+	g.EvacuateButton.X = screenWidth - metricsWidth - padding - 5
+	g.EvacuateButton.Y = g.SimButton.Y + buttonHeight + buttonSpacing
 }
 
 func loadImage(path string) *ebiten.Image {
@@ -0,0 +1,124 @@
+package bus
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"sync"
+)
+
+type MessageType int
+
+const (
+	// IncidentReport asks a rescue point to rescue the people of Incidents.
+	IncidentReport MessageType = iota
+	// HandoverRequest asks a drone to take over the reports of Persons.
+	HandoverRequest
+	// HandoverAck answers a HandoverRequest or an IncidentReport with the same Seq,
+	// Persons are the people the receiver took over.
+	HandoverAck
+	// Heartbeat tells the neighbours the drone is alive, with its Battery.
+	Heartbeat
+	// PositionBeacon tells the neighbours the Position of the drone.
+	PositionBeacon
+	// CallForBids asks the receiver to bid on the incident of Persons.
+	CallForBids
+	// Bid answers a call for bids on the incident of Persons, Cost is the bid.
+	Bid
+	// ConfirmRequest asks the receiver to read the person of Persons, at Position, before it is reported.
+	ConfirmRequest
+	// ConfirmReply answers a ConfirmRequest, Confidence is what the receiver read.
+	ConfirmReply
+	// BeliefShare gives the neighbours the Cells observed since the last share, by the sender or its own neighbours.
+	BeliefShare
+)
+
+var messageTypeNames = map[MessageType]string{
+	IncidentReport:  "incident report",
+	HandoverRequest: "handover request",
+	HandoverAck:     "handover ack",
+	Heartbeat:       "heartbeat",
+	PositionBeacon:  "position beacon",
+	CallForBids:     "call for bids",
+	Bid:             "bid",
+	ConfirmRequest:  "confirm request",
+	ConfirmReply:    "confirm reply",
+	BeliefShare:     "belief share",
+}
+
+func (t MessageType) String() string {
+	if name, exists := messageTypeNames[t]; exists {
+		return name
+	}
+	return "unknown"
+}
+
+// MessageTypes lists the message types in order, for the reports.
+var MessageTypes = []MessageType{IncidentReport, HandoverRequest, HandoverAck, Heartbeat, PositionBeacon, CallForBids, Bid, ConfirmRequest, ConfirmReply, BeliefShare}
+
+type NodeKind int
+
+const (
+	DroneNode NodeKind = iota
+	RescuePointNode
+)
+
+// Address identifies a drone or a rescue point on the bus, their IDs may collide.
+type Address struct {
+	Kind NodeKind
+	ID   int
+}
+
+// Incident is a person in distress reported to a rescue point, with the severity seen by the drone.
+type Incident struct {
+	Person       *persons.Person
+	Severity     models.Severity
+	DistressType models.DistressType // As estimated by the drone
+}
+
+type Message struct {
+	Type      MessageType
+	From      Address
+	Sender    Endpoint // To answer the message
+	Tick      int      // Sending tick
+	Seq       int      // Handover or report number of the sender, echoed by the ack
+	Persons   []*persons.Person
+	Incidents []Incident
+	Position  models.Position
+	Battery   float64
+	Cost      float64
+	// Chance that the person of a ConfirmReply is in distress
+	Confidence float64
+	Cells      []models.CellObservation
+}
+
+// Endpoint is a drone or a rescue point connected to the bus.
+type Endpoint interface {
+	BusAddress() Address
+	// Deliver puts the message in the inbox of the endpoint, it is read at its next turn.
+	Deliver(msg Message)
+}
+
+// Inbox keeps the messages of an endpoint until it reads them, any goroutine can deliver.
+type Inbox struct {
+	messages []Message
+	mu       sync.Mutex
+}
+
+func NewInbox() *Inbox {
+	return &Inbox{messages: make([]Message, 0)}
+}
+
+func (in *Inbox) Push(msg Message) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.messages = append(in.messages, msg)
+}
+
+// Drain returns the messages in their delivery order and empties the inbox.
+func (in *Inbox) Drain() []Message {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	messages := in.messages
+	in.messages = make([]Message, 0)
+	return messages
+}
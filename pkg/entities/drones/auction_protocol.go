@@ -0,0 +1,222 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"fmt"
+	"math"
+)
+
+const ProtocolAuction = "auction" // Contract-net: the network peers bid on each incident
+
+// Poids des critères d'enchère
+const (
+	AUCTION_DISTANCE_WEIGHT = 1.0  // Per cell to fly before being in range of a rescue point
+	AUCTION_BATTERY_WEIGHT  = 20.0 // Times the fraction of battery already used
+	AUCTION_LOAD_WEIGHT     = 5.0  // Per incident the bidder already has to report
+	AUCTION_COVERAGE_WEIGHT = 0.5  // Per cell the bidder would fly outside of its watched zone
+)
+
+const AUCTION_BID_TICKS = 3 // Ticks to collect the bids: the call, then the bid, each take at least a tick on the radio
+
+func init() {
+	RegisterProtocol(ProtocolAuction, func() Protocol {
+		return &auctionProtocol{
+			reportingProtocol: reportingProtocol{name: ProtocolAuction, patrol: true, relay: networkRelay},
+			auctions:          make(map[int]*auction),
+		}
+	})
+}
+
+// auctionProtocol reports like the multi-hop protocols, but a drone that cannot reach a rescue point
+// calls for bids on each incident over its network and awards it to the cheapest bidder, itself included.
+// The bids come back over the radio, so an auction stays open AUCTION_BID_TICKS ticks.
+type auctionProtocol struct {
+	reportingProtocol
+	auctions map[int]*auction // Open auctions, by person ID
+}
+
+type auction struct {
+	Person   *persons.Person
+	Deadline int
+	Bids     map[*Drone]float64
+}
+
+func (p *auctionProtocol) OnMessage(d *Drone, msg bus.Message) {
+	switch msg.Type {
+	case bus.CallForBids:
+		peer, ok := msg.Sender.(*Drone)
+		if len(msg.Persons) == 0 || !ok {
+			return
+		}
+		if cost, ok := d.AuctionCost(msg.Persons[0]); ok {
+			d.Transmit(peer, bus.Message{Type: bus.Bid, Persons: msg.Persons[:1], Cost: cost})
+		}
+	case bus.Bid:
+		peer, ok := msg.Sender.(*Drone)
+		if len(msg.Persons) == 0 || !ok {
+			return
+		}
+		if running, exists := p.auctions[msg.Persons[0].ID]; exists {
+			running.Bids[peer] = msg.Cost
+		}
+	}
+}
+
+// Avoid gives way by ID: the drone holds for a tick when a moving intruder has a lower ID, the wait
+// costs less flight than a detour. The drone with the lowest ID, or facing hovering intruders, turns right.
+func (p *auctionProtocol) Avoid(d *Drone, target models.Position, intruders []*Drone) models.Position {
+	for _, intruder := range intruders {
+		if intruder.ID < d.ID && d.PeerMoving(intruder) {
+			return d.GiveWay()
+		}
+	}
+	return d.TurnRight(target, intruders)
+}
+
+func (p *auctionProtocol) Think(d *Drone) models.Position {
+	if d.IsCharging {
+		// Drone AFK quand il charge car il est docké.
+		return d.Position
+	}
+	d.DroneNetwork = d.GetDroneNetwork(d).Drones
+
+	d.NoteDistress()
+	if target, loitering := d.Loiter(); loitering {
+		// Confirmer l'incident avant de le signaler
+		return target
+	}
+
+	toSave := d.PersonsToSave()
+	if len(toSave) == 0 {
+		return p.move(d)
+	}
+
+	p.dropAuctions(toSave)
+
+	rp := d.GetRescuePoint(d.Position)
+	if rp == nil {
+		return p.move(d)
+	}
+	if rp.Position.CalculateDistance(d.Position) <= float64(d.DroneCommRange) {
+		d.ReportPersonsToSave(rp, toSave)
+		return p.move(d)
+	}
+
+	kept := 0
+	for _, person := range toSave {
+		running, exists := p.auctions[person.ID]
+		if !exists {
+			p.callForBids(d, person)
+			kept++
+			continue
+		}
+		if d.currentTick() < running.Deadline {
+			kept++
+			continue
+		}
+		delete(p.auctions, person.ID)
+		winner := p.award(d, running)
+		if winner == nil || winner == d {
+			kept++
+			continue
+		}
+		if d.debug {
+			fmt.Printf("[DRONE %d] - Person %d awarded to drone %d\n", d.ID, person.ID, winner.ID)
+		}
+		d.HandOver(winner, []*persons.Person{person})
+	}
+	if kept == 0 {
+		return p.move(d)
+	}
+	return d.nextStepToPos(rp.Position)
+}
+
+// callForBids opens an auction on an incident and sends the call to the network peers.
+func (p *auctionProtocol) callForBids(d *Drone, person *persons.Person) {
+	p.auctions[person.ID] = &auction{
+		Person:   person,
+		Deadline: d.currentTick() + AUCTION_BID_TICKS,
+		Bids:     make(map[*Drone]float64),
+	}
+	for _, peer := range d.DroneNetwork {
+		if peer.ID == d.ID {
+			continue
+		}
+		d.Transmit(peer, bus.Message{Type: bus.CallForBids, Persons: []*persons.Person{person}})
+	}
+}
+
+// dropAuctions forgets the auctions of the people the drone no longer has to report.
+func (p *auctionProtocol) dropAuctions(toSave []*persons.Person) {
+	kept := make(map[int]bool)
+	for _, person := range toSave {
+		kept[person.ID] = true
+	}
+	for id := range p.auctions {
+		if !kept[id] {
+			delete(p.auctions, id)
+		}
+	}
+}
+
+// award returns the cheapest bidder of a closed auction, nil if nobody can take the incident.
+func (p *auctionProtocol) award(d *Drone, running *auction) *Drone {
+	var winner *Drone
+	bestCost := math.Inf(1)
+	if cost, ok := d.AuctionCost(running.Person); ok {
+		winner, bestCost = d, cost
+	}
+	for peer, cost := range running.Bids {
+		if cost < bestCost {
+			winner, bestCost = peer, cost
+		}
+	}
+	return winner
+}
+
+// AuctionCost is the bid of the drone to report an incident: the flight to a rescue point,
+// the battery it has already used, the incidents it already carries and the patrol coverage it
+// gives up. ok is false when the drone cannot take the incident.
+func (d *Drone) AuctionCost(person *persons.Person) (float64, bool) {
+	if d.IsCharging || d.DroneState != NoDefinedState || d.Evacuation != nil || d.Search != nil || d.HoldsPosition() {
+		// Un relais garde sa position pour le réseau
+		return 0, false
+	}
+	rp := d.GetRescuePoint(d.Position)
+	if rp == nil {
+		return 0, false
+	}
+
+	flight := math.Max(0, d.Position.CalculateDistance(rp.Position)-float64(d.DroneCommRange))
+	step := d.maxStep()
+	if d.Battery <= flight/step*d.flightDrain(step)+d.chargingReserve() {
+		return 0, false
+	}
+
+	load := 0
+	d.Memory.Persons.PersonsToSave.Range(func(key, _ interface{}) bool {
+		if key.(int) != person.ID {
+			load++
+		}
+		return true
+	})
+
+	cost := AUCTION_DISTANCE_WEIGHT * flight
+	cost += AUCTION_BATTERY_WEIGHT * (100 - d.Battery) / 100
+	cost += AUCTION_LOAD_WEIGHT * float64(load)
+	cost += AUCTION_COVERAGE_WEIGHT * d.distanceOutsideWatch(rp.Position)
+	return cost, true
+}
+
+// distanceOutsideWatch returns how far pos is from the zone watched by the drone.
+func (d *Drone) distanceOutsideWatch(pos models.Position) float64 {
+	minX := math.Min(d.MyWatch.CornerBottomLeft.X, d.MyWatch.CornerTopRight.X)
+	maxX := math.Max(d.MyWatch.CornerBottomLeft.X, d.MyWatch.CornerTopRight.X)
+	minY := math.Min(d.MyWatch.CornerBottomLeft.Y, d.MyWatch.CornerTopRight.Y)
+	maxY := math.Max(d.MyWatch.CornerBottomLeft.Y, d.MyWatch.CornerTopRight.Y)
+	dx := math.Max(0, math.Max(minX-pos.X, pos.X-maxX))
+	dy := math.Max(0, math.Max(minY-pos.Y, pos.Y-maxY))
+	return math.Hypot(dx, dy)
+}
@@ -0,0 +1,101 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/entities/rescue"
+	"UTC_IA04/pkg/models"
+	"math"
+	"testing"
+)
+
+// newBidder returns a drone watching a 20x20 zone from (0, 0), with a charging station under it
+// and the rescue point rp, nil for none.
+func newBidder(id int, position models.Position, battery float64, rp *rescue.RescuePoint) *Drone {
+	stations := map[models.POIType][]models.Position{models.ChargingStation: {position}}
+	watch := models.MyWatch{CornerBottomLeft: models.Position{X: 0, Y: 0}, CornerTopRight: models.Position{X: 20, Y: 20}}
+	d := NewSurveillanceDrone(id, position, watch, battery, 4, 6,
+		nil, nil, func(models.Position) *rescue.RescuePoint { return rp }, nil,
+		nil, stations, nil, nil, nil, nil, nil, 30, 30, false)
+	return &d
+}
+
+func TestAuctionCost(t *testing.T) {
+	person := &persons.Person{ID: 1}
+	cases := []struct {
+		name    string
+		battery float64
+		rp      *models.Position // Rescue point, nil for none
+		setup   func(d *Drone)
+		wantOK  bool
+		want    float64
+	}{
+		{"in range, full battery", 100, &models.Position{X: 10, Y: 10}, nil, true, 0},
+		{"half battery", 50, &models.Position{X: 10, Y: 10}, nil, true, AUCTION_BATTERY_WEIGHT / 2},
+		{"flight to the rescue point", 100, &models.Position{X: 10, Y: 20}, nil, true, 4 * AUCTION_DISTANCE_WEIGHT},
+		{"rescue point outside the zone", 100, &models.Position{X: 10, Y: 30}, nil, true,
+			14*AUCTION_DISTANCE_WEIGHT + 10*AUCTION_COVERAGE_WEIGHT},
+		{"incidents already carried", 100, &models.Position{X: 10, Y: 10}, func(d *Drone) {
+			d.Memory.Persons.PersonsToSave.Store(person.ID, person)
+			d.Memory.Persons.PersonsToSave.Store(2, &persons.Person{ID: 2})
+			d.Memory.Persons.PersonsToSave.Store(3, &persons.Person{ID: 3})
+		}, true, 2 * AUCTION_LOAD_WEIGHT},
+		{"no rescue point", 100, nil, nil, false, 0},
+		{"charging", 100, &models.Position{X: 10, Y: 10}, func(d *Drone) { d.IsCharging = true }, false, 0},
+		{"going to charge", 100, &models.Position{X: 10, Y: 10}, func(d *Drone) { d.DroneState = GoingToCharge }, false, 0},
+		{"battery at the reserve", DefaultEnergyModel.ReservePercent, &models.Position{X: 10, Y: 10}, nil, false, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var rp *rescue.RescuePoint
+			if c.rp != nil {
+				rp = &rescue.RescuePoint{Position: *c.rp}
+			}
+			d := newBidder(0, models.Position{X: 10, Y: 10}, c.battery, rp)
+			if c.setup != nil {
+				c.setup(d)
+			}
+			cost, ok := d.AuctionCost(person)
+			if ok != c.wantOK || math.Abs(cost-c.want) > 1e-9 {
+				t.Fatalf("AuctionCost() = %.2f, %v, want %.2f, %v", cost, ok, c.want, c.wantOK)
+			}
+		})
+	}
+}
+
+func TestAuctionAward(t *testing.T) {
+	person := &persons.Person{ID: 1}
+	rp := &rescue.RescuePoint{Position: models.Position{X: 10, Y: 10}}
+	peerA := newBidder(1, models.Position{X: 5, Y: 5}, 100, rp)
+	peerB := newBidder(2, models.Position{X: 5, Y: 5}, 100, rp)
+	cases := []struct {
+		name       string
+		auctioneer float64 // Battery of the auctioneer, at the reserve it cannot bid
+		bids       map[*Drone]float64
+		want       *Drone
+	}{
+		{"auctioneer alone", 100, nil, nil},
+		{"auctioneer cheapest", 50, map[*Drone]float64{peerA: 15, peerB: 20}, nil},
+		{"cheapest peer", 50, map[*Drone]float64{peerA: 15, peerB: 5}, peerB},
+		{"auctioneer cannot bid", DefaultEnergyModel.ReservePercent, map[*Drone]float64{peerA: 50}, peerA},
+		{"nobody can take it", DefaultEnergyModel.ReservePercent, nil, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := newBidder(0, models.Position{X: 10, Y: 10}, c.auctioneer, rp)
+			if c.want == nil && c.auctioneer > DefaultEnergyModel.ReservePercent {
+				c.want = d
+			}
+			running := &auction{Person: person, Bids: c.bids}
+			if got := (&auctionProtocol{}).award(d, running); got != c.want {
+				t.Fatalf("award() = %v, want %v", droneID(got), droneID(c.want))
+			}
+		})
+	}
+}
+
+func droneID(d *Drone) int {
+	if d == nil {
+		return -1
+	}
+	return d.ID
+}
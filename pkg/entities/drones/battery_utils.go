@@ -3,17 +3,91 @@ package drones
 import (
 	"UTC_IA04/pkg/models"
 	"fmt"
+	"math"
 	"math/rand"
 )
 
+// CHARGE_TARGET is the expected battery % when a drone leaves its pad, used to estimate charging times.
+const CHARGE_TARGET = 90.0
+
 func (d *Drone) BatteryManagement() (models.Position, bool) {
-	closestStation, minDistance := d.closestPOI(models.ChargingStation)
-	if d.Battery <= minDistance+5 || d.DroneState == GoingToCharge || d.DroneState == FinalGoingToDock {
-		step := d.nextStepToPos(closestStation)
+	if d.DroneState != GoingToCharge && d.DroneState != FinalGoingToDock {
+		if d.Battery > d.chargingReserve() && !d.windTooStrong() {
+			return models.Position{}, false
+		}
+		d.DroneState = GoingToCharge
+	}
+	if d.ChargingTarget == nil {
+		d.chooseChargingStation()
+	}
+	return d.nextStepToPos(*d.ChargingTarget), true
+}
+
+// SendToCharge makes a patrolling drone leave for a charging station before reaching its reserve.
+func (d *Drone) SendToCharge() {
+	if d.DroneState == NoDefinedState && !d.Landed() {
 		d.DroneState = GoingToCharge
-		return step, true
 	}
-	return models.Position{}, false
+}
+
+// Landed tells if the drone is on the ground at a charging station, on a pad or queued.
+func (d *Drone) Landed() bool {
+	return d.IsCharging || d.WaitingToCharge
+}
+
+// chooseChargingStation picks the station reached the soonest with a free pad, travel time plus
+// expected wait, among the stations the battery can reach. The pad is reserved when the
+// stations accept reservations.
+func (d *Drone) chooseChargingStation() {
+	closest, _ := d.closestChargingStation()
+	best, bestTime := closest, math.Inf(1)
+	for _, station := range d.MapPoi[models.ChargingStation] {
+		if d.tripBattery(station) >= d.Battery {
+			continue
+		}
+		travel := d.flightTicks(d.Position, station)
+		wait := 0
+		if d.ChargingWait != nil {
+			wait = d.ChargingWait(station, d.ID, d.currentTick()+travel)
+		}
+		if total := float64(travel + wait); total < bestTime {
+			best, bestTime = station, total
+		}
+	}
+	d.ChargingTarget = &best
+	if len(d.MapPoi[models.ChargingStation]) > 0 {
+		d.requestCharging(models.ChargingReserve, best)
+	}
+}
+
+// flightTicks returns the ticks the drone takes from a position to another, in a straight line
+// at its max speed.
+func (d *Drone) flightTicks(from, to models.Position) int {
+	return int(math.Ceil(from.CalculateDistance(to)/d.maxStep() - ARRIVAL_EPSILON))
+}
+
+// chargeTicks estimates the ticks on a pad to reach CHARGE_TARGET from a battery %.
+func (d *Drone) chargeTicks(battery float64) int {
+	ticks := 0
+	for ; battery < CHARGE_TARGET && ticks < 1000; ticks++ {
+		battery = d.Energy.ChargeStep(battery, d.tickSeconds())
+	}
+	return ticks
+}
+
+func (d *Drone) requestCharging(action models.ChargingAction, station models.Position) models.ChargingResponse {
+	responseChan := make(chan models.ChargingResponse)
+	d.ChargingChan <- models.ChargingRequest{
+		DroneID:      d.ID,
+		Position:     station,
+		Action:       action,
+		ArrivalTick:  d.currentTick() + d.flightTicks(d.Position, station),
+		ChargeTicks:  d.chargeTicks(d.Battery),
+		Battery:      d.Battery,
+		DroneType:    d.Type.Name,
+		ResponseChan: responseChan,
+	}
+	return <-responseChan
 }
 
 func (d *Drone) tryCharging() bool {
@@ -22,30 +96,53 @@ func (d *Drone) tryCharging() bool {
 	}
 
 	if d.IsCharging {
-		d.Battery += 5
-		if d.DroneState == FinalGoingToDock {
+		done := false
+		if d.SwapTicksLeft > 0 {
+			d.SwapTicksLeft--
+			done = d.SwapTicksLeft == 0
+		} else {
+			d.charge()
+			done = d.Battery >= 80+rand.Float64()*20
+		}
+		if d.DroneState == FinalGoingToDock || d.windTooStrong() {
+			// Reste au sol tant que le vent dépasse la limite du drone
 			return true
 		}
-		if d.Battery >= 80+rand.Float64()*20 {
+		if done {
 			d.IsCharging = false
 			d.DroneState = NoDefinedState
+			if d.ChargingTarget != nil {
+				d.requestCharging(models.ChargingRelease, *d.ChargingTarget)
+				d.ChargingTarget = nil
+			}
 			return false
 		}
 		return true
 	}
 
-	responseChan := make(chan models.ChargingResponse)
-	d.ChargingChan <- models.ChargingRequest{
-		DroneID:      d.ID,
-		Position:     d.Position,
-		ResponseChan: responseChan,
+	if d.ChargingTarget == nil || d.Position != *d.ChargingTarget {
+		return false
 	}
 
-	response := <-responseChan
+	response := d.requestCharging(models.ChargingAsk, d.Position)
+	if response.Authorized && response.Swapped {
+		fmt.Printf("[DRONE %d] Swapping battery at (%.0f, %.0f): %.0f%% -> %.0f%%\n", d.ID, d.Position.X, d.Position.Y, d.Battery, response.Battery)
+		d.IsCharging = true
+		d.WaitingToCharge = false
+		d.Battery = response.Battery
+		d.SwapTicksLeft = response.SwapTicks
+		return true
+	}
 	if response.Authorized {
 		fmt.Printf("[DRONE %d] Starting to charge at (%.0f, %.0f)\n", d.ID, d.Position.X, d.Position.Y)
 		d.IsCharging = true
-		d.Battery += 5
+		d.WaitingToCharge = false
+		d.charge()
+		return true
+	}
+	if response.QueuePosition > 0 {
+		// Posé à côté de la station en attendant un pad
+		d.WaitingToCharge = true
 		return true
 	}
 	return false
@@ -0,0 +1,223 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/models"
+	"math"
+)
+
+const (
+	BELIEF_PERIOD        = BEACON_PERIOD // Ticks between two shares of the belief map with the neighbours
+	BELIEF_INCIDENT_RATE = 0.001         // Incidents per person and tick assumed by the planner
+	BELIEF_CROWD_TICKS   = 30.0          // Ticks for the crowd seen in a cell to move on, back to the prior
+	BELIEF_UNSEEN_TICKS  = 60            // A cell never observed counts as seen that many ticks before the start
+	BELIEF_POI_RANGE     = 3.0           // Cells around a POI where it raises the likelihood of an incident
+	PLANNER_REPLAN_TICKS = 10            // Ticks before a waypoint not reached yet is chosen again
+)
+
+// POI_RISK is the extra likelihood of an incident next to each kind of POI, on top of the crowd:
+// the crowd presses in front of the stages and drinks at the bars.
+var POI_RISK = map[models.POIType]float64{
+	models.MainStage:      1.0,
+	models.SecondaryStage: 0.75,
+	models.DrinkStand:     0.5,
+	models.FoodStand:      0.25,
+	models.Toilet:         0.25,
+	models.RestArea:       0.25,
+}
+
+// BeliefMap is what a drone believes of the map: when each cell was last observed, by itself or by a
+// drone of its network, and how many people it held. A crowded cell near a stage left unobserved for
+// long likely hides an incident. The cells are indexed by y*Width+x.
+type BeliefMap struct {
+	Width       int
+	Height      int
+	LastSeen    []int            // Tick of the last observation of each cell
+	People      []float64        // People counted at the last observation
+	Prior       []float64        // People expected in each cell from the crowd density, shared by the drones
+	Risk        []float64        // Factor of the POIs around each cell, 1 away from them
+	Waypoint    *models.Position // nil until the planner chooses one
+	PlannedTick int
+	zone        models.MyWatch // Zone of the drone when the waypoint was chosen
+	dirty       map[int]bool   // Cells observed since the last share, by the drone or its neighbours
+	footprint   []models.Position
+	seeRange    int // Range of the footprint
+}
+
+// NewBeliefMap returns the belief of a drone before any observation: the crowd is where the density
+// map prior tells, it is read only by the drone and kept up to date by the simulation, and the POIs
+// raise the likelihood of an incident around them.
+func NewBeliefMap(width, height int, pois map[models.POIType][]models.Position, prior []float64) *BeliefMap {
+	b := &BeliefMap{
+		Width:    width,
+		Height:   height,
+		LastSeen: make([]int, width*height),
+		People:   make([]float64, width*height),
+		Prior:    prior,
+		Risk:     make([]float64, width*height),
+		dirty:    make(map[int]bool),
+	}
+	for i := range b.LastSeen {
+		b.LastSeen[i] = -BELIEF_UNSEEN_TICKS
+		b.People[i] = prior[i]
+		b.Risk[i] = 1
+		cell := b.cell(i)
+		for poiType, positions := range pois {
+			for _, poi := range positions {
+				b.Risk[i] += POI_RISK[poiType] * math.Max(0, 1-cell.CalculateDistance(poi)/BELIEF_POI_RANGE)
+			}
+		}
+	}
+	return b
+}
+
+func (b *BeliefMap) index(cell models.Position) (int, bool) {
+	x, y := int(cell.X), int(cell.Y)
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return 0, false
+	}
+	return y*b.Width + x, true
+}
+
+func (b *BeliefMap) cell(i int) models.Position {
+	return models.Position{X: float64(i % b.Width), Y: float64(i / b.Width)}
+}
+
+// Observe records what was seen of a cell, it tells false when the belief was already as recent.
+func (b *BeliefMap) Observe(obs models.CellObservation) bool {
+	i, ok := b.index(obs.Cell)
+	if !ok || obs.Tick <= b.LastSeen[i] {
+		return false
+	}
+	b.LastSeen[i] = obs.Tick
+	b.People[i] = float64(obs.People)
+	b.dirty[i] = true
+	return true
+}
+
+// Crowd returns the people expected in the cell at the given tick: the crowd seen at the last
+// observation moves on and the cell goes back to the prior of the density map.
+func (b *BeliefMap) Crowd(i, tick int) float64 {
+	fading := math.Exp(-float64(tick-b.LastSeen[i]) / BELIEF_CROWD_TICKS)
+	return b.Prior[i] + (b.People[i]-b.Prior[i])*fading
+}
+
+// Likelihood returns the chance that an incident started in the cell since its last observation,
+// at the given tick.
+func (b *BeliefMap) Likelihood(i, tick int) float64 {
+	rate := BELIEF_INCIDENT_RATE * b.Crowd(i, tick) * b.Risk[i]
+	return 1 - math.Exp(-rate*float64(tick-b.LastSeen[i]))
+}
+
+// BeliefCounters counts the planning and the sharing of the belief map of a drone, the simulation sums them.
+type BeliefCounters struct {
+	Waypoints int // Waypoints chosen by the planner
+	Shared    int // Cell observations sent to the neighbours
+	Merged    int // Cell observations received newer than the belief
+}
+
+// updateBelief records the cells the camera covers this tick, and shares the news with the
+// neighbours when due.
+func (d *Drone) updateBelief() {
+	if d.Belief == nil {
+		return
+	}
+	tick := d.currentTick()
+	counts := make(map[models.Position]int)
+	for _, person := range d.SeenPeople {
+		counts[person.Position.Cell()]++
+	}
+	center := d.Position.Cell()
+	for _, offset := range d.footprint() {
+		cell := models.Position{X: center.X + offset.X, Y: center.Y + offset.Y}
+		d.Belief.Observe(models.CellObservation{Cell: cell, Tick: tick, People: counts[cell]})
+	}
+
+	if (tick+d.ID)%BELIEF_PERIOD != 0 || len(d.Belief.dirty) == 0 || len(d.DroneInComRange) == 0 {
+		return
+	}
+	cells := make([]models.CellObservation, 0, len(d.Belief.dirty))
+	for i := range d.Belief.dirty {
+		cells = append(cells, models.CellObservation{Cell: d.Belief.cell(i), Tick: d.Belief.LastSeen[i], People: int(d.Belief.People[i])})
+	}
+	d.Belief.dirty = make(map[int]bool)
+	for _, peer := range d.DroneInComRange {
+		d.Transmit(peer, bus.Message{Type: bus.BeliefShare, Cells: cells})
+		d.Beliefs.Shared += len(cells)
+	}
+}
+
+// mergeBelief takes the observations of a neighbour newer than the belief, they are passed on at the
+// next share so that the whole network converges to the same map.
+func (d *Drone) mergeBelief(msg bus.Message) {
+	if d.Belief == nil {
+		return
+	}
+	for _, obs := range msg.Cells {
+		if d.Belief.Observe(obs) {
+			d.Beliefs.Merged++
+		}
+	}
+}
+
+// footprint returns the offsets of the cells the camera covers around the drone.
+func (d *Drone) footprint() []models.Position {
+	if d.Belief.footprint == nil || d.Belief.seeRange != d.DroneSeeRange {
+		vector := models.Vector{}
+		_, d.Belief.footprint = vector.GenerateCircleValues(d.DroneSeeRange)
+		d.Belief.seeRange = d.DroneSeeRange
+	}
+	return d.Belief.footprint
+}
+
+// planPatrol flies to the waypoint of the zone with the most expected detections per tick of flight,
+// it is chosen again once reached, when the zone changes or every PLANNER_REPLAN_TICKS. A drone
+// without belief map sweeps its zone.
+func (d *Drone) planPatrol() models.Position {
+	if d.Belief == nil {
+		return d.patrolMovementLogic()
+	}
+	b := d.Belief
+	tick := d.currentTick()
+	if b.Waypoint == nil || *b.Waypoint == d.Position || b.zone != d.MyWatch ||
+		tick-b.PlannedTick >= PLANNER_REPLAN_TICKS {
+		waypoint, found := d.bestWaypoint(tick)
+		if !found {
+			return d.patrolMovementLogic()
+		}
+		b.Waypoint = &waypoint
+		b.PlannedTick = tick
+		b.zone = d.MyWatch
+		d.Beliefs.Waypoints++
+	}
+	return d.nextStepToPos(*b.Waypoint)
+}
+
+// bestWaypoint scores each cell of the zone by the incidents the drone expects to detect there on
+// arrival, over the ticks to fly there.
+func (d *Drone) bestWaypoint(tick int) (models.Position, bool) {
+	minX := max(int(math.Round(d.MyWatch.CornerBottomLeft.X)), 0)
+	minY := max(int(math.Round(d.MyWatch.CornerBottomLeft.Y)), 0)
+	maxX := min(int(math.Round(d.MyWatch.CornerTopRight.X)), d.MapWidth)
+	maxY := min(int(math.Round(d.MyWatch.CornerTopRight.Y)), d.MapHeight)
+
+	var best models.Position
+	bestScore, found := -1.0, false
+	footprint := d.footprint()
+	for x := minX; x < maxX; x++ {
+		for y := minY; y < maxY; y++ {
+			candidate := models.Position{X: float64(x), Y: float64(y)}
+			eta := d.flightTicks(d.Position, candidate)
+			expected := 0.0
+			for _, offset := range footprint {
+				if i, ok := d.Belief.index(models.Position{X: candidate.X + offset.X, Y: candidate.Y + offset.Y}); ok {
+					expected += d.Belief.Likelihood(i, tick+eta)
+				}
+			}
+			if score := expected / float64(eta+1); score > bestScore {
+				best, bestScore, found = candidate, score, true
+			}
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,193 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/entities/rescue"
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+const (
+	ProtocolBasic             = "basic"               // Random search, reports itself
+	ProtocolLocalRelay        = "local-relay"         // Patrol, hands over to a neighbour in range of a rescue point
+	ProtocolMultiHop          = "multi-hop"           // Patrol, hands over through the multi-hop network
+	ProtocolMultiHopOptimized = "multi-hop-optimized" // Multi-hop, and the drone closest to the rescue point flies there
+)
+
+var BuiltinProtocols = []string{ProtocolBasic, ProtocolLocalRelay, ProtocolMultiHop, ProtocolMultiHopOptimized, ProtocolAuction, ProtocolKitDelivery}
+
+type relayMode int
+
+const (
+	noRelay relayMode = iota
+	neighbourRelay
+	networkRelay
+	closestToRescuePointRelay
+)
+
+func init() {
+	RegisterProtocol(ProtocolBasic, func() Protocol { return &reportingProtocol{name: ProtocolBasic, relay: noRelay} })
+	RegisterProtocol(ProtocolLocalRelay, func() Protocol {
+		return &reportingProtocol{name: ProtocolLocalRelay, patrol: true, relay: neighbourRelay}
+	})
+	RegisterProtocol(ProtocolMultiHop, func() Protocol {
+		return &reportingProtocol{name: ProtocolMultiHop, patrol: true, relay: networkRelay}
+	})
+	RegisterProtocol(ProtocolMultiHopOptimized, func() Protocol {
+		return &reportingProtocol{name: ProtocolMultiHopOptimized, patrol: true, relay: closestToRescuePointRelay}
+	})
+}
+
+// reportingProtocol is shared by the built-in protocols: the drone looks for people in distress,
+// reports them to the closest rescue point when in range, and otherwise relays them or flies there.
+type reportingProtocol struct {
+	name   string
+	patrol bool // Sweep the watched zone instead of moving randomly
+	relay  relayMode
+}
+
+func (p *reportingProtocol) Name() string {
+	return p.name
+}
+
+func (p *reportingProtocol) Init(d *Drone) {
+	if p.patrol {
+		d.Memory.DronePatrolPath = append(d.Memory.DronePatrolPath, models.Position{X: d.MyWatch.CornerBottomLeft.X, Y: d.MyWatch.CornerTopRight.Y})
+		d.Memory.DroneActualTarget = models.Position{X: d.MyWatch.CornerBottomLeft.X, Y: d.MyWatch.CornerTopRight.Y}
+		d.Memory.ReturningToStart = false
+	}
+	if d.debug {
+		fmt.Printf("[DRONE %d] - Protocol %s initialized\n", d.ID, p.name)
+	}
+}
+
+// OnMessage has nothing to do, the drone takes the handovers itself.
+func (p *reportingProtocol) OnMessage(d *Drone, msg bus.Message) {}
+
+func (p *reportingProtocol) move(d *Drone) models.Position {
+	if d.HoldsPosition() {
+		return d.holdPosition()
+	}
+	if d.Belief != nil {
+		return d.planPatrol()
+	}
+	if p.patrol {
+		return d.patrolMovementLogic()
+	}
+	return d.randomMovement()
+}
+
+func (p *reportingProtocol) Think(d *Drone) models.Position {
+	if d.IsCharging {
+		// Drone AFK quand il charge car il est docké.
+		return d.Position
+	}
+	if p.relay == networkRelay || p.relay == closestToRescuePointRelay {
+		d.DroneNetwork = d.GetDroneNetwork(d).Drones
+	}
+
+	d.NoteDistress()
+	if target, loitering := d.Loiter(); loitering {
+		// Confirmer l'incident avant de le signaler
+		return target
+	}
+
+	toSave := d.PersonsToSave()
+	if len(toSave) == 0 {
+		// Je patrouille
+		return p.move(d)
+	}
+
+	rp := d.GetRescuePoint(d.Position)
+	if rp == nil {
+		if d.debug {
+			fmt.Printf("[DRONE-WARNING] - Cannot find any RP. Is your Map Config correct?")
+		}
+		return p.move(d)
+	}
+
+	if rp.Position.CalculateDistance(d.Position) <= float64(d.DroneCommRange) {
+		d.ReportPersonsToSave(rp, toSave)
+		return p.move(d)
+	}
+
+	if relay := p.relayDrone(d, rp); relay != nil {
+		d.HandOver(relay, toSave)
+		return p.move(d)
+	}
+	return d.nextStepToPos(rp.Position)
+}
+
+// Avoid turns right of the intruders. When the drone closest to the rescue point flies there, an intruder
+// closer to the rescue point takes over the reports instead and the drone goes back to its zone.
+func (p *reportingProtocol) Avoid(d *Drone, target models.Position, intruders []*Drone) models.Position {
+	toSave := d.PersonsToSave()
+	rp := d.GetRescuePoint(d.Position)
+	if p.relay != closestToRescuePointRelay || len(toSave) == 0 || rp == nil {
+		return d.TurnRight(target, intruders)
+	}
+	for _, intruder := range intruders {
+		closer := rp.Position.CalculateDistance(d.PeerPosition(intruder)) < rp.Position.CalculateDistance(d.Position)
+		if closer && !d.PeerLowBattery(intruder) {
+			d.HandOver(intruder, toSave)
+			next := p.move(d)
+			if d.clearOf(next, intruders) {
+				return next
+			}
+			return d.TurnRight(next, intruders)
+		}
+	}
+	return d.TurnRight(target, intruders)
+}
+
+// relayDrone chooses the drone that takes over the reports, nil if d must fly to the rescue point.
+func (p *reportingProtocol) relayDrone(d *Drone, rp *rescue.RescuePoint) *Drone {
+	var candidates []*Drone
+	switch p.relay {
+	case neighbourRelay:
+		candidates = d.DroneInComRange
+	case networkRelay, closestToRescuePointRelay:
+		candidates = d.DroneNetwork
+	default:
+		return nil
+	}
+
+	closest := d
+	closestDist := d.Position.CalculateDistance(rp.Position)
+	for _, friend := range candidates {
+		// Position annoncée par la dernière balise du voisin
+		friendPos := d.PeerPosition(friend)
+		rpFriend := d.GetRescuePoint(friendPos)
+		if rpFriend == nil || d.PeerLowBattery(friend) {
+			continue
+		}
+		friendDist := rpFriend.Position.CalculateDistance(friendPos)
+		if friendDist <= float64(friend.DroneCommRange) {
+			return friend
+		}
+		// Si le drone ne peut pas communiquer, regarder si un drone voisin à lui peut communiquer.
+		if friendDist < closestDist {
+			closest, closestDist = friend, friendDist
+		}
+	}
+
+	if p.relay == closestToRescuePointRelay && closest != d {
+		return closest
+	}
+	return nil
+}
+
+// PersonsToSave returns the people in distress the drone still has to report,
+// without those of the handovers waiting for an ack.
+func (d *Drone) PersonsToSave() []*persons.Person {
+	handingOver := d.handingOver()
+	toSave := make([]*persons.Person, 0)
+	d.Memory.Persons.PersonsToSave.Range(func(key, value interface{}) bool {
+		if !handingOver[key.(int)] {
+			toSave = append(toSave, value.(*persons.Person))
+		}
+		return true
+	})
+	return toSave
+}
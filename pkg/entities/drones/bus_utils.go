@@ -0,0 +1,197 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/entities/drones/interfaces"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/entities/rescue"
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+const (
+	HANDOVER_ACK_TIMEOUT = 6  // Ticks before an unacknowledged handover or report is sent again
+	BEACON_PERIOD        = 10 // Ticks between two position beacons
+	HEARTBEAT_PERIOD     = 20 // Ticks between two heartbeats, half a beacon period after one
+)
+
+// BusCounters counts the handovers and reports of a drone, the simulation sums them.
+type BusCounters struct {
+	Handovers      int // Handover requests sent
+	HandoversAcked int
+	Reports        int // Incident reports sent to a rescue point
+	ReportsAcked   int
+	Expired        int // Handovers and reports without ack in time, sent again
+	AckTicks       int // Summed over the acks
+}
+
+func (d *Drone) BusAddress() bus.Address {
+	return bus.Address{Kind: bus.DroneNode, ID: d.ID}
+}
+
+func (d *Drone) Deliver(msg bus.Message) {
+	d.Inbox.Push(msg)
+}
+
+// ReadInbox handles the messages received since the last turn, then gives them to the protocol.
+// A crashed drone loses them.
+func (d *Drone) ReadInbox() {
+	messages := d.Inbox.Drain()
+	if d.Crashed {
+		return
+	}
+	for _, msg := range messages {
+		switch msg.Type {
+		case bus.HandoverRequest:
+			for _, person := range msg.Persons {
+				d.Memory.Persons.PersonsToSave.Store(person.ID, person)
+			}
+			if peer, ok := msg.Sender.(*Drone); ok {
+				d.Transmit(peer, bus.Message{Type: bus.HandoverAck, Seq: msg.Seq, Persons: msg.Persons})
+			}
+		case bus.HandoverAck:
+			d.acknowledge(msg)
+		case bus.Heartbeat, bus.PositionBeacon:
+			d.updatePeer(msg)
+		case bus.ConfirmRequest:
+			d.checkFor(msg)
+		case bus.ConfirmReply:
+			d.receiveConfirmation(msg)
+		case bus.BeliefShare:
+			d.mergeBelief(msg)
+		}
+		if d.Protocol != nil {
+			d.Protocol.OnMessage(d, msg)
+		}
+	}
+	d.expireHandovers()
+}
+
+// HandOver asks another drone to take over the reports, they are forgotten once it acknowledges.
+func (d *Drone) HandOver(to *Drone, toSave []*persons.Person) {
+	seq := d.trackHandover(to.ID, toSave)
+	d.Bus.Handovers++
+	d.Transmit(to, bus.Message{Type: bus.HandoverRequest, Seq: seq, Persons: toSave})
+}
+
+// ReportPersonsToSave asks the rescue point to send a rescuer to each person, the rescue point
+// acknowledges the people it takes in charge and the others are reported again.
+func (d *Drone) ReportPersonsToSave(rp *rescue.RescuePoint, toSave []*persons.Person) {
+	if d.RadioLink != nil && !d.RadioLink(d, rp.Position) {
+		// Transmission perdue, nouvel essai au prochain tick
+		return
+	}
+	incidents := make([]bus.Incident, 0, len(toSave))
+	for _, person := range toSave {
+		incidents = append(incidents, bus.Incident{Person: person, Severity: d.EstimateSeverity(person), DistressType: d.EstimateDistressType(person)})
+	}
+	seq := d.trackHandover(rp.ID, toSave)
+	d.Bus.Reports++
+	d.Send(rp, bus.Message{Type: bus.IncidentReport, Seq: seq, Incidents: incidents})
+}
+
+// trackHandover keeps the people of a handover or a report until the ack, and returns its Seq.
+func (d *Drone) trackHandover(to int, toSave []*persons.Person) int {
+	if d.Memory.Handovers == nil {
+		d.Memory.Handovers = make(map[int]interfaces.PendingHandover)
+	}
+	d.Memory.NextSeq++
+	ids := make([]int, 0, len(toSave))
+	for _, person := range toSave {
+		ids = append(ids, person.ID)
+	}
+	d.Memory.Handovers[d.Memory.NextSeq] = interfaces.PendingHandover{To: to, PersonIDs: ids, SentTick: d.currentTick()}
+	return d.Memory.NextSeq
+}
+
+// acknowledge forgets the people the receiver took over. A late ack still counts for them,
+// the people may then be reported twice but never lost.
+func (d *Drone) acknowledge(msg bus.Message) {
+	for _, person := range msg.Persons {
+		d.Memory.Persons.PersonsToSave.Delete(person.ID)
+	}
+	pending, exists := d.Memory.Handovers[msg.Seq]
+	if !exists {
+		return
+	}
+	delete(d.Memory.Handovers, msg.Seq)
+	d.Bus.AckTicks += d.currentTick() - pending.SentTick
+	if msg.From.Kind == bus.RescuePointNode {
+		d.Bus.ReportsAcked++
+	} else {
+		d.Bus.HandoversAcked++
+	}
+}
+
+// expireHandovers gives back the people of the handovers without ack, they are handed over again.
+func (d *Drone) expireHandovers() {
+	tick := d.currentTick()
+	for seq, pending := range d.Memory.Handovers {
+		if tick-pending.SentTick > HANDOVER_ACK_TIMEOUT {
+			delete(d.Memory.Handovers, seq)
+			d.Bus.Expired++
+			if d.debug {
+				fmt.Printf("[DRONE %d] - No ack from %d for handover %d, %d people kept\n", d.ID, pending.To, seq, len(pending.PersonIDs))
+			}
+		}
+	}
+}
+
+// handingOver returns the people waiting for an ack.
+func (d *Drone) handingOver() map[int]bool {
+	ids := make(map[int]bool)
+	for _, pending := range d.Memory.Handovers {
+		for _, id := range pending.PersonIDs {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+func (d *Drone) updatePeer(msg bus.Message) {
+	if d.Memory.Peers == nil {
+		d.Memory.Peers = make(map[int]interfaces.PeerStatus)
+	}
+	peer := d.Memory.Peers[msg.From.ID]
+	if msg.Type == bus.Heartbeat {
+		peer.Battery = msg.Battery
+		peer.LastHeartbeat = msg.Tick
+	} else {
+		peer.Position = msg.Position
+		peer.LastBeacon = msg.Tick
+	}
+	d.Memory.Peers[msg.From.ID] = peer
+}
+
+// broadcastStatus sends the position beacon and the heartbeat to the neighbours when they are due,
+// the drones are staggered by ID to spread the load on the radio.
+func (d *Drone) broadcastStatus() {
+	tick := d.currentTick()
+	beacon := (tick+d.ID)%BEACON_PERIOD == 0
+	heartbeat := (tick+d.ID)%HEARTBEAT_PERIOD == BEACON_PERIOD/2
+	if !beacon && !heartbeat {
+		return
+	}
+	for _, peer := range d.DroneInComRange {
+		if beacon {
+			d.Transmit(peer, bus.Message{Type: bus.PositionBeacon, Position: d.Position})
+		}
+		if heartbeat {
+			d.Transmit(peer, bus.Message{Type: bus.Heartbeat, Battery: d.Battery})
+		}
+	}
+}
+
+// PeerPosition returns where a drone said it was in its last beacon, its position at the start of the tick if it never did.
+func (d *Drone) PeerPosition(peer *Drone) models.Position {
+	if status, exists := d.Memory.Peers[peer.ID]; exists && status.LastBeacon > 0 {
+		return status.Position
+	}
+	return peer.Beacon
+}
+
+// PeerLowBattery tells if the last heartbeat of a drone said it would soon have to charge.
+func (d *Drone) PeerLowBattery(peer *Drone) bool {
+	status, exists := d.Memory.Peers[peer.ID]
+	return exists && status.LastHeartbeat > 0 && status.Battery <= d.chargingReserve()
+}
@@ -0,0 +1,200 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+// DISMISS_CONFIDENCE is the confidence under which a suspected incident is dropped before the end of the confirmation.
+const DISMISS_CONFIDENCE = 0.05
+
+// ConfirmationPolicy decides which reads a drone confirms before reporting them to the rescue point.
+type ConfirmationPolicy struct {
+	Threshold float64 // Confidence under which a read is confirmed first, 0 to report every read
+	Ticks     int     // Ticks to confirm, loitering over the person or waiting for the reply of the peer
+	AskPeer   bool    // Ask the closest neighbour to read the person, loiter when there is none
+}
+
+func (p ConfirmationPolicy) Enabled() bool {
+	return p.Threshold > 0 && p.Ticks > 0
+}
+
+// ConfirmationCounters counts the incidents a drone confirmed, the simulation sums them.
+type ConfirmationCounters struct {
+	Suspicions   int // Reads held for a confirmation
+	Confirmed    int
+	Dismissed    int // Not confirmed in time, or read fine again
+	PeerChecks   int // Confirmations asked to a neighbour
+	ConfirmTicks int // Summed over the confirmed incidents
+}
+
+// Suspicion is a person read in distress with a confidence too low to be reported yet.
+type Suspicion struct {
+	Person     *persons.Person
+	Confidence float64
+	Since      int
+	Reads      int    // Reads of the person since the suspicion started
+	Asked      bool   // A neighbour reads the person for the drone
+	Replied    bool   // The neighbour answered
+	For        *Drone // Neighbour the drone reads the person for, nil for its own reads
+}
+
+// NoteDistress stores the people read in distress to be reported. Under the confirmation policy a read
+// with a low confidence becomes a suspicion, the next reads raise or lower it until it is confirmed or dropped.
+func (d *Drone) NoteDistress() {
+	for _, person := range d.SeenPeople {
+		if suspicion, exists := d.Suspicions[person.ID]; exists {
+			suspicion.Confidence = models.CombineConfidence(suspicion.Confidence, d.SeenConfidence[person.ID])
+			suspicion.Reads++
+		}
+	}
+	for _, person := range d.SeenInDistress {
+		if _, exists := d.Suspicions[person.ID]; exists {
+			continue
+		}
+		if _, known := d.Memory.Persons.PersonsToSave.Load(person.ID); known {
+			continue
+		}
+		confidence := d.SeenConfidence[person.ID]
+		if !d.Confirmation.Enabled() || confidence >= d.Confirmation.Threshold {
+			d.Memory.Persons.PersonsToSave.Store(person.ID, person)
+			continue
+		}
+		d.suspect(person, confidence)
+	}
+	d.resolveSuspicions()
+}
+
+func (d *Drone) suspect(person *persons.Person, confidence float64) {
+	if d.Suspicions == nil {
+		d.Suspicions = make(map[int]*Suspicion)
+	}
+	suspicion := &Suspicion{Person: person, Confidence: confidence, Since: d.currentTick()}
+	d.Suspicions[person.ID] = suspicion
+	d.Confirmations.Suspicions++
+	if !d.Confirmation.AskPeer {
+		return
+	}
+	if peer := d.closestPeer(person.Position); peer != nil {
+		suspicion.Asked = true
+		d.Confirmations.PeerChecks++
+		d.Transmit(peer, bus.Message{Type: bus.ConfirmRequest, Persons: []*persons.Person{person}, Position: person.Position})
+	}
+}
+
+// closestPeer returns the neighbour closest to pos that can spare the time to read a person, nil if none.
+func (d *Drone) closestPeer(pos models.Position) *Drone {
+	var closest *Drone
+	closestDist := 0.0
+	for _, peer := range d.DroneInComRange {
+		if d.PeerLowBattery(peer) {
+			continue
+		}
+		peerPos := d.PeerPosition(peer)
+		dist := peerPos.CalculateDistance(pos)
+		if closest == nil || dist < closestDist {
+			closest = peer
+			closestDist = dist
+		}
+	}
+	return closest
+}
+
+// checkFor starts reading a person for the neighbour who asked, from the prior confidence: the reply
+// only carries the reads of the drone, merged by the neighbour with its own.
+func (d *Drone) checkFor(msg bus.Message) {
+	peer, ok := msg.Sender.(*Drone)
+	if !ok || len(msg.Persons) == 0 {
+		return
+	}
+	if !d.canConfirm() {
+		// Occupé, le voisin conclura seul
+		return
+	}
+	if d.Suspicions == nil {
+		d.Suspicions = make(map[int]*Suspicion)
+	}
+	person := msg.Persons[0]
+	if _, exists := d.Suspicions[person.ID]; exists {
+		return
+	}
+	d.Suspicions[person.ID] = &Suspicion{Person: person, Confidence: models.DISTRESS_PRIOR, Since: d.currentTick(), For: peer}
+}
+
+// canConfirm tells if the drone is free to leave its patrol to read a person for a neighbour.
+func (d *Drone) canConfirm() bool {
+	return !d.IsCharging && d.DroneState == NoDefinedState && d.Search == nil && d.Evacuation == nil &&
+		!d.HoldsPosition() && d.Battery > d.chargingReserve()
+}
+
+// receiveConfirmation merges the read of the neighbour with the suspicion, it is resolved at the next turn.
+func (d *Drone) receiveConfirmation(msg bus.Message) {
+	if len(msg.Persons) == 0 {
+		return
+	}
+	suspicion, exists := d.Suspicions[msg.Persons[0].ID]
+	if !exists || suspicion.For != nil {
+		return
+	}
+	suspicion.Confidence = models.CombineConfidence(suspicion.Confidence, msg.Confidence)
+	suspicion.Replied = true
+}
+
+// resolveSuspicions reports the confirmed people and drops the others once the confirmation is over.
+// A suspicion read for a neighbour ends with the reply, it starts from the prior and is not dismissed
+// before the drone has read the person.
+func (d *Drone) resolveSuspicions() {
+	tick := d.currentTick()
+	for id, suspicion := range d.Suspicions {
+		confirmed := suspicion.Confidence >= d.Confirmation.Threshold
+		deadline := suspicion.Since + d.Confirmation.Ticks
+		if suspicion.Asked {
+			// Laisser au voisin le temps de venir et de répondre
+			deadline += HANDOVER_ACK_TIMEOUT
+		}
+		unread := suspicion.For != nil && suspicion.Reads == 0
+		dismissed := !unread && suspicion.Confidence < DISMISS_CONFIDENCE
+		over := confirmed || suspicion.Replied || dismissed || tick >= deadline
+		if !over {
+			continue
+		}
+		delete(d.Suspicions, id)
+		if suspicion.For != nil {
+			d.Transmit(suspicion.For, bus.Message{Type: bus.ConfirmReply, Persons: []*persons.Person{suspicion.Person}, Confidence: suspicion.Confidence})
+			continue
+		}
+		if confirmed {
+			d.Memory.Persons.PersonsToSave.Store(id, suspicion.Person)
+			d.Confirmations.Confirmed++
+			d.Confirmations.ConfirmTicks += tick - suspicion.Since
+		} else {
+			d.Confirmations.Dismissed++
+		}
+		if d.debug {
+			fmt.Printf("[DRONE %d] - Person %d confirmed: %t (confidence %.2f)\n", d.ID, id, confirmed, suspicion.Confidence)
+		}
+	}
+}
+
+// Loiter returns the next step to watch the oldest suspicion the drone reads itself, false if there is none.
+func (d *Drone) Loiter() (models.Position, bool) {
+	var watched *Suspicion
+	for _, suspicion := range d.Suspicions {
+		if suspicion.Asked {
+			continue
+		}
+		if watched == nil || suspicion.Since < watched.Since ||
+			(suspicion.Since == watched.Since && suspicion.Person.ID < watched.Person.ID) {
+			watched = suspicion
+		}
+	}
+	if watched == nil {
+		return d.Position, false
+	}
+	if d.Position.CalculateDistance(watched.Person.Position) <= 1 {
+		return d.Position, true
+	}
+	return d.nextStepToPos(watched.Person.Position), true
+}
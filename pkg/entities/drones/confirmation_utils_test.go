@@ -0,0 +1,108 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"testing"
+)
+
+// newConfirmingDrone returns a drone able to confirm reads for its neighbours: full battery, a charging
+// station under it, and the messages delivered at once.
+func newConfirmingDrone(id int, position models.Position, tick *int) *Drone {
+	stations := map[models.POIType][]models.Position{models.ChargingStation: {position}}
+	d := NewSurveillanceDrone(id, position, models.MyWatch{}, 100, 4, 6,
+		nil, nil, nil, nil, nil, stations, nil, nil, nil, nil, nil, 30, 30, false)
+	d.GetTick = func() int { return *tick }
+	d.Confirmation = ConfirmationPolicy{Threshold: 0.5, Ticks: 5, AskPeer: true}
+	return &d
+}
+
+// see gives the drone a read of the person for the turn.
+func see(d *Drone, person *persons.Person, inDistress bool, confidence float64) {
+	d.SeenPeople = []*persons.Person{person}
+	d.SeenInDistress = nil
+	if inDistress {
+		d.SeenInDistress = []*persons.Person{person}
+	}
+	d.SeenConfidence = map[int]float64{person.ID: confidence}
+}
+
+func seeNobody(d *Drone) {
+	d.SeenPeople = nil
+	d.SeenInDistress = nil
+	d.SeenConfidence = map[int]float64{}
+}
+
+func TestPeerConfirmationRoundTrip(t *testing.T) {
+	cases := []struct {
+		name       string
+		peerRead   bool    // The neighbour reads the person in distress
+		peerConf   float64 // Confidence of the read of the neighbour
+		wantReport bool
+	}{
+		{"confirmed by the peer", true, 0.6, true},
+		{"dismissed by the peer", false, 0.005, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tick := 1
+			requester := newConfirmingDrone(0, models.Position{X: 5, Y: 5}, &tick)
+			helper := newConfirmingDrone(1, models.Position{X: 8, Y: 5}, &tick)
+			requester.DroneInComRange = []*Drone{helper}
+			helper.DroneInComRange = []*Drone{requester}
+			person := &persons.Person{ID: 7, Position: models.Position{X: 6, Y: 5}}
+
+			// Lecture douteuse : le drone demande au voisin de confirmer
+			see(requester, person, true, 0.2)
+			requester.NoteDistress()
+			suspicion, exists := requester.Suspicions[person.ID]
+			if !exists || !suspicion.Asked {
+				t.Fatalf("requester should hold an asked suspicion, got %+v", suspicion)
+			}
+			if requester.Confirmations.PeerChecks != 1 {
+				t.Fatalf("PeerChecks = %d, want 1", requester.Confirmations.PeerChecks)
+			}
+
+			// Le voisin n'a pas encore vu la personne : il ne doit pas répondre avec le prior
+			tick++
+			helper.ReadInbox()
+			seeNobody(helper)
+			helper.NoteDistress()
+			if _, exists := helper.Suspicions[person.ID]; !exists {
+				t.Fatalf("helper dropped the suspicion before reading the person")
+			}
+			if pending := requester.Inbox.Drain(); len(pending) != 0 {
+				t.Fatalf("helper replied before reading the person: %+v", pending)
+			}
+
+			// Le voisin lit la personne et répond
+			tick++
+			see(helper, person, c.peerRead, c.peerConf)
+			helper.NoteDistress()
+			if _, exists := helper.Suspicions[person.ID]; exists {
+				t.Fatalf("helper should have replied after its read")
+			}
+
+			tick++
+			requester.ReadInbox()
+			if !suspicion.Replied {
+				t.Fatalf("requester did not get the reply")
+			}
+			seeNobody(requester)
+			requester.NoteDistress()
+			if _, exists := requester.Suspicions[person.ID]; exists {
+				t.Fatalf("requester should have resolved the suspicion with the reply")
+			}
+			_, reported := requester.Memory.Persons.PersonsToSave.Load(person.ID)
+			if reported != c.wantReport {
+				t.Fatalf("reported = %t, want %t (confidence %.3f)", reported, c.wantReport, suspicion.Confidence)
+			}
+			if c.wantReport && (requester.Confirmations.Confirmed != 1 || requester.Confirmations.ConfirmTicks != 3) {
+				t.Fatalf("confirmations = %+v, want one confirmed in 3 ticks", requester.Confirmations)
+			}
+			if !c.wantReport && requester.Confirmations.Dismissed != 1 {
+				t.Fatalf("confirmations = %+v, want one dismissed", requester.Confirmations)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package drones
+
+import "fmt"
+
+// reportCrowdRisk sends the hotspots seen by the drone to the nearest rescue point.
+func (d *Drone) reportCrowdRisk() {
+	if d.CrowdRiskFunc == nil || d.GetRescuePoint == nil {
+		return
+	}
+	reports := d.CrowdRiskFunc(d)
+	if len(reports) == 0 {
+		return
+	}
+	rp := d.GetRescuePoint(d.Position)
+	if rp == nil {
+		return
+	}
+	for _, report := range reports {
+		alert := rp.ReportCrowdRisk(report)
+		if d.debug {
+			fmt.Printf("[DRONE %d] - Crowd hotspot at %v reported to RP %d (alert %d, %s)\n",
+				d.ID, report.Cell.Position, rp.ID, alert.ID, alert.Level)
+		}
+	}
+}
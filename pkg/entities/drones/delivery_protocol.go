@@ -0,0 +1,80 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+)
+
+const ProtocolKitDelivery = "kit-delivery" // Multi-hop, and the drone brings a first-aid kit or an AED to the severe cases
+
+const (
+	KIT_MIN_SEVERITY = models.SeverityModerate // Lighter incidents wait for the rescuers
+	KIT_MAX_TRIP     = 60                      // Ticks to the tent then to the person beyond which the rescuers come first
+)
+
+func init() {
+	RegisterProtocol(ProtocolKitDelivery, func() Protocol {
+		return &deliveryProtocol{reportingProtocol{name: ProtocolKitDelivery, patrol: true, relay: networkRelay}}
+	})
+}
+
+// deliveryProtocol reports like the multi-hop protocol, then brings a kit from a medical tent to the most
+// severe person it sees in distress, if the drone can carry it. The kit buys time until the rescuer arrives, it does not replace them.
+type deliveryProtocol struct {
+	reportingProtocol
+}
+
+func (p *deliveryProtocol) Think(d *Drone) models.Position {
+	// Le signalement passe d'abord, le secouriste reste indispensable
+	target := p.reportingProtocol.Think(d)
+	if d.IsCharging {
+		return target
+	}
+	if d.Delivery == nil && d.CanCarry() && !d.HoldsPosition() {
+		if person, kit := p.kitCandidate(d); person != nil {
+			d.StartDelivery(person, kit)
+		}
+	}
+	if d.Delivery != nil {
+		return d.ThinkDelivery()
+	}
+	return target
+}
+
+// Avoid climbs to a clear altitude layer when the drone carries a kit, it keeps its course to the
+// person. The other drones turn right.
+func (p *deliveryProtocol) Avoid(d *Drone, target models.Position, intruders []*Drone) models.Position {
+	if d.Delivery != nil && d.HasMedicalGear && d.Climb(target) {
+		return target
+	}
+	return d.TurnRight(target, intruders)
+}
+
+// kitCandidate chooses the most severe person in distress the drone sees, without a kit yet and close
+// enough to a medical tent for the endurance of the drone, nil if there is none.
+func (p *deliveryProtocol) kitCandidate(d *Drone) (*persons.Person, models.Equipment) {
+	var chosen *persons.Person
+	chosenSeverity := models.SeverityNone
+	tent, hasTent := d.closestMedicalTent()
+	for _, person := range d.SeenInDistress {
+		if _, suspected := d.Suspicions[person.ID]; suspected || person.HasReceivedMedical {
+			continue
+		}
+		severity := d.EstimateSeverity(person)
+		if severity < KIT_MIN_SEVERITY || severity <= chosenSeverity {
+			continue
+		}
+		trip := d.flightTicks(d.Position, person.Position)
+		if !d.carries(KitFor(severity)) {
+			if !hasTent {
+				continue
+			}
+			trip = d.flightTicks(d.Position, tent) + KIT_LOADING_TICKS + d.flightTicks(tent, person.Position)
+		}
+		if trip > KIT_MAX_TRIP || float64(trip) > d.Endurance() {
+			continue
+		}
+		chosen, chosenSeverity = person, severity
+	}
+	return chosen, KitFor(chosenSeverity)
+}
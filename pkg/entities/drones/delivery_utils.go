@@ -0,0 +1,156 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+const (
+	KIT_LOADING_TICKS   = 2 // Ticks to load a kit at a medical tent
+	DELIVERY_FINE_READS = 3 // Reads of the person out of distress in a row before the drone gives up, a single read can be wrong
+)
+
+// KitDelivery is a first-aid kit or an AED a drone brings to a person in distress, from a medical tent.
+type KitDelivery struct {
+	Person    *persons.Person
+	Kit       models.Equipment
+	Tent      models.Position
+	Claimed   bool // The simulation reserved the person for the drone, which holds the kit
+	FineReads int  // Reads out of distress in a row next to the person
+}
+
+// KitFor returns the kit a drone brings for an incident of the estimated severity, an AED for the critical ones.
+func KitFor(severity models.Severity) models.Equipment {
+	if severity == models.SeverityCritical {
+		return models.Defibrillator
+	}
+	return models.FirstAidKit
+}
+
+// StartDelivery sends the drone to the closest medical tent for the kit, straight to the person if it carries it.
+func (d *Drone) StartDelivery(person *persons.Person, kit models.Equipment) bool {
+	delivery := &KitDelivery{Person: person, Kit: kit}
+	if !d.carries(kit) {
+		tent, found := d.closestMedicalTent()
+		if !found {
+			return false
+		}
+		delivery.Tent = tent
+		d.MedicalTentTimer = KIT_LOADING_TICKS
+	}
+	d.Delivery = delivery
+	if d.debug {
+		fmt.Printf("[DRONE %d] - Bringing a %s to person %d\n", d.ID, kit, person.ID)
+	}
+	return true
+}
+
+func (d *Drone) carries(kit models.Equipment) bool {
+	return d.HasMedicalGear && d.MedicalKit == kit
+}
+
+func (d *Drone) closestMedicalTent() (models.Position, bool) {
+	var closest models.Position
+	found := false
+	for _, tent := range d.MapPoi[models.MedicalTent] {
+		if !found || d.Position.CalculateDistance(tent) < d.Position.CalculateDistance(closest) {
+			closest, found = tent, true
+		}
+	}
+	return closest, found
+}
+
+// ThinkDelivery flies to the tent, loads the kit, then flies to the person and drops it. The drone
+// keeps the kit for the next delivery when the person is gone, or reads fine DELIVERY_FINE_READS
+// times in a row; it then gives the person up to the other drones.
+func (d *Drone) ThinkDelivery() models.Position {
+	delivery := d.Delivery
+	person := delivery.Person
+	if person.IsDead() || !person.StillInSim {
+		d.Delivery = nil
+		return d.Position
+	}
+
+	if !delivery.Claimed {
+		if !d.carries(delivery.Kit) {
+			if d.Position != delivery.Tent {
+				return d.nextStepToPos(delivery.Tent)
+			}
+			if d.MedicalTentTimer > 0 {
+				d.MedicalTentTimer--
+				return d.Position
+			}
+		}
+		if !d.loadKit(delivery) {
+			d.Delivery = nil
+			return d.Position
+		}
+		delivery.Claimed = true
+		return d.Position
+	}
+
+	if d.Position.CalculateDistance(person.Position) > 1 {
+		return d.nextStepToPos(person.Position)
+	}
+	if d.readFine(person) {
+		delivery.FineReads++
+		if delivery.FineReads >= DELIVERY_FINE_READS {
+			// Déjà secourue, ou fausse alerte : le kit reste à bord
+			d.releaseKit(delivery)
+			d.Delivery = nil
+		}
+		return d.Position
+	}
+	d.dropKit(delivery)
+	d.Delivery = nil
+	return d.Position
+}
+
+// readFine tells if the camera sees the person and reads them out of distress.
+func (d *Drone) readFine(person *persons.Person) bool {
+	for _, seen := range d.SeenPeople {
+		if seen.ID == person.ID {
+			return !d.SawInDistress(person)
+		}
+	}
+	return false
+}
+
+// loadKit asks the simulation for the kit and reserves the person, another drone may already bring one.
+func (d *Drone) loadKit(delivery *KitDelivery) bool {
+	responseChan := make(chan models.MedicalDeliveryResponse)
+	d.MedicalDeliveryChan <- models.MedicalDeliveryRequest{PersonID: delivery.Person.ID, DroneID: d.ID, Kit: delivery.Kit, ResponseChan: responseChan}
+	response := <-responseChan
+	if !response.Authorized {
+		if d.debug {
+			fmt.Printf("[DRONE %d] - No %s for person %d: %s\n", d.ID, delivery.Kit, delivery.Person.ID, response.Reason)
+		}
+		return false
+	}
+	d.HasMedicalGear = true
+	d.MedicalKit = delivery.Kit
+	return true
+}
+
+// releaseKit gives the person up, another drone may bring a kit.
+func (d *Drone) releaseKit(delivery *KitDelivery) {
+	responseChan := make(chan models.MedicalDeliveryResponse)
+	d.MedicalDeliveryChan <- models.MedicalDeliveryRequest{PersonID: delivery.Person.ID, DroneID: d.ID, Kit: delivery.Kit, Release: true, ResponseChan: responseChan}
+	<-responseChan
+	if d.debug {
+		fmt.Printf("[DRONE %d] - Person %d read fine, %s kept on board\n", d.ID, delivery.Person.ID, delivery.Kit)
+	}
+}
+
+func (d *Drone) dropKit(delivery *KitDelivery) {
+	responseChan := make(chan models.SavePersonResponse)
+	d.SavePersonChan <- models.SavePersonRequest{PersonID: delivery.Person.ID, DroneID: d.ID, Kit: delivery.Kit, ResponseChan: responseChan}
+	response := <-responseChan
+	if response.Authorized {
+		d.HasMedicalGear = false
+	}
+	if d.debug {
+		fmt.Printf("[DRONE %d] - Kit for person %d: %s\n", d.ID, delivery.Person.ID, response.Reason)
+	}
+}
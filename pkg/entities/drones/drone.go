@@ -1,11 +1,13 @@
 package drones
 
 import (
+	"UTC_IA04/pkg/entities/bus"
 	"UTC_IA04/pkg/entities/drones/interfaces"
 	"UTC_IA04/pkg/entities/persons"
 	"UTC_IA04/pkg/entities/rescue"
 	"UTC_IA04/pkg/models"
 	"fmt"
+	"math"
 )
 
 type DroneState int
@@ -22,39 +24,77 @@ type DroneEffectiveNetwork struct {
 
 type Drone struct {
 	ID               int
+	Type             DroneType
 	DroneSeeRange    int
 	DroneCommRange   int
 	Position         models.Position
+	Beacon           models.Position // Position broadcast at the start of the tick, read by the other drones during their turn
+	Velocity         models.Position // Cells flown on each axis during the last tick
+	TickSeconds      float64         // Seconds simulated by a tick, given by the simulation
 	Battery          float64
 	SeenPeople       []*persons.Person
+	SeenInDistress   []*persons.Person // Seen people the camera reads in distress, rightly or not
+	SeenConfidence   map[int]float64   // Chance that each seen person is in distress, after the read
 	DroneInComRange  []*Drone
 	DroneNetwork     []*Drone
 	MapPoi           map[models.POIType][]models.Position
 	IsCharging       bool
+	WaitingToCharge  bool             // Landed at a station, queued for a pad
+	ChargingTarget   *models.Position // Station chosen to charge, nil when not going to charge
+	SwapTicksLeft    int              // Ticks before the end of a battery swap
+	Crashed          bool
+	GPSError         models.Position // Offset from the estimated Position to the true position, under GPS drift
 	MedicalTentTimer int
 	DeploymentTimer  int
 	PeopleToSave     *persons.Person
 	Objectif         models.Position
 	HasMedicalGear   bool
-	ProtocolMode     int      // 1 = protocol 1, 2 = protocol 2, 3 = protocol 3
-	Rescuer          *Rescuer 
+	MedicalKit       models.Equipment // Kit carried when HasMedicalGear
+	Protocol         Protocol
+	Rescuer          *Rescuer
 	MapWidth         int
 	MapHeight        int
-	DroneState DroneState
-	MyWatch    models.MyWatch
+	DroneState       DroneState
+	MyWatch          models.MyWatch
+	MaxWindSpeed     float64
+	Energy           EnergyModel
+	Evacuation       *models.EvacuationOrder // nil outside of an evacuation
+	SearchMissions   []models.SearchMission  // Open missions broadcast by the rescue points
+	Search           *SearchAssignment       // nil when the drone is not dedicated to a search
+	Delivery         *KitDelivery            // nil when the drone is not bringing a kit
+	RelayPost        *models.Position        // Where the drone holds to link a cut-off zone, nil on patrol
+	Layer            int                     // Altitude layer, the separation holds between the drones of a layer; set by the simulation with the moves
+	nextLayer        int                     // Layer requested for the move of the tick
+	peerFlights      map[int]peerFlight      // Neighbours at the start of the tick, by ID
+	Separation       SeparationRule
+	Avoidance        AvoidanceCounters
+	Belief           *BeliefMap // nil when the drone patrols without planner
+	Beliefs          BeliefCounters
+	Inbox            *bus.Inbox
+	Bus              BusCounters
+	Confirmation     ConfirmationPolicy
+	Suspicions       map[int]*Suspicion // Reads waiting for a confirmation, by person
+	Confirmations    ConfirmationCounters
 	// Fonctions factorisé
 	GetRescuePoint      func(pos models.Position) *rescue.RescuePoint
 	DroneSeeFunction    func(d *Drone) []*persons.Person
+	ReadDistressFunc    func(d *Drone, person *persons.Person) (bool, float64)
 	DroneInComRangeFunc func(d *Drone) []*Drone
 	GetDroneNetwork     func(d *Drone) DroneEffectiveNetwork
+	GetWeather          func() models.WeatherConditions
+	CrowdRiskFunc       func(d *Drone) []models.CrowdRiskReport
+	GetTick             func() int
+	ChargingWait        func(station models.Position, droneID, arrivalTick int) int
+	RadioSend           func(from, to *Drone, msg bus.Message)
+	RadioLink           func(d *Drone, pos models.Position) bool // Whether a transmission to pos gets through
 	// Différents Chans.
-	MoveChan     chan models.MovementRequest
-	ChargingChan chan models.ChargingRequest
+	MoveChan            chan models.MovementRequest
+	ChargingChan        chan models.ChargingRequest
 	MedicalDeliveryChan chan models.MedicalDeliveryRequest
 	SavePersonChan      chan models.SavePersonRequest
 	SavePersonByRescuer chan models.RescuePeopleRequest
-	Memory interfaces.DroneMemory
-	debug bool
+	Memory              interfaces.DroneMemory
+	debug               bool
 }
 
 func NewSurveillanceDrone(id int,
@@ -71,7 +111,7 @@ func NewSurveillanceDrone(id int,
 	chargingChan chan models.ChargingRequest,
 	medicalDeliveryChan chan models.MedicalDeliveryRequest,
 	savePersonChan chan models.SavePersonRequest,
-	protocolMode int,
+	protocol Protocol,
 	savePersonByRescuer chan models.RescuePeopleRequest,
 	MapWidth int,
 	MapHeight int,
@@ -80,7 +120,9 @@ func NewSurveillanceDrone(id int,
 ) Drone {
 	return Drone{
 		ID:                  id,
+		Type:                DefaultDroneType,
 		Position:            position,
+		Beacon:              position,
 		MyWatch:             myWatch,
 		Battery:             battery,
 		DroneSeeRange:       droneSeeRange,
@@ -88,7 +130,12 @@ func NewSurveillanceDrone(id int,
 		DroneSeeFunction:    droneSeeFunc,
 		DroneInComRangeFunc: droneInComRange,
 		GetDroneNetwork:     getDroneNetwork,
+		MaxWindSpeed:        DEFAULT_MAX_WIND_SPEED,
+		TickSeconds:         models.SECONDS_PER_TICK,
+		Energy:              DefaultEnergyModel,
 		SeenPeople:          []*persons.Person{},
+		SeenInDistress:      []*persons.Person{},
+		SeenConfidence:      make(map[int]float64),
 		DroneInComRange:     []*Drone{},
 		DroneNetwork:        []*Drone{},
 		MoveChan:            moveChan,
@@ -102,7 +149,7 @@ func NewSurveillanceDrone(id int,
 		Objectif:            models.Position{},
 		HasMedicalGear:      false,
 		SavePersonChan:      savePersonChan,
-		ProtocolMode:        protocolMode,
+		Protocol:            protocol,
 		Rescuer:             nil,
 		SavePersonByRescuer: savePersonByRescuer,
 		MapWidth:            MapWidth,
@@ -110,20 +157,14 @@ func NewSurveillanceDrone(id int,
 		DroneState:          NoDefinedState,
 		GetRescuePoint:      getRescuePoint,
 		Memory:              interfaces.DroneMemory{},
+		Inbox:               bus.NewInbox(),
 		debug:               debug,
 	}
 }
 
 func (d *Drone) InitProtocol() {
-	switch d.ProtocolMode {
-	case 1:
-		d.initProtocol1()
-	case 2:
-		d.initProtocol2()
-	case 3:
-		d.initProtocol3()
-	case 4:
-		d.initProtocol4()
+	if d.Protocol != nil {
+		d.Protocol.Init(d)
 	}
 }
 
@@ -132,21 +173,25 @@ func (d *Drone) Move(target models.Position) bool {
 		return false
 	}
 
+	from, layer := d.Position, d.Layer
 	responseChan := make(chan models.MovementResponse)
-	d.MoveChan <- models.MovementRequest{MemberID: d.ID, MemberType: "drone", NewPosition: target, ResponseChan: responseChan}
+	d.MoveChan <- models.MovementRequest{MemberID: d.ID, MemberType: "drone", NewPosition: target, Layer: d.nextLayer, ResponseChan: responseChan}
 	response := <-responseChan
 
 	if response.Authorized {
-		dechargingStep := 0.5
-		if d.Battery >= dechargingStep {
-			d.Battery -= dechargingStep
-		} else {
-			d.Battery = 0.0
+		// La simulation a déjà déplacé le drone sur la carte, et changé sa couche
+		if d.Layer != layer {
+			d.Avoidance.Climbs++
 		}
+		d.Velocity = models.Position{X: target.X - from.X, Y: target.Y - from.Y}
+		d.drain(from.CalculateDistance(target))
 		d.Position = target
 		return true
 	}
 
+	// Bloqué, le drone reste en vol stationnaire
+	d.Velocity = models.Position{}
+	d.drain(0)
 	return false
 }
 
@@ -155,7 +200,44 @@ func (d *Drone) ReceiveInfo() {
 	droneInComRange := d.DroneInComRangeFunc(d)
 
 	d.SeenPeople = seenPeople
+	d.SeenInDistress = make([]*persons.Person, 0)
+	d.SeenConfidence = make(map[int]float64, len(seenPeople))
+	for _, person := range seenPeople {
+		read, confidence := d.ReadDistressFunc(d, person)
+		d.SeenConfidence[person.ID] = confidence
+		if read {
+			d.SeenInDistress = append(d.SeenInDistress, person)
+		}
+	}
 	d.DroneInComRange = droneInComRange
+	// Les voisins sont lus avant les déplacements, pendant lesquels ils changent de position et de couche
+	d.peerFlights = make(map[int]peerFlight, len(droneInComRange))
+	for _, peer := range droneInComRange {
+		d.peerFlights[peer.ID] = peerFlight{
+			Position: peer.Beacon,
+			Layer:    peer.Layer,
+			Flying:   peer.Airborne() && !peer.OnFinalApproach(),
+			Moving:   peer.Velocity != (models.Position{}),
+		}
+	}
+}
+
+// TruePosition returns the cell where the drone really is, the drone only knows its estimate Position.
+func (d *Drone) TruePosition() models.Position {
+	return d.TruePositionOf(d.Position).Cell()
+}
+
+// TruePositionOf returns where the drone really is, between the cells, when it estimates to be at pos.
+func (d *Drone) TruePositionOf(pos models.Position) models.Position {
+	return models.Position{
+		X: math.Max(0, math.Min(float64(d.MapWidth-1), pos.X+d.GPSError.X)),
+		Y: math.Max(0, math.Min(float64(d.MapHeight-1), pos.Y+d.GPSError.Y)),
+	}
+}
+
+// Airborne tells if the drone is flying: battery left, not crashed and not landed at a station.
+func (d *Drone) Airborne() bool {
+	return d.Battery > 0 && !d.Crashed && !d.Landed()
 }
 
 func (d *Drone) GetAllReachableDrones() []*Drone {
@@ -189,40 +271,43 @@ func (d *Drone) Think() models.Position {
 		return pos
 	}
 
-	switch d.ProtocolMode {
-	case 1:
-		return d.ThinkProtocol1()
-	case 2:
-		return d.ThinkProtocol2()
-	case 3:
-		d.DroneNetwork = d.GetDroneNetwork(d).Drones
-		if d.debug {
-			fmt.Printf("[DRONE %d] - Drone Network : %v\n", d.ID, d.DroneNetwork)
-		}
-		return d.ThinkProtocol3()
-	case 4:
-		// Récupérer le network pour le protocole 4
-		d.DroneNetwork = d.GetDroneNetwork(d).Drones
-		if d.debug {
-			fmt.Printf("[DRONE %d] - Drone Network : %v\n", d.ID, d.DroneNetwork)
-		}
-		return d.ThinkProtocol4()
-	default:
+	if d.Evacuation != nil {
+		return d.ThinkEvacuation()
+	}
+
+	if d.Search != nil {
+		return d.ThinkSearch()
+	}
+
+	if d.Protocol == nil {
 		fmt.Printf("[DRONE %d] - Protocole non défini\n", d.ID)
 		return d.randomMovement()
 	}
+	return d.Protocol.Think(d)
 }
 
 func (d *Drone) Myturn() {
+	d.ReadInbox()
+	if d.Crashed {
+		return
+	}
+	d.broadcastStatus()
+	d.updateBelief()
 
 	if d.tryCharging() {
+		d.Velocity = models.Position{}
 		return
 	}
 
-	target := d.Think()
+	d.reportCrowdRisk()
+	d.reportSightings()
+
+	// Toute cible est ramenée à ce que le drone peut voler en un tick
+	target := d.deconflict(d.fly(d.Think()))
 
 	if target.X == d.Position.X && target.Y == d.Position.Y {
-		d.Battery -= 0.25
+		d.Velocity = models.Position{}
+		d.drain(0)
 		return
 	}
 
@@ -232,12 +317,7 @@ func (d *Drone) Myturn() {
 	}
 }
 
-func (d *Drone) UpdateProtocole(newprot int) {
-	if newprot != 1 && newprot != 2 && newprot != 3 && newprot != 4 {
-		return
-	}
-	if d.ProtocolMode == newprot {
-		return
-	}
-	d.ProtocolMode = newprot
+// SetProtocol replaces the behaviour of the drone, Init is called by InitProtocol.
+func (d *Drone) SetProtocol(protocol Protocol) {
+	d.Protocol = protocol
 }
@@ -0,0 +1,96 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/models"
+	"fmt"
+	"strings"
+)
+
+// DroneRole tells the protocols what a drone is for.
+type DroneRole string
+
+const (
+	RolePatrol DroneRole = "patrol" // Sweeps its zone, the default
+	RoleRelay  DroneRole = "relay"  // Holds position in its zone to keep the network connected
+)
+
+// DroneType describes the hardware of a kind of drone. The types are read from configs/drones,
+// a missing field keeps the value of DefaultDroneType.
+type DroneType struct {
+	Name         string      `json:"name"`
+	Role         DroneRole   `json:"role"`
+	SeeRange     int         `json:"seeRange"`     // Cells seen around the drone
+	CommRange    int         `json:"commRange"`    // Cells reached by the radio
+	MaxSpeed     float64     `json:"maxSpeed"`     // m/s, the cruise speed of the trips, 0 for one cell per tick
+	Acceleration float64     `json:"acceleration"` // m/s², 0 to reach any speed within a tick
+	TurnRate     float64     `json:"turnRate"`     // °/s, 0 to turn at any speed
+	Sensor       string      `json:"sensor"`       // Sensor model of configs/sensors, empty for the camera of the simulation
+	PayloadKg    float64     `json:"payloadKg"`    // Heaviest kit the drone can carry
+	Energy       EnergyModel `json:"energy"`
+}
+
+// DefaultDroneType is the drone of the original fleet, all drones are of this type unless configured.
+var DefaultDroneType = DroneType{
+	Name:      "standard",
+	Role:      RolePatrol,
+	SeeRange:  4,
+	CommRange: 6,
+	PayloadKg: MEDICAL_GEAR_KG,
+	Energy:    DefaultEnergyModel,
+}
+
+// ApplyType gives the drone the ranges and the hardware of a type, the battery % is kept.
+func (d *Drone) ApplyType(droneType DroneType) {
+	d.Type = droneType
+	d.DroneSeeRange = droneType.SeeRange
+	d.DroneCommRange = droneType.CommRange
+	d.Energy = droneType.Energy
+}
+
+// IsRelay tells if the drone is a relay drone by type.
+func (d *Drone) IsRelay() bool {
+	return d.Type.Role == RoleRelay
+}
+
+// HoldsPosition tells if the drone holds position for the network instead of patrolling,
+// a relay drone or a drone given a relay post.
+func (d *Drone) HoldsPosition() bool {
+	return d.IsRelay() || d.RelayPost != nil
+}
+
+// CanCarry tells if the drone can lift the medical gear.
+func (d *Drone) CanCarry() bool {
+	return d.Type.PayloadKg >= MEDICAL_GEAR_KG
+}
+
+// holdPosition keeps the drone at its relay post, a relay drone without post at the centre of
+// its zone where it links the neighbouring zones.
+func (d *Drone) holdPosition() models.Position {
+	post := models.Position{
+		X: float64(int((d.MyWatch.CornerBottomLeft.X + d.MyWatch.CornerTopRight.X) / 2)),
+		Y: float64(int((d.MyWatch.CornerBottomLeft.Y + d.MyWatch.CornerTopRight.Y) / 2)),
+	}
+	if d.RelayPost != nil {
+		post = *d.RelayPost
+	}
+	if d.Position == post {
+		return d.Position
+	}
+	return d.nextStepToPos(post)
+}
+
+// ParseTypeMix reads a fleet composition, a comma-separated list of drone type names given
+// to the drones in turn ("scout,scout,relay" makes a third of relays).
+func ParseTypeMix(spec string) ([]string, error) {
+	names := make([]string, 0)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("empty drone type list")
+	}
+	return names, nil
+}
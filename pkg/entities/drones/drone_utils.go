@@ -1,6 +1,7 @@
 package drones
 
 import (
+	"UTC_IA04/pkg/entities/persons"
 	"UTC_IA04/pkg/models"
 	"math"
 	"math/rand"
@@ -79,13 +80,23 @@ func calculateDirectionScore(d *Drone, pos models.Position) float64 {
 	for _, person := range d.SeenPeople {
 		dist := pos.CalculateDistance(person.Position)
 		score += 1.0 / (dist + 1)
-		if person.InDistress {
+		if d.SawInDistress(person) {
 			score += 3.0
 		}
 	}
 	return score
 }
 
+// SawInDistress tells if the camera read the person in distress during this tick.
+func (d *Drone) SawInDistress(person *persons.Person) bool {
+	for _, seen := range d.SeenInDistress {
+		if seen.ID == person.ID {
+			return true
+		}
+	}
+	return false
+}
+
 func findBestDirection(scores map[models.Position]float64) (models.Position, float64) {
 	var bestDir models.Position
 	bestScore := -1.0
@@ -98,10 +109,13 @@ func findBestDirection(scores map[models.Position]float64) (models.Position, flo
 	return bestDir, bestScore
 }
 
+// nextStepToPos returns the position reached towards pos in a tick, in a straight line within
+// the speed, acceleration and turn rate of the drone.
 func (d *Drone) nextStepToPos(pos models.Position) models.Position {
-	return stepTowards(d.Position, pos)
+	return d.fly(pos)
 }
 
+// stepTowards moves the rescuers on the ground one cell per tick on each axis.
 func stepTowards(from models.Position, to models.Position) models.Position {
 	direction := models.Position{
 		X: to.X - from.X,
@@ -128,21 +142,6 @@ func stepTowards(from models.Position, to models.Position) models.Position {
 	return nextStep
 }
 
-func (d *Drone) closestPOI(poiType models.POIType) (models.Position, float64) {
-	pois := d.MapPoi[poiType]
-	minDistance := math.Inf(1)
-	var closestPOI models.Position
-
-	for _, poi := range pois {
-		distance := d.Position.CalculateManhattanDistance(poi)
-		if distance < minDistance {
-			minDistance = distance
-			closestPOI = poi
-		}
-	}
-	return closestPOI, minDistance
-}
-
 func (d *Drone) patrolMovementLogic() models.Position {
 	currentX := int(math.Round(d.Position.X))
 	currentY := int(math.Round(d.Position.Y))
@@ -151,9 +150,12 @@ func (d *Drone) patrolMovementLogic() models.Position {
 	minX := max(int(math.Round(d.MyWatch.CornerBottomLeft.X)), 0)
 	minY := max(int(math.Round(d.MyWatch.CornerBottomLeft.Y)), 0)
 
-	// Si on est hors limites, retourner au point de départ
+	// Si on est hors limites, rejoindre le point le plus proche de la zone
 	if currentX >= maxX || currentY >= maxY || currentX < minX || currentY < minY {
-		return d.nextStepToPos(models.Position{X: float64(minX), Y: float64(minY)})
+		return d.nextStepToPos(models.Position{
+			X: float64(max(minX, min(currentX, maxX-1))),
+			Y: float64(max(minY, min(currentY, maxY-1))),
+		})
 	}
 
 	if d.Position.CalculateDistance(models.Position{X: float64(minX), Y: float64(minY)}) < 1 {
@@ -191,3 +193,14 @@ func (d *Drone) patrolMovementLogic() models.Position {
 		return d.nextStepToPos(models.Position{X: float64(minX), Y: float64(minY)})
 	}
 }
+
+// AssignZone gives a new patrol zone to the drone. The sweep goes on from where the drone is,
+// a drone outside of its new zone joins it by the closest side.
+func (d *Drone) AssignZone(zone models.MyWatch) bool {
+	if d.MyWatch == zone {
+		return false
+	}
+	d.MyWatch = zone
+	d.Memory.ReturningToStart = false
+	return true
+}
@@ -0,0 +1,136 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/models"
+	"math"
+)
+
+const (
+	AIR_DENSITY     = 1.225 // kg/m³ at sea level
+	GRAVITY         = 9.81  // m/s²
+	MEDICAL_GEAR_KG = 0.8   // Payload of a drone carrying medical gear
+)
+
+// EnergyModel describes the hardware of a drone. Battery stays a percentage of CapacityWh.
+type EnergyModel struct {
+	CapacityWh       float64 `json:"capacityWh"`
+	MassKg           float64 `json:"massKg"`      // Frame and battery, without payload
+	RotorAreaM2      float64 `json:"rotorAreaM2"` // Disk area of all the rotors
+	DragAreaM2       float64 `json:"dragAreaM2"`  // Drag coefficient times frontal area
+	Efficiency       float64 `json:"efficiency"`  // Electrical to rotor power (motors, ESC, propellers)
+	ChargerPowerW    float64 `json:"chargerPowerW"`
+	ChargeEfficiency float64 `json:"chargeEfficiency"`
+	FastChargeLimit  float64 `json:"fastChargeLimit"` // Battery % up to which the charger delivers its full power
+	ReservePercent   float64 `json:"reservePercent"`  // Kept on top of the trip to the closest charging station
+}
+
+// DefaultEnergyModel is an inspection quadcopter of about 4 kg with two 130 Wh batteries,
+// around 45 minutes of hover.
+var DefaultEnergyModel = EnergyModel{
+	CapacityWh:       263,
+	MassKg:           3.77,
+	RotorAreaM2:      0.58,
+	DragAreaM2:       0.1,
+	Efficiency:       0.6,
+	ChargerPowerW:    450,
+	ChargeEfficiency: 0.9,
+	FastChargeLimit:  80,
+	ReservePercent:   10,
+}
+
+// Power returns the electrical power (W) drawn at a ground speed (m/s) in a given wind (m/s).
+// Induced power comes from the momentum theory of the rotors, it drops with the airspeed
+// while the parasite drag of the frame grows with its cube.
+func (e EnergyModel) Power(speed, wind, payloadKg float64) float64 {
+	thrust := (e.MassKg + payloadKg) * GRAVITY
+	// La direction du vent n'est pas modélisée : vent de travers en moyenne
+	airspeed := math.Hypot(speed, wind)
+	hoverInduced := math.Sqrt(thrust / (2 * AIR_DENSITY * e.RotorAreaM2))
+	induced := hoverInduced * hoverInduced / math.Sqrt(airspeed*airspeed+hoverInduced*hoverInduced)
+	parasite := 0.5 * AIR_DENSITY * e.DragAreaM2 * math.Pow(airspeed, 3)
+	return (thrust*induced + parasite) / e.Efficiency
+}
+
+// ChargePower returns the power (W) stored in the battery: constant current up to
+// FastChargeLimit, then the charger tapers down to a tenth of its power at 100%.
+func (e EnergyModel) ChargePower(battery float64) float64 {
+	power := e.ChargerPowerW * e.ChargeEfficiency
+	if battery < e.FastChargeLimit {
+		return power
+	}
+	return power * math.Max(0.1, (100-battery)/(100-e.FastChargeLimit))
+}
+
+// percentPerTick turns a power (W) held during a tick of tickSeconds into a battery percentage.
+func (e EnergyModel) percentPerTick(power, tickSeconds float64) float64 {
+	return power * tickSeconds / 3600 / e.CapacityWh * 100
+}
+
+func (d *Drone) payloadKg() float64 {
+	if d.HasMedicalGear {
+		return MEDICAL_GEAR_KG
+	}
+	return 0
+}
+
+// flightDrain returns the battery % used to fly a given distance (cells) in one tick, 0 to hover.
+func (d *Drone) flightDrain(cells float64) float64 {
+	speed := cells * models.METERS_PER_UNIT / d.tickSeconds()
+	return d.Energy.percentPerTick(d.Energy.Power(speed, d.weather().Wind, d.payloadKg()), d.tickSeconds())
+}
+
+// drain consumes the battery for a tick, the drone flew the given distance in cells.
+func (d *Drone) drain(cells float64) {
+	d.Battery = math.Max(0, d.Battery-d.flightDrain(cells))
+}
+
+// ChargeStep returns the battery % after a tick of tickSeconds on a charger.
+func (e EnergyModel) ChargeStep(battery, tickSeconds float64) float64 {
+	return math.Min(100, battery+e.percentPerTick(e.ChargePower(battery), tickSeconds))
+}
+
+// charge adds the energy of a tick at the charging station.
+func (d *Drone) charge() {
+	d.Battery = d.Energy.ChargeStep(d.Battery, d.tickSeconds())
+}
+
+// tripBattery returns the battery % needed to fly to pos at the current wind, in a straight
+// line at the max speed like nextStepToPos.
+func (d *Drone) tripBattery(pos models.Position) float64 {
+	step := d.maxStep()
+	return d.Position.CalculateDistance(pos) / step * d.flightDrain(step)
+}
+
+// closestChargingStation returns the charging station reached with the least battery,
+// and the battery % needed to get there.
+func (d *Drone) closestChargingStation() (models.Position, float64) {
+	var closest models.Position
+	needed := math.Inf(1)
+	for _, station := range d.MapPoi[models.ChargingStation] {
+		if trip := d.tripBattery(station); trip < needed {
+			closest, needed = station, trip
+		}
+	}
+	return closest, needed
+}
+
+// Endurance returns the ticks the drone can still patrol before leaving for a charging station.
+func (d *Drone) Endurance() float64 {
+	return math.Max(0, d.Battery-d.chargingReserve()) / d.flightDrain(d.maxStep())
+}
+
+// ChargingCycle estimates the ticks off patrol for a charge started at the reserve: the trip
+// to the closest station and back, and the time on the pad from ReservePercent.
+func (d *Drone) ChargingCycle() int {
+	station, reserve := d.closestChargingStation()
+	if math.IsInf(reserve, 1) {
+		return 0
+	}
+	return 2*d.flightTicks(d.Position, station) + d.chargeTicks(d.Energy.ReservePercent)
+}
+
+// chargingReserve returns the battery % under which the drone must leave for a charging station.
+func (d *Drone) chargingReserve() float64 {
+	_, needed := d.closestChargingStation()
+	return needed + d.Energy.ReservePercent + d.avoidanceReserve()
+}
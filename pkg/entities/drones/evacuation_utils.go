@@ -0,0 +1,46 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/models"
+	"math"
+)
+
+// IsGuiding tells if the drone guides the crowd during an evacuation,
+// the other drones monitor an emergency exit.
+func (d *Drone) IsGuiding() bool {
+	return d.Evacuation != nil && (len(d.Evacuation.Exits) == 0 || d.ID%2 == 0)
+}
+
+// ThinkEvacuation replaces the protocol during an evacuation.
+func (d *Drone) ThinkEvacuation() models.Position {
+	if !d.IsGuiding() {
+		exit := d.Evacuation.Exits[d.ID%len(d.Evacuation.Exits)]
+		// Se placer juste avant la sortie pour surveiller la file
+		watchPos := models.Position{X: math.Max(0, exit.Position.X-1), Y: exit.Position.Y}
+		if d.Position.CalculateDistance(watchPos) < 1 {
+			return d.Position
+		}
+		return d.nextStepToPos(watchPos)
+	}
+
+	// Guider le groupe le plus proche encore sur le site
+	var sumX, sumY float64
+	count := 0
+	for _, p := range d.SeenPeople {
+		if !p.StillInSim || p.IsDead() || !p.SeekingExit {
+			continue
+		}
+		sumX += p.Position.X
+		sumY += p.Position.Y
+		count++
+	}
+	if count == 0 {
+		return d.patrolMovementLogic()
+	}
+
+	target := models.Position{X: math.Round(sumX / float64(count)), Y: math.Round(sumY / float64(count))}
+	if d.Evacuation.Hazard.Contains(target) || d.Position.CalculateDistance(target) < 1 {
+		return d.Position
+	}
+	return d.nextStepToPos(target)
+}
@@ -12,4 +12,22 @@ type DroneMemory struct {
 	Persons           struct {
 		PersonsToSave sync.Map
 	}
+	Handovers map[int]PendingHandover // Waiting for an ack, by Seq
+	NextSeq   int
+	Peers     map[int]PeerStatus // Last heartbeat and beacon of the neighbours, by drone ID
+}
+
+// PendingHandover is a handover or a report sent and not acknowledged yet, its people are kept
+// until the ack and handed over again when it expires.
+type PendingHandover struct {
+	To        int // Drone or rescue point ID
+	PersonIDs []int
+	SentTick  int
+}
+
+type PeerStatus struct {
+	Position      models.Position
+	Battery       float64
+	LastBeacon    int
+	LastHeartbeat int
 }
@@ -0,0 +1,99 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/models"
+	"math"
+)
+
+const (
+	FLIGHT_STEP_SECONDS = 1.0  // Longest step of the integration of the flight within a tick
+	ARRIVAL_EPSILON     = 1e-9 // Rounding margin of the continuous positions
+)
+
+// tickSeconds returns the seconds simulated by a tick, models.SECONDS_PER_TICK if the simulation gave none.
+func (d *Drone) tickSeconds() float64 {
+	if d.TickSeconds <= 0 {
+		return models.SECONDS_PER_TICK
+	}
+	return d.TickSeconds
+}
+
+// defaultMaxSpeed returns the speed (m/s) of one cell per tick, the speed of a drone type without maxSpeed.
+func (d *Drone) defaultMaxSpeed() float64 {
+	return models.METERS_PER_UNIT / d.tickSeconds()
+}
+
+// maxSpeed returns the cruise speed of the drone in cells per second.
+func (d *Drone) maxSpeed() float64 {
+	speed := d.Type.MaxSpeed
+	if speed <= 0 {
+		speed = d.defaultMaxSpeed()
+	}
+	return speed / models.METERS_PER_UNIT
+}
+
+// maxStep returns the distance (cells) flown in a tick at the max speed of the drone.
+func (d *Drone) maxStep() float64 {
+	return d.maxSpeed() * d.tickSeconds()
+}
+
+// acceleration returns the speed (cells per second) gained or lost in a second, unlimited without acceleration.
+func (d *Drone) acceleration() float64 {
+	if d.Type.Acceleration <= 0 {
+		return math.Inf(1)
+	}
+	return d.Type.Acceleration / models.METERS_PER_UNIT
+}
+
+// turnRate returns the heading change (radians) allowed in a second, unlimited without turn rate.
+func (d *Drone) turnRate() float64 {
+	if d.Type.TurnRate <= 0 {
+		return math.Inf(1)
+	}
+	return d.Type.TurnRate * math.Pi / 180
+}
+
+// fly returns the position reached towards target at the end of the tick, from the velocity of
+// the last tick. The tick is flown in steps of at most FLIGHT_STEP_SECONDS so that the acceleration
+// and the turn rate hold in real time whatever the length of the tick.
+func (d *Drone) fly(target models.Position) models.Position {
+	tick := d.tickSeconds()
+	steps := math.Ceil(tick / FLIGHT_STEP_SECONDS)
+	dt := tick / steps
+	position := d.Position
+	velocity := models.Position{X: d.Velocity.X / tick, Y: d.Velocity.Y / tick}
+	for i := 0; i < int(steps) && position != target; i++ {
+		position, velocity = d.flyStep(position, velocity, target, dt)
+	}
+	return position
+}
+
+// flyStep returns the position and the velocity (cells per second) after dt seconds of flight
+// towards target. The drone speeds up by its acceleration up to its max speed and stops on the
+// target once within reach. A turn sharper than its turn rate makes it turn at the max rate
+// while braking, a stopped drone turns on the spot.
+func (d *Drone) flyStep(position, velocity, target models.Position, dt float64) (models.Position, models.Position) {
+	dx, dy := target.X-position.X, target.Y-position.Y
+	dist := math.Hypot(dx, dy)
+	heading := math.Atan2(dy, dx)
+
+	speed := math.Hypot(velocity.X, velocity.Y)
+	if speed > 0 {
+		current := math.Atan2(velocity.Y, velocity.X)
+		turn := math.Remainder(heading-current, 2*math.Pi)
+		if maxTurn := d.turnRate() * dt; math.Abs(turn) > maxTurn+ARRIVAL_EPSILON {
+			// Virage trop serré : tourner au maximum en freinant
+			heading = current + math.Copysign(maxTurn, turn)
+			speed = math.Max(0, speed-d.acceleration()*dt)
+			velocity = models.Position{X: speed * math.Cos(heading), Y: speed * math.Sin(heading)}
+			return models.Position{X: position.X + velocity.X*dt, Y: position.Y + velocity.Y*dt}, velocity
+		}
+	}
+
+	speed = math.Min(d.maxSpeed(), speed+d.acceleration()*dt)
+	if dist <= speed*dt+ARRIVAL_EPSILON {
+		return target, models.Position{}
+	}
+	velocity = models.Position{X: dx / dist * speed, Y: dy / dist * speed}
+	return models.Position{X: position.X + velocity.X*dt, Y: position.Y + velocity.Y*dt}, velocity
+}
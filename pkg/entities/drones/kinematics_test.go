@@ -0,0 +1,44 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/models"
+	"math"
+	"testing"
+)
+
+func TestFlyFollowsTheDroneDynamics(t *testing.T) {
+	scout := DroneType{MaxSpeed: 15, Acceleration: 6, TurnRate: 180}
+	carrier := DroneType{MaxSpeed: 8, Acceleration: 2, TurnRate: 45}
+	unlimited := func(droneType DroneType) DroneType {
+		droneType.Acceleration, droneType.TurnRate = 0, 0
+		return droneType
+	}
+
+	cases := []struct {
+		name        string
+		tickSeconds float64
+		droneType   DroneType
+		velocity    models.Position // Cells flown during the last tick
+		target      models.Position
+		want        models.Position
+	}{
+		// Une case par tick sans type, quelle que soit la durée du tick
+		{"standard drone", models.SECONDS_PER_TICK, DefaultDroneType, models.Position{}, models.Position{X: 5}, models.Position{X: 1}},
+		{"standard drone, short tick", 5, DefaultDroneType, models.Position{}, models.Position{X: 5}, models.Position{X: 1}},
+		// 6, 12 puis 15 m/s : 63 m en 5 s au lieu de 75 m
+		{"scout taking off", 5, scout, models.Position{}, models.Position{X: 100}, models.Position{X: 31.5}},
+		{"scout taking off, no acceleration", 5, unlimited(scout), models.Position{}, models.Position{X: 100}, models.Position{X: 37.5}},
+		// Demi-tour à 8 m/s : le drone vire de 45° et freine de 2 m/s chaque seconde au lieu de repartir en ligne droite
+		{"carrier turning back", 2, carrier, models.Position{X: 8}, models.Position{X: -100}, models.Position{X: 2.121, Y: 4.121}},
+		{"carrier turning back, no turn rate", 2, unlimited(carrier), models.Position{X: 8}, models.Position{X: -100}, models.Position{X: -8}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := &Drone{Type: c.droneType, Velocity: c.velocity, TickSeconds: c.tickSeconds}
+			got := d.fly(c.target)
+			if math.Abs(got.X-c.want.X) > 1e-3 || math.Abs(got.Y-c.want.Y) > 1e-3 {
+				t.Fatalf("fly(%v) = %v, want %v", c.target, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/models"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Protocol is a drone behaviour. Each drone gets its own instance, so a protocol can keep
+// per-drone state. Protocols register themselves by name with RegisterProtocol, usually
+// from an init function of their package.
+type Protocol interface {
+	Name() string
+	// Init is called once the fleet is created, before the first tick.
+	Init(d *Drone)
+	// Think returns the next position of the drone, battery, evacuation and search
+	// missions are handled before the protocol is asked.
+	Think(d *Drone) models.Position
+	// OnMessage is called when d reads a message from its inbox, after the drone handled
+	// the handovers, acks, heartbeats and beacons itself.
+	OnMessage(d *Drone, msg bus.Message)
+}
+
+// Avoider is implemented by the protocols with their own avoidance manoeuvre, the others turn right.
+// Avoid returns the position to fly to instead of target, which brings the drone too close to the intruders.
+type Avoider interface {
+	Avoid(d *Drone, target models.Position, intruders []*Drone) models.Position
+}
+
+type ProtocolFactory func() Protocol
+
+var (
+	protocolsMu sync.RWMutex
+	protocols   = make(map[string]ProtocolFactory)
+)
+
+// RegisterProtocol makes a protocol available by name, registering the same name twice panics.
+func RegisterProtocol(name string, factory ProtocolFactory) {
+	protocolsMu.Lock()
+	defer protocolsMu.Unlock()
+	if _, exists := protocols[name]; exists {
+		panic(fmt.Sprintf("drone protocol %q registered twice", name))
+	}
+	protocols[name] = factory
+}
+
+// NewProtocol returns a new instance of the protocol registered under name.
+func NewProtocol(name string) (Protocol, error) {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+	factory, exists := protocols[strings.TrimSpace(name)]
+	if !exists {
+		return nil, fmt.Errorf("unknown drone protocol %q (available: %s)", name, strings.Join(protocolNames(), ", "))
+	}
+	return factory(), nil
+}
+
+// ProtocolNames returns the registered protocol names, built-in protocols first.
+func ProtocolNames() []string {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+	return protocolNames()
+}
+
+func protocolNames() []string {
+	names := make([]string, 0, len(protocols))
+	for _, name := range BuiltinProtocols {
+		if _, exists := protocols[name]; exists {
+			names = append(names, name)
+		}
+	}
+	others := make([]string, 0)
+	for name := range protocols {
+		if !isBuiltinProtocol(name) {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+func isBuiltinProtocol(name string) bool {
+	for _, builtin := range BuiltinProtocols {
+		if builtin == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProtocolMix reads a fleet composition, a comma-separated list of protocol names
+// given to the drones in turn ("multi-hop,basic" alternates both protocols).
+func ParseProtocolMix(spec string) ([]string, error) {
+	names := make([]string, 0)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, err := NewProtocol(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("empty drone protocol list")
+	}
+	return names, nil
+}
+
+// Transmit sends a message to another drone over the radio, it may arrive late or never.
+func (d *Drone) Transmit(to *Drone, msg bus.Message) {
+	if msg.Tick == 0 {
+		msg.Tick = d.currentTick()
+	}
+	if d.RadioSend == nil {
+		d.Send(to, msg)
+		return
+	}
+	d.RadioSend(d, to, msg)
+}
+
+// Send puts a message in the inbox of a drone or a rescue point at once.
+func (d *Drone) Send(to bus.Endpoint, msg bus.Message) {
+	msg.From = d.BusAddress()
+	msg.Sender = d
+	if msg.Tick == 0 {
+		msg.Tick = d.currentTick()
+	}
+	to.Deliver(msg)
+}
+
+// ProtocolName returns the name of the protocol of the drone, empty if it has none.
+func (d *Drone) ProtocolName() string {
+	if d.Protocol == nil {
+		return ""
+	}
+	return d.Protocol.Name()
+}
+
+// Helpers for the protocols defined outside of this package.
+
+// StepTowards returns the next cell on the way to pos.
+func (d *Drone) StepTowards(pos models.Position) models.Position {
+	return d.nextStepToPos(pos)
+}
+
+// Patrol sweeps the zone watched by the drone column by column.
+func (d *Drone) Patrol() models.Position {
+	return d.patrolMovementLogic()
+}
+
+// PlanPatrol flies to the waypoint of the belief map with the most expected detections,
+// it sweeps the zone when the drone has no belief map.
+func (d *Drone) PlanPatrol() models.Position {
+	return d.planPatrol()
+}
+
+// Wander moves the drone randomly, towards the people it sees if any.
+func (d *Drone) Wander() models.Position {
+	return d.randomMovement()
+}
+
+// HoldPosition keeps the drone at its relay post, a relay drone without post at the centre of its zone.
+func (d *Drone) HoldPosition() models.Position {
+	return d.holdPosition()
+}
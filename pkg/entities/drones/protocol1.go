@@ -1,67 +0,0 @@
-package drones
-
-import (
-	"UTC_IA04/pkg/entities/persons"
-	"UTC_IA04/pkg/entities/rescue"
-	"UTC_IA04/pkg/models"
-	"fmt"
-)
-
-func (d *Drone) initProtocol1() {
-	fmt.Printf("[DRONES] - Succeffuly terminated Protocole 1 init.\n")
-}
-
-
-func (d *Drone) ThinkProtocol1() models.Position {
-	if d.IsCharging {
-		// Drone AFK quand il charge car il est docké.
-		return d.Position
-	}
-
-	for _, person := range d.SeenPeople {
-		if person.IsInDistress() {
-			d.Memory.Persons.PersonsToSave.Store(person.ID, person)
-		}
-	}
-
-	isEmpty := true
-	d.Memory.Persons.PersonsToSave.Range(func(_, _ interface{}) bool {
-		isEmpty = false
-		return false
-	})
-
-	if isEmpty {
-		// Je patrouille
-		return d.randomMovement()
-	}
-
-	if rp := d.GetRescuePoint(d.Position); rp != nil {
-		canCommunicate := rp.Position.CalculateDistance(d.Position) <= float64(d.DroneCommRange)
-		if canCommunicate {
-			d.Memory.Persons.PersonsToSave.Range(func(key, value interface{}) bool {
-				person := value.(*persons.Person)
-				respChan := make(chan rescue.RescueResponse)
-				rp.RequestChan <- rescue.RescueRequest{
-					PersonID:      person.ID,
-					Position:      person.Position,
-					DroneSenderID: d.ID,
-					ResponseChan:  respChan,
-				}
-				response := <-respChan
-				if response.Accepted {
-					d.Memory.Persons.PersonsToSave.Delete(person.ID)
-				}
-				return true
-			})
-		}
-
-		if !canCommunicate {
-			return d.nextStepToPos(rp.Position)
-		}
-
-	}
-	if d.debug {
-		fmt.Printf("[DRONE-WARNING] - Cannot find any RP. Is your Map Config correct?")
-	}
-	return d.randomMovement()
-}
@@ -0,0 +1,67 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+const SEARCH_PATTERN_LEGS = 12
+
+// SearchAssignment is the expanding square flown by a drone dedicated to a search mission.
+type SearchAssignment struct {
+	MissionID int
+	Waypoints []models.Position
+	Next      int
+}
+
+// AssignSearch dedicates the drone to a mission, rotation shifts the pattern between the drones of a same mission.
+func (d *Drone) AssignSearch(mission models.SearchMission, rotation int) {
+	d.Search = &SearchAssignment{
+		MissionID: mission.ID,
+		Waypoints: models.ExpandingSquare(mission.LastSeen, float64(d.DroneSeeRange), SEARCH_PATTERN_LEGS, rotation, d.MapWidth, d.MapHeight),
+	}
+	if d.debug {
+		fmt.Printf("[DRONE %d] - Assigned to search mission %d\n", d.ID, mission.ID)
+	}
+}
+
+// ThinkSearch flies the next leg of the expanding square, the assignment ends with the pattern.
+func (d *Drone) ThinkSearch() models.Position {
+	for d.Search.Next < len(d.Search.Waypoints) && d.Position.CalculateDistance(d.Search.Waypoints[d.Search.Next]) < 1 {
+		d.Search.Next++
+	}
+	if d.Search.Next >= len(d.Search.Waypoints) {
+		d.Search = nil
+		return d.patrolMovementLogic()
+	}
+	return d.nextStepToPos(d.Search.Waypoints[d.Search.Next])
+}
+
+// reportSightings sends to the nearest rescue point the people matching a broadcast description.
+func (d *Drone) reportSightings() {
+	if len(d.SearchMissions) == 0 || d.GetRescuePoint == nil {
+		return
+	}
+	rp := d.GetRescuePoint(d.Position)
+	if rp == nil {
+		return
+	}
+	for _, mission := range d.SearchMissions {
+		for _, p := range d.SeenPeople {
+			if p.Appearance != mission.Description || !p.StillInSim || p.IsDead() {
+				continue
+			}
+			sighting := models.Sighting{DroneID: d.ID, PersonID: p.ID, Position: p.Position, Tick: d.currentTick()}
+			if rp.ReportSighting(mission.ID, sighting) && d.Search != nil && d.Search.MissionID == mission.ID {
+				d.Search = nil
+			}
+		}
+	}
+}
+
+func (d *Drone) currentTick() int {
+	if d.GetTick == nil {
+		return 0
+	}
+	return d.GetTick()
+}
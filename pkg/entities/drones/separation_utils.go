@@ -0,0 +1,173 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/models"
+	"math"
+)
+
+// SeparationRule keeps the drones of the same altitude layer apart, the simulation refuses the moves breaking it.
+type SeparationRule struct {
+	MinDistance float64 // Cells between two drones of the same layer, 0 to let them share a cell
+	Layers      int     // Altitude layers, 0 or 1 keeps the whole fleet on one layer
+}
+
+func (r SeparationRule) Enabled() bool {
+	return r.MinDistance > 0
+}
+
+// HomeLayer returns the layer a drone flies on when it does not avoid anyone, the fleet is spread over the layers.
+func (r SeparationRule) HomeLayer(droneID int) int {
+	if r.Layers <= 1 {
+		return 0
+	}
+	return droneID % r.Layers
+}
+
+// Breaks tells if flying from one position to another brings a drone too close to a drone at other,
+// moving away from a drone already too close is allowed.
+func (r SeparationRule) Breaks(from, to, other models.Position) bool {
+	dist := to.CalculateDistance(other)
+	return dist < r.MinDistance && dist < from.CalculateDistance(other)
+}
+
+// FINAL_APPROACH_FACTOR sets the radius around its charging station, in separation distances, where a
+// drone lands in the sequence of the station: the pads and the queue keep the drones apart, not the rule.
+const FINAL_APPROACH_FACTOR = 2.0
+
+// OnFinalApproach tells if the drone is about to land at its charging station, the separation no longer holds.
+func (d *Drone) OnFinalApproach() bool {
+	return d.ChargingTarget != nil &&
+		d.Position.CalculateDistance(*d.ChargingTarget) <= d.Separation.MinDistance*FINAL_APPROACH_FACTOR
+}
+
+// AVOIDANCE_RESERVE_TICKS are the ticks of hover kept in the charging reserve under the separation rule,
+// for the detours and holds on the way to the station.
+const AVOIDANCE_RESERVE_TICKS = 8
+
+func (d *Drone) avoidanceReserve() float64 {
+	if !d.Separation.Enabled() {
+		return 0
+	}
+	return AVOIDANCE_RESERVE_TICKS * d.flightDrain(0)
+}
+
+// AvoidanceCounters counts the manoeuvres of a drone to keep the separation, the simulation sums them.
+type AvoidanceCounters struct {
+	Manoeuvres int // Targets changed because of an intruder
+	Holds      int // Ticks held in place to give way
+	Climbs     int // Changes of altitude layer
+}
+
+// AVOIDANCE_TURNS are the heading changes tried by TurnRight, in radians to the right of the course
+// (clockwise on the map, the Y axis points down).
+var AVOIDANCE_TURNS = []float64{math.Pi / 4, math.Pi / 2, 3 * math.Pi / 4}
+
+// peerFlight is a neighbour as the drone sees it at the start of the tick, from its beacon.
+type peerFlight struct {
+	Position models.Position // Estimated position broadcast by the neighbour
+	Layer    int
+	Flying   bool // Airborne and not landing at its charging station
+	Moving   bool
+}
+
+// PeerMoving tells if the neighbour was moving at the start of the tick.
+func (d *Drone) PeerMoving(peer *Drone) bool {
+	return d.peerFlights[peer.ID].Moving
+}
+
+// Intruders returns the neighbours of the drone the move to target would bring too close, on the layer.
+// The drone compares the estimated positions broadcast at the start of the tick, the simulation refuses
+// a move still breaking the rule between the true positions.
+func (d *Drone) Intruders(target models.Position, layer int) []*Drone {
+	intruders := make([]*Drone, 0)
+	if !d.Separation.Enabled() || d.OnFinalApproach() {
+		return intruders
+	}
+	for _, peer := range d.DroneInComRange {
+		flight, known := d.peerFlights[peer.ID]
+		if !known || flight.Layer != layer || !flight.Flying {
+			continue
+		}
+		if d.Separation.Breaks(d.Position, target, flight.Position) {
+			intruders = append(intruders, peer)
+		}
+	}
+	return intruders
+}
+
+// deconflict changes the target of the tick when it brings the drone too close to a neighbour, with the
+// manoeuvre of the protocol or by turning right. A drone off its home layer asks to go back once the layer
+// is clear, the layer changes with the move.
+func (d *Drone) deconflict(target models.Position) models.Position {
+	d.nextLayer = d.Layer
+	if !d.Separation.Enabled() || target == d.Position {
+		return target
+	}
+	if home := d.Separation.HomeLayer(d.ID); d.Layer != home && len(d.Intruders(target, home)) == 0 {
+		d.nextLayer = home
+	}
+	intruders := d.Intruders(target, d.nextLayer)
+	if len(intruders) == 0 {
+		return target
+	}
+	d.Avoidance.Manoeuvres++
+	if avoider, ok := d.Protocol.(Avoider); ok {
+		return d.fly(avoider.Avoid(d, target, intruders))
+	}
+	return d.fly(d.TurnRight(target, intruders))
+}
+
+// TurnRight is the right-of-way rule of the aviation: the drone turns right of its course until the
+// intruders are clear, two drones head-on both turn right and pass each other. It holds if no turn is clear.
+func (d *Drone) TurnRight(target models.Position, intruders []*Drone) models.Position {
+	dx, dy := target.X-d.Position.X, target.Y-d.Position.Y
+	step := math.Hypot(dx, dy)
+	course := math.Atan2(dy, dx)
+	for _, turn := range AVOIDANCE_TURNS {
+		candidate := models.Position{
+			X: d.Position.X + step*math.Cos(course+turn),
+			Y: d.Position.Y + step*math.Sin(course+turn),
+		}
+		if d.onMap(candidate) && d.clearOf(candidate, intruders) {
+			return candidate
+		}
+	}
+	return d.GiveWay()
+}
+
+// GiveWay holds the drone in place for the tick, the intruders go first.
+func (d *Drone) GiveWay() models.Position {
+	d.Avoidance.Holds++
+	return d.Position
+}
+
+// Climb asks to fly the move of the tick on the closest altitude layer clear around target, it keeps
+// its course. It tells false when the rule has a single layer or all the layers are busy.
+func (d *Drone) Climb(target models.Position) bool {
+	for offset := 1; offset < d.Separation.Layers; offset++ {
+		for _, layer := range []int{d.nextLayer + offset, d.nextLayer - offset} {
+			if layer < 0 || layer >= d.Separation.Layers {
+				continue
+			}
+			if len(d.Intruders(target, layer)) == 0 {
+				d.nextLayer = layer
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *Drone) onMap(pos models.Position) bool {
+	cell := pos.Cell()
+	return cell.X >= 0 && cell.Y >= 0 && cell.X < float64(d.MapWidth) && cell.Y < float64(d.MapHeight)
+}
+
+func (d *Drone) clearOf(pos models.Position, intruders []*Drone) bool {
+	for _, intruder := range intruders {
+		if d.Separation.Breaks(d.Position, pos, d.peerFlights[intruder.ID].Position) {
+			return false
+		}
+	}
+	return true
+}
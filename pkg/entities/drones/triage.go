@@ -0,0 +1,44 @@
+package drones
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"math/rand"
+)
+
+// SEVERITY_ESTIMATION_ERROR is the probability that a drone misjudges
+// the severity of an incident by one level when it reports it.
+const SEVERITY_ESTIMATION_ERROR = 0.25
+
+// EstimateSeverity returns the severity the drone perceives for a person in distress.
+func (d *Drone) EstimateSeverity(person *persons.Person) models.Severity {
+	severity := person.GetSeverity()
+	if severity == models.SeverityNone || rand.Float64() >= SEVERITY_ESTIMATION_ERROR {
+		return severity
+	}
+
+	if rand.Float64() < 0.5 {
+		severity--
+	} else {
+		severity++
+	}
+	return min(max(severity, models.SeverityLow), models.SeverityCritical)
+}
+
+// DISTRESS_TYPE_ESTIMATION_ERROR is the probability that a drone mistakes the type of an incident
+// it reports: the rescuers then leave with the equipment of another type.
+const DISTRESS_TYPE_ESTIMATION_ERROR = 0.2
+
+// EstimateDistressType returns the incident type the drone perceives for a person in distress.
+func (d *Drone) EstimateDistressType(person *persons.Person) models.DistressType {
+	if person.DistressType == models.NoDistress || rand.Float64() >= DISTRESS_TYPE_ESTIMATION_ERROR {
+		return person.DistressType
+	}
+
+	// Un autre type, les plus fréquents étant les plus souvent confondus
+	for {
+		if distressType := models.RandomDistressType(nil); distressType != person.DistressType {
+			return distressType
+		}
+	}
+}
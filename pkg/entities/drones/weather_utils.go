@@ -0,0 +1,17 @@
+package drones
+
+import "UTC_IA04/pkg/models"
+
+// DEFAULT_MAX_WIND_SPEED is the wind (m/s) above which a drone must land at a charging station.
+const DEFAULT_MAX_WIND_SPEED = 12.0
+
+func (d *Drone) weather() models.WeatherConditions {
+	if d.GetWeather == nil {
+		return models.DefaultWeather
+	}
+	return d.GetWeather()
+}
+
+func (d *Drone) windTooStrong() bool {
+	return d.weather().Wind > d.MaxWindSpeed
+}
@@ -7,7 +7,14 @@ import (
 	"UTC_IA04/pkg/models"
 	"math"
 	"math/rand"
-	"time"
+)
+
+// Durées en ticks de simulation (1 tick = 1 minute)
+const (
+	BASE_EXIT_TICKS      = 180 // Default stay before heading to the exit
+	ENTRANCE_ZONE_TICKS  = 15  // Time after which people leave the entrance zone
+	EXIT_ZONE_LEAD_TICKS = 30  // People walk to the exit zone this long before leaving
+	POI_REVISIT_TICKS    = 120 // Time after which the wish to revisit a POI is doubled
 )
 
 type MovementPattern int
@@ -25,24 +32,24 @@ type ZonePreference struct {
 	MainZoneWeight     float64
 	ExitZoneWeight     float64
 	POIPreferences     map[models.POIType]float64
-	ExitTime           time.Duration
-	LastPOIVisit       map[models.POIType]time.Time
+	ExitTime           int                    // Ticks spent in the festival before leaving
+	LastPOIVisit       map[models.POIType]int // Tick of the last visit
 }
 
 func GetZonePreference(pattern MovementPattern) ZonePreference {
 	pref := ZonePreference{
 		POIPreferences: make(map[models.POIType]float64),
-		LastPOIVisit:   make(map[models.POIType]time.Time),
+		LastPOIVisit:   make(map[models.POIType]int),
 	}
 
-	baseExitTime := 3 * time.Hour
+	baseExitTime := BASE_EXIT_TICKS
 
 	switch pattern {
 	case MainEventFocused:
 		pref.EntranceZoneWeight = 0.1
 		pref.MainZoneWeight = 0.8
 		pref.ExitZoneWeight = 0.1
-		pref.ExitTime = baseExitTime + time.Duration(rand.Intn(60))*time.Minute
+		pref.ExitTime = baseExitTime + rand.Intn(60)
 		pref.POIPreferences[models.MainStage] = 0.9
 		pref.POIPreferences[models.SecondaryStage] = 0.7
 		pref.POIPreferences[models.FoodStand] = 0.4
@@ -52,7 +59,7 @@ func GetZonePreference(pattern MovementPattern) ZonePreference {
 		pref.EntranceZoneWeight = 0.3
 		pref.MainZoneWeight = 0.4
 		pref.ExitZoneWeight = 0.3
-		pref.ExitTime = baseExitTime + time.Duration(rand.Intn(120))*time.Minute
+		pref.ExitTime = baseExitTime + rand.Intn(120)
 		pref.POIPreferences[models.MainStage] = 0.7
 		pref.POIPreferences[models.SecondaryStage] = 0.7
 		pref.POIPreferences[models.FoodStand] = 0.7
@@ -63,7 +70,7 @@ func GetZonePreference(pattern MovementPattern) ZonePreference {
 		pref.EntranceZoneWeight = 0.2
 		pref.MainZoneWeight = 0.3
 		pref.ExitZoneWeight = 0.5
-		pref.ExitTime = baseExitTime - time.Duration(rand.Intn(90))*time.Minute
+		pref.ExitTime = baseExitTime - rand.Intn(90)
 		pref.POIPreferences[models.MainStage] = 0.3
 		pref.POIPreferences[models.SecondaryStage] = 0.3
 		pref.POIPreferences[models.FoodStand] = 0.4
@@ -72,7 +79,7 @@ func GetZonePreference(pattern MovementPattern) ZonePreference {
 		pref.EntranceZoneWeight = 0.6
 		pref.MainZoneWeight = 0.3
 		pref.ExitZoneWeight = 0.1
-		pref.ExitTime = baseExitTime + time.Duration(rand.Intn(180))*time.Minute
+		pref.ExitTime = baseExitTime + rand.Intn(180)
 		pref.POIPreferences[models.MainStage] = 0.5
 		pref.POIPreferences[models.SecondaryStage] = 0.5
 		pref.POIPreferences[models.FoodStand] = 0.5
@@ -96,24 +103,24 @@ func GetZonePreference(pattern MovementPattern) ZonePreference {
 			pref.POIPreferences[poiType] = 0.5
 		}
 		// Initialize last visit times to simulation start
-		pref.LastPOIVisit[poiType] = time.Now()
+		pref.LastPOIVisit[poiType] = 0
 	}
 
 	return pref
 }
 
-func (z *ZonePreference) ShouldMoveToZone(currentZone string, entryTime time.Time) bool {
-	timeSinceEntry := time.Since(entryTime)
-
+// ShouldMoveToZone decides whether to leave the current zone, ticksSinceEntry is the
+// time spent in the festival.
+func (z *ZonePreference) ShouldMoveToZone(currentZone string, ticksSinceEntry int) bool {
 	switch currentZone {
 	case "entrance":
-		if timeSinceEntry > 15*time.Minute {
+		if ticksSinceEntry > ENTRANCE_ZONE_TICKS {
 			return true
 		}
 		return rand.Float64() > z.EntranceZoneWeight
 
 	case "main":
-		if timeSinceEntry > z.ExitTime-30*time.Minute {
+		if ticksSinceEntry > z.ExitTime-EXIT_ZONE_LEAD_TICKS {
 			return true
 		}
 		return rand.Float64() > z.MainZoneWeight
@@ -133,29 +140,31 @@ func (z *ZonePreference) GetPOIPreference(poiType models.POIType) float64 {
 	return 0.5
 }
 
-func (z *ZonePreference) ShouldVisitPOI(poiType models.POIType) bool {
-	baseProbability := z.GetPOIPreference(poiType)
+// ShouldVisitPOI decides whether to go to a POI, urgency (0.0 to 1.0) is how much
+// the person needs it and raises the probability.
+func (z *ZonePreference) ShouldVisitPOI(poiType models.POIType, urgency float64, currentTick int) bool {
+	baseProbability := z.GetPOIPreference(poiType) + urgency
 	lastVisit, exists := z.LastPOIVisit[poiType]
 
 	if !exists {
-		z.LastPOIVisit[poiType] = time.Now()
+		z.LastPOIVisit[poiType] = currentTick
 		return rand.Float64() < baseProbability
 	}
 
-	timeSinceVisit := time.Since(lastVisit)
-	timeMultiplier := math.Min(timeSinceVisit.Hours()/2.0, 1.0)
+	ticksSinceVisit := currentTick - lastVisit
+	timeMultiplier := math.Min(float64(ticksSinceVisit)/POI_REVISIT_TICKS, 1.0)
 	adjustedProbability := baseProbability * (1 + timeMultiplier)
 
 	return rand.Float64() < adjustedProbability
 }
 
-func (z *ZonePreference) GetNextZone(currentZone string, entryTime time.Time) string {
-	if z.ShouldMoveToZone(currentZone, entryTime) {
+func (z *ZonePreference) GetNextZone(currentZone string, ticksSinceEntry int) string {
+	if z.ShouldMoveToZone(currentZone, ticksSinceEntry) {
 		switch currentZone {
 		case "entrance":
 			return "main"
 		case "main":
-			if time.Since(entryTime) > z.ExitTime-30*time.Minute {
+			if ticksSinceEntry > z.ExitTime-EXIT_ZONE_LEAD_TICKS {
 				return "exit"
 			}
 		}
@@ -0,0 +1,125 @@
+package persons
+
+import (
+	"UTC_IA04/pkg/models"
+	"math"
+)
+
+const (
+	HYDRATION_LOSS_PER_TICK   = 0.002
+	HUNGER_GAIN_PER_TICK      = 0.0015
+	BLADDER_GAIN_PER_TICK     = 0.0015
+	ALCOHOL_ELIMINATION       = 0.0025 // g/L per tick, about 0.15 g/L per hour
+	URGENT_NEED_THRESHOLD     = 0.6
+	DEHYDRATION_THRESHOLD     = 0.3
+	INTOXICATION_THRESHOLD    = 1.0 // g/L
+	ALCOHOL_PER_DRINK         = 0.25
+	HYDRATION_PER_DRINK       = 0.4
+	HUNGER_RELIEF_PER_MEAL    = 0.7
+	BLADDER_FILL_PER_DRINK    = 0.2
+	DRINKER_PROPORTION        = 0.6
+	ALCOHOL_DEHYDRATION_RATIO = 0.5
+)
+
+// Needs are the internal variables of a person, they evolve every tick and are reset by POI visits.
+type Needs struct {
+	Hydration float64 // 1.0 = well hydrated, 0.0 = severely dehydrated
+	Hunger    float64 // 0.0 = satiated, 1.0 = starving
+	Bladder   float64 // 0.0 = empty, 1.0 = urgent
+	Alcohol   float64 // Blood alcohol level in g/L
+	Drinker   bool    // Drinks alcohol at the drink stands
+}
+
+func NewNeeds(drinker bool) Needs {
+	return Needs{
+		Hydration: 0.8,
+		Hunger:    0.2,
+		Bladder:   0.1,
+		Alcohol:   0,
+		Drinker:   drinker,
+	}
+}
+
+// Update makes the needs evolve during one tick, heat speeds up dehydration.
+func (n *Needs) Update(heatStress float64) {
+	hydrationLoss := HYDRATION_LOSS_PER_TICK * (1 + 2*heatStress)
+	// L'alcool déshydrate
+	hydrationLoss += n.Alcohol * ALCOHOL_DEHYDRATION_RATIO * HYDRATION_LOSS_PER_TICK
+
+	n.Hydration = math.Max(0, n.Hydration-hydrationLoss)
+	n.Hunger = math.Min(1, n.Hunger+HUNGER_GAIN_PER_TICK)
+	n.Bladder = math.Min(1, n.Bladder+BLADDER_GAIN_PER_TICK)
+	n.Alcohol = math.Max(0, n.Alcohol-ALCOHOL_ELIMINATION)
+}
+
+// Visit applies the effect of a POI visit.
+func (n *Needs) Visit(poiType models.POIType) {
+	switch poiType {
+	case models.DrinkStand:
+		n.Hydration = math.Min(1, n.Hydration+HYDRATION_PER_DRINK)
+		n.Bladder = math.Min(1, n.Bladder+BLADDER_FILL_PER_DRINK)
+		if n.Drinker {
+			n.Alcohol += ALCOHOL_PER_DRINK
+		}
+	case models.FoodStand:
+		n.Hunger = math.Max(0, n.Hunger-HUNGER_RELIEF_PER_MEAL)
+	case models.Toilet:
+		n.Bladder = 0
+	}
+}
+
+// Urgency returns how much the person needs the given POI, from 0.0 to 1.0.
+func (n Needs) Urgency(poiType models.POIType) float64 {
+	switch poiType {
+	case models.DrinkStand:
+		return 1 - n.Hydration
+	case models.FoodStand:
+		return n.Hunger
+	case models.Toilet:
+		return n.Bladder
+	default:
+		return 0
+	}
+}
+
+// MostUrgent returns the POI type answering the most pressing need, if any need is urgent.
+func (n Needs) MostUrgent() (models.POIType, bool) {
+	best := models.POIType(-1)
+	bestUrgency := URGENT_NEED_THRESHOLD
+	for _, poiType := range []models.POIType{models.DrinkStand, models.Toilet, models.FoodStand} {
+		if urgency := n.Urgency(poiType); urgency >= bestUrgency {
+			best = poiType
+			bestUrgency = urgency
+		}
+	}
+	return best, best >= 0
+}
+
+// DistressFactor scales the probability of a malaise, dehydration and alcohol make it more likely.
+func (n Needs) DistressFactor() float64 {
+	factor := 1.0
+	if n.Hydration < DEHYDRATION_THRESHOLD {
+		factor += 2 * (DEHYDRATION_THRESHOLD - n.Hydration) / DEHYDRATION_THRESHOLD
+	}
+	if n.Alcohol > INTOXICATION_THRESHOLD/2 {
+		factor += n.Alcohol - INTOXICATION_THRESHOLD/2
+	}
+	return factor
+}
+
+// DistressWeights returns the factors applied to the incident type frequencies.
+func (n Needs) DistressWeights() map[models.DistressType]float64 {
+	weights := map[models.DistressType]float64{
+		models.Dehydration:  1,
+		models.Intoxication: 1,
+	}
+	if n.Hydration < DEHYDRATION_THRESHOLD {
+		weights[models.Dehydration] += 4 * (DEHYDRATION_THRESHOLD - n.Hydration) / DEHYDRATION_THRESHOLD
+	}
+	if n.Alcohol > INTOXICATION_THRESHOLD/2 {
+		weights[models.Intoxication] += 4 * (n.Alcohol - INTOXICATION_THRESHOLD/2)
+	} else if n.Alcohol == 0 {
+		weights[models.Intoxication] = 0.2
+	}
+	return weights
+}
@@ -4,18 +4,20 @@ import (
 	"UTC_IA04/pkg/models"
 	"fmt"
 	"math/rand"
-	"time"
 )
 
 type Person struct {
 	ID                      int
 	Position                models.Position
 	InDistress              bool
+	DistressType            models.DistressType
+	Severity                models.Severity
 	Dead                    bool
 	StillInSim              bool
 	DistressProbability     float64
 	Lifespan                int
 	CurrentDistressDuration int
+	UnderTreatment          bool // A rescuer treats the person on site, the distress no longer gets worse
 	width                   int
 	height                  int
 	MoveChan                chan models.MovementRequest
@@ -25,25 +27,37 @@ type Person struct {
 	State                   StateData
 	MovementPattern         MovementPattern
 	ZonePreference          ZonePreference
-	EntryTime               time.Time
+	EntryTick               int // Tick at which the person passed the gate
 	CurrentPath             []models.Position
 	CurrentPOI              *models.POIType
 	TargetPOIPosition       *models.Position
-	TimeAtPOI               time.Duration
-	LastZoneChange          time.Time
+	TimeAtPOI               int // Ticks
+	LastZoneChange          int // Tick
 	debug                   bool
 	hardDebug               bool
-	HasReceivedMedical      bool
+	HasReceivedMedical      bool // A drone dropped a kit during the current distress
+	Stabilised              bool // The kit keeps the person alive until the rescuers arrive
 	SeekingExit             bool
-	TreatmentTime           time.Duration
+	TreatmentTime           int // Ticks
 	AssignedDroneID         *int
+	GetWeather              func() models.WeatherConditions
+	GetTick                 func() int // Simulation clock
+	Needs                   Needs
+	Arrived                 bool // Has passed an entrance gate
+	ArrivalTick             int
+	DepartureTick           int
+	ExitTarget              *models.Position         // Emergency exit, nil for the regular exit
+	AvoidCells              map[models.Position]bool // Hazard area to walk around during an evacuation
+	Guided                  bool                     // A drone is guiding the person towards the exit
+	Appearance              models.Appearance
+	Lost                    bool // Separated from their group, wanders until a drone finds them
+	LostTick                int
 }
 
-func NewCrowdMember(id int, position models.Position, distressProbability float64, lifespan int, width int, height int, moveChan chan models.MovementRequest, deadChan chan models.DeadRequest, exitChan chan models.ExitRequest) Person {
+func NewCrowdMember(id int, position models.Position, distressProbability float64, lifespan int, width int, height int, moveChan chan models.MovementRequest, deadChan chan models.DeadRequest, exitChan chan models.ExitRequest, getWeather func() models.WeatherConditions, getTick func() int) Person {
 	profileType := ProfileType(rand.Intn(4))
 	movementPattern := MovementPattern(rand.Intn(5))
 	zonePreference := GetZonePreference(movementPattern)
-	now := time.Now()
 
 	p := Person{
 		ID:                      id,
@@ -64,16 +78,20 @@ func NewCrowdMember(id int, position models.Position, distressProbability float6
 		State:                   NewStateData(),
 		MovementPattern:         movementPattern,
 		ZonePreference:          zonePreference,
-		EntryTime:               now,
+		EntryTick:               0,
 		CurrentPath:             make([]models.Position, 0),
 		CurrentPOI:              nil,
 		TargetPOIPosition:       nil,
 		TimeAtPOI:               0,
-		LastZoneChange:          now,
+		LastZoneChange:          0,
 		debug:                   false,
 		hardDebug:               false,
 		HasReceivedMedical:      false,
 		TreatmentTime:           0,
+		GetWeather:              getWeather,
+		GetTick:                 getTick,
+		Needs:                   NewNeeds(rand.Float64() < DRINKER_PROPORTION),
+		Appearance:              models.RandomAppearance(),
 	}
 	return p
 }
@@ -84,14 +102,28 @@ func (p *Person) IsAssigned() bool {
 
 func (c *Person) Myturn() {
 	if c.SeekingExit && !c.InDistress {
+		if !c.Guided && c.slowedByRain() {
+			return
+		}
 		if len(c.CurrentPath) == 0 {
 			exitPos := models.Position{X: (float64(c.width)/10)*9 + 0.1, Y: c.Position.Y}
+			if c.ExitTarget != nil {
+				exitPos = *c.ExitTarget
+			}
 			if exitPos.CalculateDistance(c.Position) < 1 {
 				c.Exit()
 				return
 			}
 
-			path := models.FindPath(c.Position, exitPos, c.width, c.height, make(map[models.Position]bool))
+			avoid := c.AvoidCells
+			if avoid == nil {
+				avoid = make(map[models.Position]bool)
+			}
+			path := models.FindPath(c.Position, exitPos, c.width, c.height, avoid)
+			if path == nil && len(avoid) > 0 {
+				// Encerclé par le danger : traverser plutôt que rester bloqué
+				path = models.FindPath(c.Position, exitPos, c.width, c.height, make(map[models.Position]bool))
+			}
 			c.CurrentPath = path
 		}
 		c.goTo()
@@ -109,6 +141,13 @@ func (c *Person) Myturn() {
 		c.UpdateHealth()
 		return
 	}
+	if c.Lost {
+		c.UpdateHealth()
+		if !c.slowedByRain() {
+			c.wander()
+		}
+		return
+	}
 	c.State.UpdateState(c)
 	c.UpdateHealth()
 
@@ -119,13 +158,22 @@ func (c *Person) Myturn() {
 
 	obstacles := make(map[models.Position]bool)
 
+	if c.State.CurrentState != Resting && c.slowedByRain() {
+		return
+	}
+
 	switch c.State.CurrentState {
 	case Exploring:
 		c.UpdatePosition(obstacles)
 	case SeekingPOI:
+		if c.CurrentPOI == nil {
+			if poiType, urgent := c.Needs.MostUrgent(); urgent {
+				c.CurrentPOI = &poiType
+			}
+		}
 		if c.CurrentPOI == nil {
 			for poiType := range c.ZonePreference.POIPreferences {
-				if c.ZonePreference.ShouldVisitPOI(poiType) {
+				if c.ZonePreference.ShouldVisitPOI(poiType, c.Needs.Urgency(poiType), c.currentTick()) {
 					c.CurrentPOI = &poiType
 					break
 				}
@@ -134,7 +182,7 @@ func (c *Person) Myturn() {
 		c.UpdatePosition(obstacles)
 	case Resting:
 		// Don't move while resting
-		c.TimeAtPOI += time.Second
+		c.TimeAtPOI++
 		if c.Profile.StaminaLevel > 0.8 {
 			c.State.CurrentState = Exploring
 			c.TimeAtPOI = 0
@@ -150,6 +198,8 @@ func (c *Person) UpdatePosition(obstacles map[models.Position]bool) bool {
 	}
 
 	if c.HasReachedPOI() {
+		c.Needs.Visit(*c.CurrentPOI)
+		c.ZonePreference.LastPOIVisit[*c.CurrentPOI] = c.currentTick()
 		c.State.CurrentState = Resting
 		c.State.TimeInState = 0
 		return false
@@ -170,6 +220,28 @@ func (c *Person) UpdatePosition(obstacles map[models.Position]bool) bool {
 	return false
 }
 
+// wander moves a lost person to a random neighbouring cell, away from the exit zone.
+func (c *Person) wander() {
+	target := models.Position{
+		X: c.Position.X + float64(rand.Intn(3)-1),
+		Y: c.Position.Y + float64(rand.Intn(3)-1),
+	}
+	if target.X < 0 || target.Y < 0 || target.X >= float64(c.width)*9/10-1 || target.Y >= float64(c.height) {
+		return
+	}
+	c.tryMove(target)
+}
+
+// GetLost separates the person from their group.
+func (c *Person) GetLost(tick int) {
+	c.Lost = true
+	c.LostTick = tick
+	c.CurrentPath = nil
+	c.CurrentPOI = nil
+	c.TargetPOIPosition = nil
+	c.State.CurrentState = Exploring
+}
+
 func (c *Person) goTo() bool {
 	if len(c.CurrentPath) > 0 {
 		nextPos := c.CurrentPath[0]
@@ -221,10 +293,11 @@ func (c *Person) generateNewPath(obstacles map[models.Position]bool) {
 		targetPos = *c.TargetPOIPosition
 	} else {
 		currentZone := c.determineCurrentZone()
-		targetZone := c.ZonePreference.GetNextZone(currentZone, c.EntryTime)
+		targetZone := c.ZonePreference.GetNextZone(currentZone, c.GetTimeSinceEntry())
 		if targetZone == currentZone {
 			targetPos = c.getRandomZonePosition(targetZone)
 		} else {
+			c.LastZoneChange = c.currentTick()
 			targetPos = c.getZoneEntryPoint(targetZone)
 		}
 	}
@@ -272,6 +345,7 @@ func (c *Person) determineCurrentZone() string {
 }
 
 func (c *Person) UpdateHealth() {
+	heatStress := c.heatStress()
 
 	if c.State.CurrentState == Resting {
 		c.Profile.StaminaLevel += 0.01
@@ -283,7 +357,7 @@ func (c *Person) UpdateHealth() {
 		if c.State.CurrentState == SeekingPOI {
 			staminaReduction = 0.002
 		}
-		c.Profile.StaminaLevel -= staminaReduction
+		c.Profile.StaminaLevel -= staminaReduction * (1 + heatStress)
 		if c.Profile.StaminaLevel < 0 {
 			c.Profile.StaminaLevel = 0
 		}
@@ -291,23 +365,71 @@ func (c *Person) UpdateHealth() {
 
 	if c.InDistress {
 		c.CurrentDistressDuration++
-		if c.CurrentDistressDuration >= c.Lifespan {
+		if c.CurrentDistressDuration >= c.Lifespan && !c.Stabilised && !c.UnderTreatment {
 			c.Die()
 		}
 	} else {
+		c.Needs.Update(heatStress)
+
 		effectiveProbability := c.DistressProbability *
 			(1.0 - c.Profile.MalaiseResistance) *
-			(1.0 - c.Profile.StaminaLevel)
+			(1.0 - c.Profile.StaminaLevel) *
+			(1.0 + heatStress) *
+			c.Needs.DistressFactor()
 
 		randNum := rand.Float64() * 20
 
 		if randNum < effectiveProbability {
-			c.InDistress = true
+			weights := c.Needs.DistressWeights()
+			weights[models.Dehydration] *= 1 + 3*heatStress
+			c.StartDistress(models.RandomDistressType(weights))
 		}
 		c.CurrentDistressDuration = 0
 	}
 }
 
+// StartDistress puts the person in distress, the incident type decides
+// its severity and how long the person survives without treatment.
+func (c *Person) StartDistress(distressType models.DistressType) {
+	profile := models.DistressProfiles[distressType]
+	c.InDistress = true
+	c.DistressType = distressType
+	c.Severity = profile.Severity
+	c.Lifespan = profile.SampleLifespan()
+	c.CurrentDistressDuration = 0
+	c.UnderTreatment = false
+	c.HasReceivedMedical = false
+	c.Stabilised = false
+}
+
+// ReceiveKit applies the kit a drone dropped: more time to live, or no limit until the rescuers arrive.
+func (c *Person) ReceiveKit() {
+	profile := models.DistressProfiles[c.DistressType]
+	c.HasReceivedMedical = true
+	c.Lifespan += profile.KitExtraTicks
+	c.Stabilised = profile.KitStabilises
+}
+
+func (c *Person) weather() models.WeatherConditions {
+	if c.GetWeather == nil {
+		return models.DefaultWeather
+	}
+	return c.GetWeather()
+}
+
+// heatStress is the heat felt by the person, the shade of a rest area cuts most of it.
+func (c *Person) heatStress() float64 {
+	stress := c.weather().HeatStress()
+	if c.State.CurrentState == Resting && c.CurrentPOI != nil && *c.CurrentPOI == models.RestArea {
+		stress *= 0.3
+	}
+	return stress
+}
+
+func (c *Person) slowedByRain() bool {
+	return rand.Float64() < c.weather().RainSlowdown()
+}
+
 func (c *Person) Die() {
 	if c.IsDead() {
 		return
@@ -370,8 +492,16 @@ func (c *Person) IsDead() bool {
 	return c.Dead
 }
 
-func (c *Person) GetTimeSinceEntry() time.Duration {
-	return time.Since(c.EntryTime)
+// GetTimeSinceEntry returns the number of ticks spent in the festival.
+func (c *Person) GetTimeSinceEntry() int {
+	return c.currentTick() - c.EntryTick
+}
+
+func (c *Person) currentTick() int {
+	if c.GetTick == nil {
+		return 0
+	}
+	return c.GetTick()
 }
 
 func (c *Person) GetCurrentZone() string {
@@ -382,6 +512,10 @@ func (c *Person) IsInDistress() bool {
 	return c.InDistress
 }
 
+func (c *Person) GetSeverity() models.Severity {
+	return c.Severity
+}
+
 func (c *Person) GetStamina() float64 {
 	return c.Profile.StaminaLevel
 }
@@ -48,6 +48,10 @@ func (s *StateData) UpdateState(person *Person) {
 			s.CurrentState = SeekingPOI
 			s.TargetPOI = poiTypePtr(models.RestArea)
 			s.TimeInState = 0
+		} else if poiType, urgent := person.Needs.MostUrgent(); urgent {
+			s.CurrentState = SeekingPOI
+			s.TargetPOI = poiTypePtr(poiType)
+			s.TimeInState = 0
 		} else {
 			for poiType, interest := range person.ZonePreference.POIPreferences {
 				if interest > 0.7 && rand.Float64() < interest {
@@ -0,0 +1,89 @@
+package rescue
+
+import (
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+const (
+	CROWD_ALERT_MERGE_DISTANCE = 2.0 // Reports closer than this update the same alert
+	CROWD_ALERT_TIMEOUT        = 10  // Ticks without report before an alert is resolved
+)
+
+// ReportCrowdRisk logs a hotspot reported by a drone and returns the alert tracking it.
+func (rp *RescuePoint) ReportCrowdRisk(report models.CrowdRiskReport) *models.CrowdAlert {
+	rp.alertsMu.Lock()
+	defer rp.alertsMu.Unlock()
+
+	for _, alert := range rp.CrowdAlerts {
+		if alert.Resolved || alert.Position.CalculateDistance(report.Cell.Position) > CROWD_ALERT_MERGE_DISTANCE {
+			continue
+		}
+		alert.Reports++
+		alert.ReportedBy[report.DroneID] = true
+		alert.LastTick = report.Tick
+		alert.MaxDensity = max(alert.MaxDensity, report.Cell.Density)
+		alert.MaxPressure = max(alert.MaxPressure, report.Cell.Pressure)
+		if report.Level > alert.Level {
+			alert.Level = report.Level
+			fmt.Printf("[RP %d] Crowd alert %d escalated to %s at (%.0f, %.0f)\n",
+				rp.ID, alert.ID, alert.Level, alert.Position.X, alert.Position.Y)
+		}
+		return alert
+	}
+
+	alert := &models.CrowdAlert{
+		ID:          len(rp.CrowdAlerts),
+		Position:    report.Cell.Position,
+		Level:       report.Level,
+		MaxDensity:  report.Cell.Density,
+		MaxPressure: report.Cell.Pressure,
+		Reports:     1,
+		ReportedBy:  map[int]bool{report.DroneID: true},
+		FirstTick:   report.Tick,
+		LastTick:    report.Tick,
+	}
+	rp.CrowdAlerts = append(rp.CrowdAlerts, alert)
+	fmt.Printf("[RP %d] New %s crowd alert %d from drone %d at (%.0f, %.0f): %.1f p/m², pressure %.4f\n",
+		rp.ID, alert.Level, alert.ID, report.DroneID, alert.Position.X, alert.Position.Y,
+		report.Cell.Density, report.Cell.Pressure)
+	return alert
+}
+
+// ResolveCrowdAlerts closes the alerts that no drone has reported for a while.
+func (rp *RescuePoint) ResolveCrowdAlerts(tick int) {
+	rp.alertsMu.Lock()
+	defer rp.alertsMu.Unlock()
+
+	for _, alert := range rp.CrowdAlerts {
+		if !alert.Resolved && tick-alert.LastTick > CROWD_ALERT_TIMEOUT {
+			alert.Resolved = true
+			if rp.debug {
+				fmt.Printf("[RP %d] Crowd alert %d resolved after %d ticks\n", rp.ID, alert.ID, alert.LastTick-alert.FirstTick)
+			}
+		}
+	}
+}
+
+// CrowdAlertsSnapshot returns a copy of every alert raised by this rescue point.
+func (rp *RescuePoint) CrowdAlertsSnapshot() []models.CrowdAlert {
+	rp.alertsMu.Lock()
+	defer rp.alertsMu.Unlock()
+
+	alerts := make([]models.CrowdAlert, 0, len(rp.CrowdAlerts))
+	for _, alert := range rp.CrowdAlerts {
+		alerts = append(alerts, *alert)
+	}
+	return alerts
+}
+
+// OpenCrowdAlerts returns a copy of the alerts still open.
+func (rp *RescuePoint) OpenCrowdAlerts() []models.CrowdAlert {
+	open := make([]models.CrowdAlert, 0)
+	for _, alert := range rp.CrowdAlertsSnapshot() {
+		if !alert.Resolved {
+			open = append(open, alert)
+		}
+	}
+	return open
+}
@@ -0,0 +1,64 @@
+package rescue
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/entities/persons"
+	"fmt"
+)
+
+func (rp *RescuePoint) BusAddress() bus.Address {
+	return bus.Address{Kind: bus.RescuePointNode, ID: rp.ID}
+}
+
+func (rp *RescuePoint) Deliver(msg bus.Message) {
+	rp.Inbox.Push(msg)
+}
+
+// ReadInbox handles the incident reports received since the last tick. The ack lists the people
+// taken in charge, by this rescue point or another one; the drone reports the others again.
+// It returns all the people taken in charge during the tick.
+func (rp *RescuePoint) ReadInbox(tick int) []*persons.Person {
+	dispatched := make([]*persons.Person, 0)
+	for _, msg := range rp.Inbox.Drain() {
+		if msg.Type != bus.IncidentReport {
+			continue
+		}
+		taken := make([]*persons.Person, 0, len(msg.Incidents))
+		for _, incident := range msg.Incidents {
+			respChan := make(chan RescueResponse)
+			rp.RequestChan <- RescueRequest{
+				PersonID:      incident.Person.ID,
+				Position:      incident.Person.Position,
+				DroneSenderID: msg.From.ID,
+				Severity:      incident.Severity,
+				DistressType:  incident.DistressType,
+				ReceivedTick:  tick,
+				ResponseChan:  respChan,
+			}
+			response := <-respChan
+			if response.Accepted {
+				taken = append(taken, incident.Person)
+			} else if rp.debug {
+				fmt.Printf("[RP %d] Person %d reported by drone %d will not be rescued -- ERROR : %v\n",
+					rp.ID, incident.Person.ID, msg.From.ID, response.Error)
+			}
+		}
+		if msg.Sender != nil {
+			msg.Sender.Deliver(bus.Message{
+				Type:    bus.HandoverAck,
+				From:    rp.BusAddress(),
+				Sender:  rp,
+				Tick:    tick,
+				Seq:     msg.Seq,
+				Persons: taken,
+			})
+		}
+		dispatched = append(dispatched, taken...)
+	}
+	return dispatched
+}
+
+// DropInbox loses the messages received while the rescue point is offline.
+func (rp *RescuePoint) DropInbox() {
+	rp.Inbox.Drain()
+}
@@ -1,9 +1,13 @@
 package rescue
 
 import (
+	"UTC_IA04/pkg/entities/bus"
 	"UTC_IA04/pkg/entities/persons"
 	"UTC_IA04/pkg/models"
 	"fmt"
+	"math"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 )
@@ -19,6 +23,15 @@ type RescuePoint struct {
 	SavePersonByRescuer  chan models.RescuePeopleRequest
 	ActiveMissions       sync.Map
 	AllRescuePoints      []*RescuePoint
+	MaxRescuers          int
+	Equipment            map[models.Equipment]int
+	PendingRequests      []RescueRequest
+	CrowdAlerts          []*models.CrowdAlert
+	SearchMissions       []*models.SearchMission
+	Inbox                *bus.Inbox
+	mu                   sync.Mutex
+	alertsMu             sync.Mutex
+	searchMu             sync.Mutex
 	debug                bool
 }
 
@@ -26,6 +39,9 @@ type RescueRequest struct {
 	PersonID      int
 	Position      models.Position
 	DroneSenderID int
+	Severity      models.Severity
+	DistressType  models.DistressType
+	ReceivedTick  int
 	ResponseChan  chan RescueResponse
 }
 
@@ -35,8 +51,12 @@ type RescueResponse struct {
 	Error         error
 }
 
-func NewRescuePoint(id int, position models.Position, savePersonByRescuer chan models.RescuePeopleRequest, debug bool) *RescuePoint {
+func NewRescuePoint(id int, position models.Position, maxRescuers int, savePersonByRescuer chan models.RescuePeopleRequest, debug bool) *RescuePoint {
 	fmt.Printf("[RP] New RescuePoint created at position (%.0f, %.0f)\n", position.X, position.Y)
+	equipment := make(map[models.Equipment]int)
+	for _, e := range []models.Equipment{models.FirstAidKit, models.IVFluids, models.Stretcher, models.Defibrillator} {
+		equipment[e] = max(1, maxRescuers/2)
+	}
 	return &RescuePoint{
 		ID:                   id,
 		Position:             position,
@@ -47,6 +67,12 @@ func NewRescuePoint(id int, position models.Position, savePersonByRescuer chan m
 		ResponseChan:         make(chan RescueResponse),
 		AllRescuePoints:      make([]*RescuePoint, 0),
 		SavePersonByRescuer:  savePersonByRescuer,
+		MaxRescuers:          maxRescuers,
+		Equipment:            equipment,
+		PendingRequests:      make([]RescueRequest, 0),
+		CrowdAlerts:          make([]*models.CrowdAlert, 0),
+		SearchMissions:       make([]*models.SearchMission, 0),
+		Inbox:                bus.NewInbox(),
 		debug:                debug,
 	}
 }
@@ -131,51 +157,127 @@ func (rp *RescuePoint) handleRequests() {
 			continue
 		}
 
-		// Vérifier la disponibilité d'un rescuer
-		rescuer := rp.getAvailableRescuer()
-		if rescuer == nil {
-			req.ResponseChan <- RescueResponse{
-				Accepted: false,
-				Error:    fmt.Errorf("no available rescuers"),
-			}
-			continue
-		}
-
-		// Assigner la mission
-		rp.assignMission(rescuer, req)
-		rp.ActiveMissions.Store(req.PersonID, true)
-
-		req.ResponseChan <- RescueResponse{
-			Accepted:      true,
-			RescuePointID: rp.ID,
-		}
+		rp.acceptRequest(req)
 
 		if rp.debug {
-			fmt.Printf("[RP] Mission assigned to RescuePoint %d to Rescue Person : %d by Drone %d\n", rp.ID, req.PersonID, req.DroneSenderID)
+			fmt.Printf("[RP] Mission accepted by RescuePoint %d to Rescue Person : %d by Drone %d (severity %s)\n", rp.ID, req.PersonID, req.DroneSenderID, req.Severity)
 		}
 	}
 }
 
 func (rp *RescuePoint) handleRPRequests() {
 	for req := range rp.RPRequestChan {
-		// Vérifier la disponibilité d'un rescuer
+		rp.acceptRequest(req)
+	}
+}
+
+// acceptRequest queues the request for triage and answers the sender.
+// The mission is dispatched as soon as a rescuer and the equipment are available.
+func (rp *RescuePoint) acceptRequest(req RescueRequest) {
+	rp.mu.Lock()
+	rp.PendingRequests = append(rp.PendingRequests, req)
+	rp.mu.Unlock()
+	rp.ActiveMissions.Store(req.PersonID, true)
+
+	req.ResponseChan <- RescueResponse{
+		Accepted:      true,
+		RescuePointID: rp.ID,
+	}
+	rp.dispatchPendingRequests()
+}
+
+// dispatchPendingRequests assigns queued requests to free rescuers, the most severe first.
+// A request whose equipment is out of stock waits while less severe ones can still be served.
+func (rp *RescuePoint) dispatchPendingRequests() {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	sort.SliceStable(rp.PendingRequests, func(i, j int) bool {
+		return rp.PendingRequests[i].Severity > rp.PendingRequests[j].Severity
+	})
+
+	remaining := make([]RescueRequest, 0, len(rp.PendingRequests))
+	for _, req := range rp.PendingRequests {
+		equipment := models.DistressProfiles[req.DistressType].Equipment
+		if !rp.hasEquipment(equipment) {
+			remaining = append(remaining, req)
+			continue
+		}
 		rescuer := rp.getAvailableRescuer()
 		if rescuer == nil {
-			req.ResponseChan <- RescueResponse{
-				Accepted: false,
-				Error:    fmt.Errorf("no available rescuers"),
-			}
+			remaining = append(remaining, req)
 			continue
 		}
-
+		rp.takeEquipment(rescuer, equipment)
 		rp.assignMission(rescuer, req)
-		rp.ActiveMissions.Store(req.PersonID, true)
+	}
+	rp.PendingRequests = remaining
+}
 
-		req.ResponseChan <- RescueResponse{
-			Accepted:      true,
-			RescuePointID: rp.ID,
+func (rp *RescuePoint) hasEquipment(equipment []models.Equipment) bool {
+	for _, e := range equipment {
+		if rp.Equipment[e] <= 0 {
+			return false
 		}
 	}
+	return true
+}
+
+func (rp *RescuePoint) takeEquipment(rescuer *Rescuer, equipment []models.Equipment) {
+	for _, e := range equipment {
+		rp.Equipment[e]--
+	}
+	rescuer.Equipment = append(rescuer.Equipment[:0], equipment...)
+}
+
+// fetchMissingEquipment brings the rescuer the equipment the incident found on site needs and the
+// one the drone reported did not: a runner takes it from the rescue point, borrowing it when it is
+// out, and the treatment waits for it. It returns the ticks of the run, 0 when nothing is missing.
+func (rp *RescuePoint) fetchMissingEquipment(rescuer *Rescuer, distressType models.DistressType) int {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	missing := false
+	for _, e := range models.DistressProfiles[distressType].Equipment {
+		if !slices.Contains(rescuer.Equipment, e) {
+			rp.Equipment[e]--
+			rescuer.Equipment = append(rescuer.Equipment, e)
+			missing = true
+		}
+	}
+	if !missing {
+		return 0
+	}
+	// Le coureur avance d'une case par tick, diagonales comprises, comme les secouristes
+	return int(max(math.Abs(rescuer.Position.X-rp.Position.X), math.Abs(rescuer.Position.Y-rp.Position.Y)))
+}
+
+func (rp *RescuePoint) returnEquipment(rescuer *Rescuer) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	for _, e := range rescuer.Equipment {
+		rp.Equipment[e]++
+	}
+	rescuer.Equipment = rescuer.Equipment[:0]
+}
+
+// CancelRequest drops a queued request, e.g. when the person died or left before being reached.
+func (rp *RescuePoint) CancelRequest(personID int) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	for i, req := range rp.PendingRequests {
+		if req.PersonID == personID {
+			rp.PendingRequests = append(rp.PendingRequests[:i], rp.PendingRequests[i+1:]...)
+			rp.ActiveMissions.Delete(personID)
+			return
+		}
+	}
+}
+
+// QueueLength returns the number of accepted requests still waiting for a rescuer.
+func (rp *RescuePoint) QueueLength() int {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return len(rp.PendingRequests)
 }
 
 func (rp *RescuePoint) findClosestRescuePoint(pos models.Position) *RescuePoint {
@@ -228,7 +330,10 @@ func (rp *RescuePoint) getAvailableRescuer() *Rescuer {
 		}
 	}
 
-	// Si aucun rescuer n'est disponible, en créer un nouveau
+	// Si aucun rescuer n'est disponible, en créer un nouveau dans la limite de l'effectif
+	if rp.MaxRescuers > 0 && len(rp.Rescuers) >= rp.MaxRescuers {
+		return nil
+	}
 	newRescuerID := len(rp.Rescuers)
 	newRescuer := &Rescuer{
 		ID:        newRescuerID,
@@ -246,9 +351,12 @@ func (rp *RescuePoint) assignMission(rescuer *Rescuer, req RescueRequest) {
 	rescuer.Active = true
 	rescuer.State = MovingToPerson
 	rescuer.Person = &persons.Person{
-		ID:       req.PersonID,
-		Position: req.Position,
+		ID:           req.PersonID,
+		Position:     req.Position,
+		DistressType: req.DistressType,
+		Severity:     req.Severity,
 	}
+	rescuer.TreatmentRemaining = 0
 
 	if rp.debug {
 		fmt.Printf("[RESCUE POINT %d] Rescuer %d assigned to person %d at position (%.0f, %.0f)\n",
@@ -8,12 +8,14 @@ import (
 )
 
 type Rescuer struct {
-	ID        int
-	Position  models.Position
-	Person    *persons.Person
-	HomePoint models.Position
-	State     RescuerState
-	Active    bool
+	ID                 int
+	Position           models.Position
+	Person             *persons.Person
+	HomePoint          models.Position
+	State              RescuerState
+	Active             bool
+	Equipment          []models.Equipment
+	TreatmentRemaining int
 }
 
 type RescuerState int
@@ -22,12 +24,14 @@ const (
 	Idle RescuerState = iota
 	MovingToPerson
 	ReturningToBase
+	Treating
 )
 
 func (rp *RescuePoint) UpdateRescuers() {
 	if rp.debug {
 		fmt.Printf("[RESCUE POINT - %d] Updating rescuers\n", rp.ID)
 	}
+	rp.dispatchPendingRequests()
 	for index := range rp.Rescuers {
 		rescuer := rp.Rescuers[index]
 		if rescuer.Person != nil {
@@ -39,47 +43,49 @@ func (rp *RescuePoint) UpdateRescuers() {
 		if rescuer.State == MovingToPerson {
 			// Faire bouger jusqu'à la personne et mettre le rescuer en inactif
 			if rescuer.Position.CalculateDistance(rescuer.Person.Position) <= 1 {
-				rescueResponse := make(chan models.RescuePeopleResponse)
-				rp.SavePersonByRescuer <- models.RescuePeopleRequest{
-					PersonID:      rescuer.Person.ID,
-					RescuerID:     rescuer.ID,
-					RescuePointID: rp.ID,
-					ResponseChan:  rescueResponse,
-				}
-
-				select {
-				case response := <-rescueResponse:
-					if response.Authorized {
-						if rp.debug {
-							fmt.Printf("[RESCUER] Successfully healed person %d\n", rescuer.Person.ID)
-						}
-					}
-				case <-time.After(1 * time.Second):
-					fmt.Printf("[RESCUER] Timeout while waiting for response for person %d\n", rescuer.Person.ID)
-				}
-
+				response := rp.sendRescuePeopleRequest(rescuer, false)
 				personID := rescuer.Person.ID
-
-				rp.ActiveMissions.Delete(rescuer.Person.ID)
 				for i := range rp.Rescuers {
 					tempRescuer := rp.Rescuers[i]
-					if tempRescuer.Person != nil {
+					if tempRescuer != rescuer && tempRescuer.Person != nil {
 						if tempRescuer.Person.ID == personID {
 							tempRescuer.Person = nil
 							tempRescuer.State = ReturningToBase
 						}
 					}
 				}
+
+				// Le rescuer reste sur place le temps du traitement de la détresse constatée
+				if response.Authorized {
+					rescuer.State = Treating
+					rescuer.Person.DistressType = response.DistressType
+					rescuer.TreatmentRemaining = models.DistressProfiles[response.DistressType].TreatmentTime +
+						rp.fetchMissingEquipment(rescuer, response.DistressType)
+				} else {
+					rp.ActiveMissions.Delete(personID)
+					rescuer.Person = nil
+					rescuer.State = ReturningToBase
+				}
 			} else {
 				rescuer.Position = stepTowards(rescuer.Position, rescuer.Person.Position)
 			}
 		}
+		if rescuer.State == Treating {
+			rescuer.TreatmentRemaining--
+			if rescuer.TreatmentRemaining <= 0 {
+				rp.sendRescuePeopleRequest(rescuer, true)
+				rp.ActiveMissions.Delete(rescuer.Person.ID)
+				rescuer.Person = nil
+				rescuer.State = ReturningToBase
+			}
+		}
 		if rescuer.State == ReturningToBase {
 			if rescuer.Position.CalculateDistance(rescuer.HomePoint) <= 1 {
 				rescuer.State = Idle
 				rescuer.Person = nil
 				rescuer.Position = models.Position{X: rescuer.HomePoint.X, Y: rescuer.HomePoint.Y}
 				rescuer.Active = false
+				rp.returnEquipment(rescuer)
 			} else {
 				rescuer.Position = stepTowards(rescuer.Position, rescuer.HomePoint)
 			}
@@ -87,6 +93,31 @@ func (rp *RescuePoint) UpdateRescuers() {
 	}
 }
 
+// sendRescuePeopleRequest tells the simulation that the rescuer reached the person, or finished
+// treating them when done.
+func (rp *RescuePoint) sendRescuePeopleRequest(rescuer *Rescuer, done bool) models.RescuePeopleResponse {
+	rescueResponse := make(chan models.RescuePeopleResponse)
+	rp.SavePersonByRescuer <- models.RescuePeopleRequest{
+		PersonID:      rescuer.Person.ID,
+		RescuerID:     rescuer.ID,
+		RescuePointID: rp.ID,
+		DistressType:  rescuer.Person.DistressType,
+		Done:          done,
+		ResponseChan:  rescueResponse,
+	}
+
+	select {
+	case response := <-rescueResponse:
+		if response.Authorized && done && rp.debug {
+			fmt.Printf("[RESCUER] Successfully healed person %d\n", rescuer.Person.ID)
+		}
+		return response
+	case <-time.After(1 * time.Second):
+		fmt.Printf("[RESCUER] Timeout while waiting for response for person %d\n", rescuer.Person.ID)
+		return models.RescuePeopleResponse{}
+	}
+}
+
 func stepTowards(from models.Position, to models.Position) models.Position {
 	direction := models.Position{
 		X: to.X - from.X,
@@ -112,3 +143,16 @@ func stepTowards(from models.Position, to models.Position) models.Position {
 
 	return nextStep
 }
+
+// RescuerTargets returns the people the rescuers are walking to.
+func (rp *RescuePoint) RescuerTargets() []int {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	targets := make([]int, 0)
+	for _, rescuer := range rp.Rescuers {
+		if rescuer.State == MovingToPerson && rescuer.Person != nil {
+			targets = append(targets, rescuer.Person.ID)
+		}
+	}
+	return targets
+}
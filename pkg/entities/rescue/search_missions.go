@@ -0,0 +1,93 @@
+package rescue
+
+import (
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+// OpenSearchMission registers a missing person, the drones learn about it at the next broadcast.
+func (rp *RescuePoint) OpenSearchMission(mission models.SearchMission) *models.SearchMission {
+	rp.searchMu.Lock()
+	defer rp.searchMu.Unlock()
+
+	mission.RescuePointID = rp.ID
+	rp.SearchMissions = append(rp.SearchMissions, &mission)
+	who := "person"
+	if mission.Description.Child {
+		who = "child"
+	}
+	fmt.Printf("[RP %d] Search mission %d opened for a missing %s in %s, last seen at (%.0f, %.0f)\n",
+		rp.ID, mission.ID, who, mission.Description.Clothing, mission.LastSeen.X, mission.LastSeen.Y)
+	return &mission
+}
+
+// ReportSighting records a sighting for a mission of this rescue point or of another one.
+// The ground team checks each sighted person once and returns true if it is the missing person.
+func (rp *RescuePoint) ReportSighting(missionID int, sighting models.Sighting) bool {
+	if rp.reportSighting(missionID, sighting) {
+		return true
+	}
+	for _, other := range rp.AllRescuePoints {
+		if other.ID != rp.ID && other.reportSighting(missionID, sighting) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rp *RescuePoint) reportSighting(missionID int, sighting models.Sighting) bool {
+	rp.searchMu.Lock()
+	defer rp.searchMu.Unlock()
+
+	for _, mission := range rp.SearchMissions {
+		if mission.ID != missionID || !mission.Open() {
+			continue
+		}
+		for _, previous := range mission.Sightings {
+			if previous.PersonID == sighting.PersonID {
+				// Déjà vérifié par l'équipe au sol
+				return false
+			}
+		}
+		sighting.Confirmed = sighting.PersonID == mission.PersonID
+		mission.Sightings = append(mission.Sightings, sighting)
+		if sighting.Confirmed {
+			mission.Found = true
+			mission.FoundTick = sighting.Tick
+			mission.FoundBy = sighting.DroneID
+			fmt.Printf("[RP %d] Missing person of mission %d found by drone %d at (%.0f, %.0f) after %d ticks\n",
+				rp.ID, mission.ID, sighting.DroneID, sighting.Position.X, sighting.Position.Y, mission.TimeToFind())
+		}
+		return sighting.Confirmed
+	}
+	return false
+}
+
+// CancelSearchMission closes a mission whose person can no longer be found.
+func (rp *RescuePoint) CancelSearchMission(missionID int) {
+	rp.searchMu.Lock()
+	defer rp.searchMu.Unlock()
+
+	for _, mission := range rp.SearchMissions {
+		if mission.ID == missionID && mission.Open() {
+			mission.Cancelled = true
+			if rp.debug {
+				fmt.Printf("[RP %d] Search mission %d cancelled\n", rp.ID, mission.ID)
+			}
+		}
+	}
+}
+
+// SearchMissionsSnapshot returns a copy of every search mission of this rescue point.
+func (rp *RescuePoint) SearchMissionsSnapshot() []models.SearchMission {
+	rp.searchMu.Lock()
+	defer rp.searchMu.Unlock()
+
+	missions := make([]models.SearchMission, 0, len(rp.SearchMissions))
+	for _, mission := range rp.SearchMissions {
+		copied := *mission
+		copied.Sightings = append([]models.Sighting(nil), mission.Sightings...)
+		missions = append(missions, copied)
+	}
+	return missions
+}
@@ -0,0 +1,51 @@
+package models
+
+import "math/rand"
+
+// RatePeriod is a piece of a piecewise constant rate curve, from StartTick (included) to EndTick (excluded).
+type RatePeriod struct {
+	StartTick int
+	EndTick   int
+	Rate      float64 // Relative rate, only the ratios between periods matter
+}
+
+// AttendanceCurves describe when people arrive at and leave the festival.
+type AttendanceCurves struct {
+	Arrivals   []RatePeriod
+	Departures []RatePeriod
+}
+
+// AttendanceProfile is the attendance of a festival. ByPattern overrides the curves
+// for a MovementPattern (keys are the pattern values, like minPOIs).
+type AttendanceProfile struct {
+	AttendanceCurves
+	ByPattern   map[int]AttendanceCurves
+	TicketScans string // Optional CSV of ticket scans, overrides the arrival curves (ByPattern too) until they run out
+}
+
+// SampleTick draws a tick following the rate curve, false if the curve is empty.
+func SampleTick(periods []RatePeriod) (int, bool) {
+	total := 0.0
+	for _, p := range periods {
+		if p.EndTick > p.StartTick && p.Rate > 0 {
+			total += p.Rate * float64(p.EndTick-p.StartTick)
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	r := rand.Float64() * total
+	for _, p := range periods {
+		if p.EndTick <= p.StartTick || p.Rate <= 0 {
+			continue
+		}
+		weight := p.Rate * float64(p.EndTick-p.StartTick)
+		if r < weight {
+			return p.StartTick + int(r/p.Rate), true
+		}
+		r -= weight
+	}
+	last := periods[len(periods)-1]
+	return last.EndTick - 1, true
+}
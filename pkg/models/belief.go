@@ -0,0 +1,8 @@
+package models
+
+// CellObservation is what a drone saw of a map cell, shared with its neighbours for their belief maps.
+type CellObservation struct {
+	Cell   Position
+	Tick   int // Tick of the observation
+	People int // People detected in the cell
+}
@@ -1,12 +1,29 @@
 package models
 
+type ChargingAction int
+
+const (
+    ChargingAsk     ChargingAction = iota // Ask for a pad at the station, the drone is queued if all are taken
+    ChargingReserve                       // Book a pad before flying to the station
+    ChargingRelease                       // Leave the pad, the queue or the reservation
+)
+
 type ChargingRequest struct {
     DroneID      int
-    Position     Position
+    Position     Position // Position of the charging station
+    Action       ChargingAction
+    ArrivalTick  int     // Expected arrival, for a reservation
+    ChargeTicks  int     // Expected time on the pad
+    Battery      float64 // Battery % of the drone, left at the station on a hot swap
+    DroneType    string  // Type of the drone, a hot swap takes a spare of this type
     ResponseChan chan ChargingResponse
 }
 
 type ChargingResponse struct {
-    Authorized bool
-    Reason     string
-}
\ No newline at end of file
+    Authorized    bool
+    Reason        string
+    QueuePosition int     // 1 for the next drone to get a pad, 0 when not queued
+    Swapped       bool    // The battery was swapped for a charged spare
+    Battery       float64 // Battery % of the spare after a hot swap
+    SwapTicks     int     // Ticks on the ground to swap the battery
+}
@@ -0,0 +1,85 @@
+package models
+
+// Scale of the simulation, used to turn agents per cell into persons/m².
+const (
+	METERS_PER_UNIT  = 2.0  // Side of a map cell in meters
+	PEOPLE_PER_AGENT = 4.0  // Real attendees represented by a simulated person
+	SECONDS_PER_TICK = 60.0 // Length of a tick in seconds
+)
+
+type CrowdRiskLevel int
+
+const (
+	CrowdRiskNone CrowdRiskLevel = iota
+	CrowdRiskWarning
+	CrowdRiskCritical
+)
+
+// CrowdCell is the crowd state around a map cell. Pressure follows Helbing's
+// crowd pressure: local density times the variance of the velocities.
+type CrowdCell struct {
+	Position Position
+	Density  float64 // persons/m²
+	Pressure float64 // 1/s²
+}
+
+// CrowdThresholds are the density and pressure levels that raise an alert.
+type CrowdThresholds struct {
+	DensityWarning   float64
+	DensityCritical  float64
+	PressureWarning  float64
+	PressureCritical float64
+}
+
+// DefaultCrowdThresholds uses pressures lower than Helbing's 0.02/s² because a tick
+// lasts a minute, which smooths the velocities.
+var DefaultCrowdThresholds = CrowdThresholds{
+	DensityWarning:   4.0,
+	DensityCritical:  6.0,
+	PressureWarning:  0.0025,
+	PressureCritical: 0.005,
+}
+
+func (t CrowdThresholds) Level(cell CrowdCell) CrowdRiskLevel {
+	if cell.Density >= t.DensityCritical || cell.Pressure >= t.PressureCritical {
+		return CrowdRiskCritical
+	}
+	if cell.Density >= t.DensityWarning || cell.Pressure >= t.PressureWarning {
+		return CrowdRiskWarning
+	}
+	return CrowdRiskNone
+}
+
+// CrowdRiskReport is a hotspot seen by a drone.
+type CrowdRiskReport struct {
+	DroneID int
+	Cell    CrowdCell
+	Level   CrowdRiskLevel
+	Tick    int
+}
+
+// CrowdAlert is a hotspot tracked by the control room, successive reports of
+// the same area update the same alert until it is resolved.
+type CrowdAlert struct {
+	ID          int
+	Position    Position
+	Level       CrowdRiskLevel
+	MaxDensity  float64
+	MaxPressure float64
+	Reports     int
+	ReportedBy  map[int]bool // Drone IDs
+	FirstTick   int
+	LastTick    int
+	Resolved    bool
+}
+
+func (l CrowdRiskLevel) String() string {
+	switch l {
+	case CrowdRiskWarning:
+		return "Warning"
+	case CrowdRiskCritical:
+		return "Critical"
+	default:
+		return "None"
+	}
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+)
+
+type DistressType int
+type Severity int
+type Equipment int
+
+const (
+	NoDistress DistressType = iota
+	Dehydration
+	Intoxication
+	Injury
+	CardiacArrest
+)
+
+const (
+	SeverityNone Severity = iota
+	SeverityLow
+	SeverityModerate
+	SeveritySevere
+	SeverityCritical
+)
+
+const (
+	FirstAidKit Equipment = iota
+	IVFluids
+	Stretcher
+	Defibrillator
+)
+
+// DistressProfile describes how an incident type evolves and what it takes to treat it.
+// The time-to-death of an untreated case follows a Weibull distribution
+// (LifespanScale, LifespanShape) so that each type has its own survival curve.
+type DistressProfile struct {
+	Type          DistressType
+	Severity      Severity
+	Frequency     float64 // Relative frequency among new incidents
+	LifespanScale float64 // Ticks, characteristic time-to-death
+	LifespanShape float64 // > 1 means risk increases with time
+	TreatmentTime int     // Ticks a rescuer spends on site
+	Equipment     []Equipment
+	Kit           Equipment // Kit a drone can drop before the rescuers arrive
+	KitExtraTicks int       // Ticks the kit adds to the survival window
+	KitStabilises bool      // The kit keeps the person alive until the rescuers arrive
+}
+
+var DistressProfiles = map[DistressType]DistressProfile{
+	Dehydration: {
+		Type:          Dehydration,
+		Severity:      SeverityLow,
+		Frequency:     0.45,
+		LifespanScale: 320,
+		LifespanShape: 2.0,
+		TreatmentTime: 8,
+		Equipment:     []Equipment{IVFluids},
+		Kit:           FirstAidKit,
+		KitStabilises: true,
+	},
+	Intoxication: {
+		Type:          Intoxication,
+		Severity:      SeverityModerate,
+		Frequency:     0.30,
+		LifespanScale: 220,
+		LifespanShape: 1.5,
+		TreatmentTime: 12,
+		Equipment:     []Equipment{FirstAidKit},
+		Kit:           FirstAidKit,
+		KitExtraTicks: 120,
+	},
+	Injury: {
+		Type:          Injury,
+		Severity:      SeveritySevere,
+		Frequency:     0.20,
+		LifespanScale: 150,
+		LifespanShape: 1.2,
+		TreatmentTime: 15,
+		Equipment:     []Equipment{FirstAidKit, Stretcher},
+		Kit:           FirstAidKit,
+		KitExtraTicks: 90,
+	},
+	CardiacArrest: {
+		Type:          CardiacArrest,
+		Severity:      SeverityCritical,
+		Frequency:     0.05,
+		LifespanScale: 30,
+		LifespanShape: 3.0,
+		TreatmentTime: 20,
+		Equipment:     []Equipment{Defibrillator, Stretcher},
+		Kit:           Defibrillator,
+		KitExtraTicks: 45,
+	},
+}
+
+// DistressTypes lists the incident types in a stable order.
+var DistressTypes = []DistressType{Dehydration, Intoxication, Injury, CardiacArrest}
+
+// Severities lists the severity levels of real incidents, from the least to the most urgent.
+var Severities = []Severity{SeverityLow, SeverityModerate, SeveritySevere, SeverityCritical}
+
+// RandomDistressType draws an incident type, weights scale the base frequency of each type.
+// A nil weights map uses the base frequencies.
+func RandomDistressType(weights map[DistressType]float64) DistressType {
+	total := 0.0
+	scaled := make([]float64, len(DistressTypes))
+	for i, t := range DistressTypes {
+		w := DistressProfiles[t].Frequency
+		if factor, ok := weights[t]; ok {
+			w *= factor
+		}
+		scaled[i] = w
+		total += w
+	}
+
+	r := rand.Float64() * total
+	for i, t := range DistressTypes {
+		if r < scaled[i] {
+			return t
+		}
+		r -= scaled[i]
+	}
+	return DistressTypes[len(DistressTypes)-1]
+}
+
+// SampleLifespan draws the number of ticks an untreated case survives.
+func (p DistressProfile) SampleLifespan() int {
+	u := 1 - rand.Float64()
+	lifespan := p.LifespanScale * math.Pow(-math.Log(u), 1/p.LifespanShape)
+	return max(1, int(math.Round(lifespan)))
+}
+
+func (t DistressType) String() string {
+	switch t {
+	case Dehydration:
+		return "Dehydration"
+	case Intoxication:
+		return "Intoxication"
+	case Injury:
+		return "Injury"
+	case CardiacArrest:
+		return "Cardiac arrest"
+	default:
+		return "None"
+	}
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "Low"
+	case SeverityModerate:
+		return "Moderate"
+	case SeveritySevere:
+		return "Severe"
+	case SeverityCritical:
+		return "Critical"
+	default:
+		return "None"
+	}
+}
+
+func (e Equipment) String() string {
+	switch e {
+	case FirstAidKit:
+		return "First aid kit"
+	case IVFluids:
+		return "IV fluids"
+	case Stretcher:
+		return "Stretcher"
+	case Defibrillator:
+		return "Defibrillator"
+	default:
+		return "Unknown"
+	}
+}
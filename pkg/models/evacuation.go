@@ -0,0 +1,32 @@
+package models
+
+// EmergencyExit is an exit used during an evacuation, Capacity is the number of people it lets out per tick.
+type EmergencyExit struct {
+	Name     string
+	Position Position
+	Capacity int
+}
+
+// HazardArea is a disc that people must not cross during an evacuation (fire, collapsed stage...).
+type HazardArea struct {
+	Center Position
+	Radius float64
+}
+
+func (h *HazardArea) Contains(pos Position) bool {
+	return h != nil && pos.CalculateDistance(h.Center) <= h.Radius
+}
+
+// EvacuationScenario triggers an evacuation at a given tick.
+type EvacuationScenario struct {
+	Name        string
+	TriggerTick int
+	Hazard      *HazardArea
+	Exits       []EmergencyExit // Optional, overrides the emergency exits of the map
+}
+
+// EvacuationOrder is what the drones know about a running evacuation.
+type EvacuationOrder struct {
+	Exits  []EmergencyExit
+	Hazard *HazardArea
+}
@@ -0,0 +1,41 @@
+package models
+
+type FaultType string
+
+const (
+	FaultCrash              FaultType = "crash"     // The drone falls, for good
+	FaultSensor             FaultType = "sensor"    // The drone sees nobody
+	FaultRadio              FaultType = "radio"     // The drone reaches neither the other drones nor the rescue points
+	FaultGPSDrift           FaultType = "gps_drift" // The position estimate of the drone slides away from its true position
+	FaultRescuePointOffline FaultType = "rescue_point_offline"
+)
+
+// Fault is a failure planned at a given tick.
+type Fault struct {
+	Type        FaultType
+	Tick        int
+	Duration    int     // Ticks, the default duration of the scenario if 0. A crash is permanent
+	Drones      []int   // IDs of the drones hit
+	RescuePoint int     // ID of the rescue point going offline
+	Drift       float64 // Cells per tick of a GPS drift
+}
+
+// FaultRates draws random failures: each flying drone, or each rescue point, fails with the
+// given probability at every tick.
+type FaultRates struct {
+	Crash              float64
+	Sensor             float64
+	Radio              float64
+	GPSDrift           float64
+	RescuePointOffline float64
+	Duration           int     // Ticks of a random failure, the default duration of the scenario if 0
+	Drift              float64 // Cells per tick of a random GPS drift
+}
+
+// FaultScenario plans failures, at given ticks or at random.
+type FaultScenario struct {
+	Name     string
+	Duration int // Default duration of a failure
+	Faults   []Fault
+	Rates    FaultRates
+}
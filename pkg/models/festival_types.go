@@ -30,10 +30,17 @@ type ZoneConfig struct {
 }
 
 type FestivalConfig struct {
-	MapWidth     int
-	MapHeight    int
-	Zones        []ZoneConfig
-	POILocations []POILocation
+	MapWidth        int
+	MapHeight       int
+	Zones           []ZoneConfig
+	POILocations    []POILocation
+	Weather         []WeatherSample // Optional, overrides the weather scenario
+	Attendance      *AttendanceProfile
+	Gates           []Position // Entrance gates, people spawn on the left edge if empty
+	EmergencyExits  []EmergencyExit
+	CrowdThresholds *CrowdThresholds // Optional, overrides the default crowd risk thresholds
+	Protocols       string           // Optional, comma-separated drone protocols given to the fleet in turn
+	StartHour       *float64         // Optional, hour of the first tick, FESTIVAL_START_HOUR of the simulation if nil
 }
 
 type POILocation struct {
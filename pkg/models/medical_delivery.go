@@ -3,6 +3,8 @@ package models
 type MedicalDeliveryRequest struct {
 	PersonID     int
 	DroneID      int
+	Kit          Equipment
+	Release      bool // The drone gives the person up, another drone may bring a kit
 	ResponseChan chan MedicalDeliveryResponse
 }
 
@@ -4,6 +4,7 @@ type MovementRequest struct {
 	MemberID     int
 	MemberType   string
 	NewPosition  Position
+	Layer        int // Altitude layer of a drone after the move
 	ResponseChan chan MovementResponse
 }
 
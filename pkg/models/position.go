@@ -18,6 +18,11 @@ func (p Position) Round() Position {
 	}
 }
 
+// Cell returns the map cell holding a continuous position.
+func (p Position) Cell() Position {
+	return Position{X: math.Round(p.X), Y: math.Round(p.Y)}
+}
+
 func (p *Position) CalculateDistance(other Position) float64 {
 	return math.Sqrt(math.Pow(p.X-other.X, 2) + math.Pow(p.Y-other.Y, 2))
 }
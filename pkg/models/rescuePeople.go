@@ -5,10 +5,13 @@ type RescuePeopleRequest struct {
 	RescuerID     int
 	RescuePointID int
 	DroneSenderID int
+	DistressType  DistressType // Incident the rescuer was sent for, as estimated by the drone
+	Done          bool         // The treatment on site is over
 	ResponseChan  chan RescuePeopleResponse
 }
 
 type RescuePeopleResponse struct {
-	Authorized bool
-	Reason     string
+	Authorized   bool
+	Reason       string
+	DistressType DistressType // Incident found on site
 }
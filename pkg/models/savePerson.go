@@ -3,6 +3,7 @@ package models
 type SavePersonRequest struct {
 	PersonID     int
 	DroneID      int
+	Kit          Equipment
 	ResponseChan chan SavePersonResponse
 }
 
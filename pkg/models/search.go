@@ -0,0 +1,76 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+)
+
+var Clothings = []string{"red", "blue", "green", "yellow", "black", "white"}
+
+const CHILD_PROPORTION = 0.1
+
+// Appearance is what a drone can tell about a person from the air.
+type Appearance struct {
+	Child    bool
+	Clothing string
+}
+
+func RandomAppearance() Appearance {
+	return Appearance{
+		Child:    rand.Float64() < CHILD_PROPORTION,
+		Clothing: Clothings[rand.Intn(len(Clothings))],
+	}
+}
+
+// Sighting is a person matching the description of a search mission, seen by a drone.
+type Sighting struct {
+	DroneID   int
+	PersonID  int
+	Position  Position
+	Tick      int
+	Confirmed bool // The ground team recognised the missing person
+}
+
+// SearchMission is a missing person the drones are looking for.
+type SearchMission struct {
+	ID            int
+	RescuePointID int
+	PersonID      int
+	Description   Appearance
+	LastSeen      Position
+	LostTick      int // Tick at which the person got lost
+	ReportedTick  int // Tick at which the rescue point was told
+	Sightings     []Sighting
+	Found         bool
+	FoundTick     int
+	FoundBy       int // Drone ID
+	Cancelled     bool // The person left the festival or died before being found
+}
+
+func (m *SearchMission) Open() bool {
+	return !m.Found && !m.Cancelled
+}
+
+// TimeToFind returns the number of ticks between the report and the confirmed sighting.
+func (m *SearchMission) TimeToFind() int {
+	return m.FoundTick - m.ReportedTick
+}
+
+// ExpandingSquare returns the waypoints of an expanding square search around center,
+// legs grow by spacing every two turns. rotation (0 to 3) turns the pattern by quarters
+// so that several drones do not fly the same legs.
+func ExpandingSquare(center Position, spacing float64, legs int, rotation int, width int, height int) []Position {
+	directions := []Position{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+	waypoints := []Position{center}
+	current := center
+	for leg := 0; leg < legs; leg++ {
+		dir := directions[(leg+rotation)%len(directions)]
+		length := spacing * float64(leg/2+1)
+		current = Position{X: current.X + dir.X*length, Y: current.Y + dir.Y*length}
+		waypoints = append(waypoints, Position{
+			X: math.Max(0, math.Min(float64(width-1), math.Round(current.X))),
+			Y: math.Max(0, math.Min(float64(height-1), math.Round(current.Y))),
+		})
+	}
+	return waypoints
+}
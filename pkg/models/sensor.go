@@ -0,0 +1,79 @@
+package models
+
+import "math"
+
+// SensorModel describes the camera of the drones: how well it detects people, and how well
+// it tells a person in distress from a person who is fine, by day and by night.
+type SensorModel struct {
+	Name               string  `json:"name"`
+	Detection          float64 `json:"detection"`          // Chance to detect a person right under the drone
+	RangeFalloff       float64 `json:"rangeFalloff"`       // Share of Detection lost at the edge of the see range
+	OcclusionLoss      float64 `json:"occlusionLoss"`      // Share of Detection lost per person/m² around the person
+	TruePositive       float64 `json:"truePositive"`       // Chance to read a person in distress as in distress
+	FalsePositive      float64 `json:"falsePositive"`      // Chance to read a person who is fine as in distress
+	NightDetection     float64 `json:"nightDetection"`     // Detection factor in the dark
+	NightTruePositive  float64 `json:"nightTruePositive"`  // TruePositive in the dark
+	NightFalsePositive float64 `json:"nightFalsePositive"` // FalsePositive in the dark
+}
+
+// DefaultSensorModel is a visible-light camera: good by day, half blind at night.
+var DefaultSensorModel = SensorModel{
+	Name:               "default",
+	Detection:          0.9,
+	RangeFalloff:       0.6,
+	OcclusionLoss:      0.1,
+	TruePositive:       0.85,
+	FalsePositive:      0.002,
+	NightDetection:     0.5,
+	NightTruePositive:  0.6,
+	NightFalsePositive: 0.006,
+}
+
+// DetectionChance returns the chance to detect a person at dist cells, in a crowd of density
+// persons/m², with daylight between 0 (night) and 1 (day).
+func (m SensorModel) DetectionChance(dist, seeRange, density, daylight float64) float64 {
+	chance := m.Detection
+	if seeRange > 0 {
+		chance *= 1 - m.RangeFalloff*math.Min(1, dist/seeRange)
+	}
+	chance *= math.Max(0, 1-m.OcclusionLoss*density)
+	chance *= daylight + (1-daylight)*m.NightDetection
+	return math.Max(0, math.Min(1, chance))
+}
+
+// DistressChance returns the chance to read a detected person as in distress.
+func (m SensorModel) DistressChance(inDistress bool, daylight float64) float64 {
+	if inDistress {
+		return daylight*m.TruePositive + (1-daylight)*m.NightTruePositive
+	}
+	return daylight*m.FalsePositive + (1-daylight)*m.NightFalsePositive
+}
+
+// DISTRESS_PRIOR is the share of a festival crowd in distress, the confidence of a read starts from it.
+const DISTRESS_PRIOR = 0.02
+
+// Confidence returns the chance that a person is in distress after a read, from DISTRESS_PRIOR.
+func (m SensorModel) Confidence(read bool, daylight float64) float64 {
+	inDistress := m.DistressChance(true, daylight)
+	fine := m.DistressChance(false, daylight)
+	if !read {
+		inDistress, fine = 1-inDistress, 1-fine
+	}
+	if inDistress+fine == 0 {
+		return DISTRESS_PRIOR
+	}
+	return inDistress * DISTRESS_PRIOR / (inDistress*DISTRESS_PRIOR + fine*(1-DISTRESS_PRIOR))
+}
+
+// CombineConfidence merges the confidences of two independent reads of the same person.
+func CombineConfidence(a, b float64) float64 {
+	if a >= 1 || b >= 1 {
+		return 1
+	}
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	priorOdds := DISTRESS_PRIOR / (1 - DISTRESS_PRIOR)
+	odds := a / (1 - a) * b / (1 - b) / priorOdds
+	return odds / (1 + odds)
+}
@@ -0,0 +1,48 @@
+package models
+
+import "math"
+
+type WeatherConditions struct {
+	Temperature float64 // °C
+	Humidity    float64 // 0.0 to 1.0
+	Rain        float64 // mm/h
+	Wind        float64 // m/s
+}
+
+// WeatherSample is the weather observed at a given tick, values in between are interpolated.
+type WeatherSample struct {
+	Tick int
+	WeatherConditions
+}
+
+type WeatherConfig struct {
+	Name    string
+	Samples []WeatherSample
+}
+
+// DefaultWeather is a mild summer afternoon, used when a festival has no weather series.
+var DefaultWeather = WeatherConditions{
+	Temperature: 24,
+	Humidity:    0.5,
+	Rain:        0,
+	Wind:        3,
+}
+
+// HeatStress returns 0 in comfortable conditions and grows with temperature and humidity
+// (about 1.0 at 35°C and 50% humidity).
+func (w WeatherConditions) HeatStress() float64 {
+	if w.Temperature <= 25 {
+		return 0
+	}
+	return (w.Temperature - 25) / 10 * (0.5 + w.Humidity)
+}
+
+// RainSlowdown returns the probability that a walking person does not move during a tick.
+func (w WeatherConditions) RainSlowdown() float64 {
+	return math.Min(0.5, w.Rain/20)
+}
+
+// VisibilityFactor scales the drone detection probability, heavy rain hides people.
+func (w WeatherConditions) VisibilityFactor() float64 {
+	return 1 - math.Min(0.6, w.Rain/25)
+}
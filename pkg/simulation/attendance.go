@@ -0,0 +1,245 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"encoding/csv"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const MIN_STAY_TICKS = 60
+
+// DefaultAttendance is used when the festival config has no attendance profile:
+// most people arrive during the first two hours and leave at the end of the festival.
+var DefaultAttendance = models.AttendanceProfile{
+	AttendanceCurves: models.AttendanceCurves{
+		Arrivals: []models.RatePeriod{
+			{StartTick: 0, EndTick: 30, Rate: 3},
+			{StartTick: 30, EndTick: 120, Rate: 2},
+			{StartTick: 120, EndTick: 250, Rate: 0.5},
+		},
+		Departures: []models.RatePeriod{
+			{StartTick: 300, EndTick: 450, Rate: 0.3},
+			{StartTick: 450, EndTick: FESTIVALTICKS, Rate: 3},
+		},
+	},
+	ByPattern: map[int]models.AttendanceCurves{
+		int(persons.LateArrival): {
+			Arrivals: []models.RatePeriod{{StartTick: 150, EndTick: 300, Rate: 1}},
+		},
+		int(persons.EarlyExiter): {
+			Departures: []models.RatePeriod{{StartTick: 200, EndTick: 350, Rate: 1}},
+		},
+	},
+}
+
+// LoadTicketScans reads a CSV of ticket scans, the first column of each row is the scan tick.
+// Rows that do not start with a number (like a header) are skipped.
+func LoadTicketScans(scansPath string) ([]int, error) {
+	absPath, err := filepath.Abs(scansPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path: %v", err)
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ticket scans file: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ticket scans file: %v", err)
+	}
+
+	scans := make([]int, 0, len(records))
+	for _, record := range records {
+		if len(record) == 0 {
+			continue
+		}
+		tick, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil {
+			continue
+		}
+		scans = append(scans, tick)
+	}
+	if len(scans) == 0 {
+		return nil, fmt.Errorf("ticket scans file has no scans")
+	}
+
+	return scans, nil
+}
+
+func (s *Simulation) attendanceProfile() models.AttendanceProfile {
+	if s.FestivalConfig != nil && s.FestivalConfig.Attendance != nil {
+		return *s.FestivalConfig.Attendance
+	}
+	return DefaultAttendance
+}
+
+// scanQueue hands out the ticket scans in a random order, one scan per person.
+type scanQueue struct {
+	ticks []int
+	next  int
+}
+
+// attendanceScans returns the ticket scans of the profile shuffled once, nil without scans.
+func attendanceScans(profile models.AttendanceProfile) *scanQueue {
+	if profile.TicketScans == "" {
+		return nil
+	}
+	scans, err := LoadTicketScans(profile.TicketScans)
+	if err != nil {
+		fmt.Printf("Warning: Could not load ticket scans from %s: %v\n", profile.TicketScans, err)
+		return nil
+	}
+	rand.Shuffle(len(scans), func(i, j int) {
+		scans[i], scans[j] = scans[j], scans[i]
+	})
+	return &scanQueue{ticks: scans}
+}
+
+// skip passes the scans of n people scheduled before.
+func (q *scanQueue) skip(n int) {
+	if q != nil {
+		q.next += n
+	}
+}
+
+// take returns the next scan, false once every scan is used: the arrival curves take over.
+func (q *scanQueue) take() (int, bool) {
+	if q == nil || q.next >= len(q.ticks) {
+		return 0, false
+	}
+	tick := q.ticks[q.next]
+	q.next++
+	return tick, true
+}
+
+// scheduleAttendance draws the arrival and departure ticks of the people who have not arrived yet.
+func (s *Simulation) scheduleAttendance() {
+	profile := s.attendanceProfile()
+	scans := attendanceScans(profile)
+	for i := range s.Persons {
+		if s.Persons[i].Arrived {
+			// Les festivaliers déjà entrés ont utilisé leur scan
+			scans.skip(1)
+		}
+	}
+
+	for i := range s.Persons {
+		if !s.Persons[i].Arrived {
+			s.schedulePerson(&s.Persons[i], profile, scans)
+		}
+	}
+}
+
+// schedulePerson draws the arrival and departure ticks of the person. The ticket scans come before the
+// arrival curves, those of ByPattern included, until they run out.
+func (s *Simulation) schedulePerson(p *persons.Person, profile models.AttendanceProfile, scans *scanQueue) {
+	curves := profile.AttendanceCurves
+	if override, ok := profile.ByPattern[int(p.MovementPattern)]; ok {
+		if len(override.Arrivals) > 0 {
+			curves.Arrivals = override.Arrivals
+		}
+		if len(override.Departures) > 0 {
+			curves.Departures = override.Departures
+		}
+	}
+
+	arrival, ok := scans.take()
+	if !ok {
+		arrival, ok = models.SampleTick(curves.Arrivals)
+	}
+	if !ok {
+		arrival = 0
+	}
+	arrival = max(0, min(arrival, s.festivalTotalTicks-1))
+
+	departure, ok := models.SampleTick(curves.Departures)
+	if !ok {
+		departure = s.festivalTotalTicks
+	}
+	departure = max(departure, arrival+MIN_STAY_TICKS)
+
+	p.ArrivalTick = arrival
+	p.DepartureTick = departure
+	// La personne se rapproche de la sortie avant son départ
+	p.ZonePreference.ExitTime = departure - arrival
+}
+
+// admitArrivals lets in the people whose arrival tick has come.
+func (s *Simulation) admitArrivals() {
+	for i := range s.Persons {
+		p := &s.Persons[i]
+		if !p.Arrived && p.StillInSim && p.ArrivalTick <= s.currentTick {
+			p.Position = s.randomGate()
+			p.EntryTick = s.currentTick
+			p.Arrived = true
+			s.Map.AddCrowdMember(p)
+		}
+	}
+}
+
+// sendDepartures sends to the exit the people whose departure tick has come.
+func (s *Simulation) sendDepartures() {
+	for i := range s.Persons {
+		p := &s.Persons[i]
+		if p.Arrived && p.StillInSim && !p.SeekingExit && !p.InDistress && !p.Lost && p.DepartureTick <= s.currentTick {
+			s.sendToExit(p)
+		}
+	}
+}
+
+func (s *Simulation) sendToExit(p *persons.Person) {
+	if p.ExitTarget != nil {
+		// Déjà évacué vers une sortie de secours
+		return
+	}
+	p.CurrentPath = models.FindPath(p.Position, models.Position{
+		X: (float64(s.Map.Width)/10)*9 + 0.1,
+		Y: p.Position.Y},
+		s.Map.Width,
+		s.Map.Height,
+		make(map[models.Position]bool))
+	p.SeekingExit = true
+}
+
+// randomGate returns the position of a random entrance gate of the festival config.
+// Gates out of the map or blocked are ignored, without gates people enter from the left edge.
+func (s *Simulation) randomGate() models.Position {
+	gates := make([]models.Position, 0)
+	if s.FestivalConfig != nil {
+		for _, gate := range s.FestivalConfig.Gates {
+			if gate.X < 0 || gate.Y < 0 || gate.X >= float64(s.Map.Width) || gate.Y >= float64(s.Map.Height) {
+				continue
+			}
+			if s.Map.IsBlocked(gate) {
+				continue
+			}
+			gates = append(gates, gate)
+		}
+	}
+	if len(gates) == 0 {
+		return models.Position{X: 0, Y: float64(rand.Intn(s.Map.Height))}
+	}
+	return gates[rand.Intn(len(gates))]
+}
+
+// CountPresentPeople returns the number of people currently inside the festival.
+func (s *Simulation) CountPresentPeople() int {
+	count := 0
+	for i := range s.Persons {
+		if s.Persons[i].Arrived && s.Persons[i].StillInSim {
+			count++
+		}
+	}
+	return count
+}
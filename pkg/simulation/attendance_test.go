@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"sort"
+	"testing"
+)
+
+func TestScanQueueTake(t *testing.T) {
+	cases := []struct {
+		name    string
+		queue   *scanQueue
+		skipped int
+		want    []int // Scans taken until the queue runs out
+	}{
+		{"no scans", nil, 0, nil},
+		{"in order", &scanQueue{ticks: []int{30, 10, 20}}, 0, []int{30, 10, 20}},
+		{"scans of the people scheduled before", &scanQueue{ticks: []int{30, 10, 20}}, 2, []int{20}},
+		{"all used", &scanQueue{ticks: []int{30, 10, 20}}, 5, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.queue.skip(c.skipped)
+			var got []int
+			for tick, ok := c.queue.take(); ok; tick, ok = c.queue.take() {
+				got = append(got, tick)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("take() = %v, want %v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("take() = %v, want %v", got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulePersonScans(t *testing.T) {
+	late := models.AttendanceCurves{Arrivals: []models.RatePeriod{{StartTick: 150, EndTick: 300, Rate: 1}}}
+	profile := models.AttendanceProfile{
+		AttendanceCurves: models.AttendanceCurves{Arrivals: []models.RatePeriod{{StartTick: 0, EndTick: 100, Rate: 1}}},
+		ByPattern:        map[int]models.AttendanceCurves{int(persons.LateArrival): late},
+	}
+	cases := []struct {
+		name     string
+		pattern  persons.MovementPattern
+		scans    []int
+		people   int
+		wantScan []int // Arrivals of the first people, in any order, each scan used once
+		minCurve int   // Range of the arrival curve for the people after the scans
+		maxCurve int
+	}{
+		{"each scan once", persons.MainEventFocused, []int{40, 10, 40, 25}, 4, []int{10, 25, 40, 40}, 0, 0},
+		{"curve once the scans run out", persons.MainEventFocused, []int{40, 10}, 5, []int{10, 40}, 0, 99},
+		{"scans before ByPattern", persons.LateArrival, []int{40, 10}, 4, []int{10, 40}, 150, 299},
+		{"ByPattern without scans", persons.LateArrival, nil, 3, nil, 150, 299},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := &Simulation{festivalTotalTicks: FESTIVALTICKS}
+			var scans *scanQueue
+			if c.scans != nil {
+				scans = &scanQueue{ticks: append([]int(nil), c.scans...)}
+			}
+			arrivals := make([]int, c.people)
+			for i := range arrivals {
+				p := &persons.Person{ID: i, MovementPattern: c.pattern}
+				s.schedulePerson(p, profile, scans)
+				arrivals[i] = p.ArrivalTick
+			}
+			scanned := append([]int(nil), arrivals[:len(c.wantScan)]...)
+			sort.Ints(scanned)
+			for i := range scanned {
+				if scanned[i] != c.wantScan[i] {
+					t.Fatalf("scanned arrivals = %v, want %v", scanned, c.wantScan)
+				}
+			}
+			for _, arrival := range arrivals[len(c.wantScan):] {
+				if arrival < c.minCurve || arrival > c.maxCurve {
+					t.Fatalf("arrival %d off the curve [%d, %d]", arrival, c.minCurve, c.maxCurve)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+)
+
+// BusStats counts the messages of the drones by type, and their handovers and reports.
+type BusStats struct {
+	Messages       map[bus.MessageType]int // Sent over the radio
+	Handovers      int                     // Handover requests between drones
+	HandoversAcked int
+	Reports        int // Incident reports to the rescue points
+	ReportsAcked   int
+	Expired        int // Handovers and reports without ack in time, sent again
+	AckTicks       int // Summed over the acks
+}
+
+// AverageAck returns the ticks between a handover or a report and its ack.
+func (b BusStats) AverageAck() float64 {
+	acked := b.HandoversAcked + b.ReportsAcked
+	if acked == 0 {
+		return 0
+	}
+	return float64(b.AckTicks) / float64(acked)
+}
+
+// readRescuePointInboxes lets the rescue points handle the reports of the last tick,
+// an offline rescue point loses them. The people taken in charge are recorded for the dispatch delay.
+func (s *Simulation) readRescuePointInboxes() {
+	for _, rp := range s.RescuePoints {
+		if s.rescuePointOffline(rp) {
+			rp.DropInbox()
+			continue
+		}
+		s.recordDispatches(rp.ReadInbox(s.currentTick))
+	}
+}
+
+func (s *Simulation) collectBusStats() {
+	s.Radio.mu.Lock()
+	defer s.Radio.mu.Unlock()
+	s.BusStats.Handovers, s.BusStats.HandoversAcked = 0, 0
+	s.BusStats.Reports, s.BusStats.ReportsAcked = 0, 0
+	s.BusStats.Expired, s.BusStats.AckTicks = 0, 0
+	for i := range s.Drones {
+		counters := s.Drones[i].Bus
+		s.BusStats.Handovers += counters.Handovers
+		s.BusStats.HandoversAcked += counters.HandoversAcked
+		s.BusStats.Reports += counters.Reports
+		s.BusStats.ReportsAcked += counters.ReportsAcked
+		s.BusStats.Expired += counters.Expired
+		s.BusStats.AckTicks += counters.AckTicks
+	}
+}
@@ -0,0 +1,350 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	DEFAULT_CHARGING_PADS = 1
+	RESERVATION_HOLD      = 2  // Ticks before its expected arrival from which a reserved pad is held
+	RESERVATION_TIMEOUT   = 10 // Ticks after its expected arrival after which a reservation is dropped
+	SWAP_TICKS            = 2  // Ticks on the ground to swap a battery
+)
+
+// ChargingStats summarizes the use of the charging stations over a run.
+type ChargingStats struct {
+	Charges      int
+	Swaps        int   // Charges made by swapping the battery
+	PadTicks     int   // Pads available, summed over the ticks
+	BusyPadTicks int   // Pads in use, summed over the ticks
+	WaitTicks    int   // Drone-ticks spent landed in a queue
+	Waits        []int // Ticks waited by each drone before getting a pad
+	MaxQueue     int
+}
+
+// Utilisation returns the share of pad-ticks spent charging a drone.
+func (c ChargingStats) Utilisation() float64 {
+	if c.PadTicks == 0 {
+		return 0
+	}
+	return float64(c.BusyPadTicks) / float64(c.PadTicks)
+}
+
+// AverageWait returns the ticks waited before getting a pad, averaged over the charges.
+func (c ChargingStats) AverageWait() float64 {
+	if len(c.Waits) == 0 {
+		return 0
+	}
+	total := 0
+	for _, wait := range c.Waits {
+		total += wait
+	}
+	return float64(total) / float64(len(c.Waits))
+}
+
+// chargingSlot is a drone waiting for a pad, or a reservation of a drone still flying.
+type chargingSlot struct {
+	DroneID     int
+	Since       int // Tick of the reservation or of the arrival, orders the queue
+	ETA         int // Expected arrival tick
+	Present     bool
+	ArrivalTick int
+	ChargeTicks int
+}
+
+// ChargingStation shares its pads between the drones, first come first served.
+// Reservations take their place in the queue when they are made. In hot-swap mode a pad
+// swaps the battery of the drone for a charged spare, and the chargers charge the spares.
+type ChargingStation struct {
+	Position models.Position
+	Pads     int
+	OnPads   map[int]int // Drone ID -> expected end of its charge
+	Queue    []chargingSlot
+	HotSwap  bool
+	Spares   []spareBattery
+}
+
+// spareBattery is a spare of a drone type, charged like the batteries of that type.
+type spareBattery struct {
+	DroneType string
+	Energy    drones.EnergyModel
+	Battery   float64
+}
+
+func NewChargingStation(position models.Position, pads int) *ChargingStation {
+	if pads <= 0 {
+		pads = DEFAULT_CHARGING_PADS
+	}
+	return &ChargingStation{
+		Position: position,
+		Pads:     pads,
+		OnPads:   make(map[int]int),
+		Queue:    make([]chargingSlot, 0),
+	}
+}
+
+func (cs *ChargingStation) slot(droneID int) int {
+	for i, slot := range cs.Queue {
+		if slot.DroneID == droneID {
+			return i
+		}
+	}
+	return -1
+}
+
+// holdsPad tells if a slot keeps a pad at the given tick: the drone is there, or its reservation is due.
+func (slot chargingSlot) holdsPad(tick int) bool {
+	return slot.Present || slot.ETA <= tick+RESERVATION_HOLD
+}
+
+// ask gives a pad to a drone standing on the station, or queues it.
+func (cs *ChargingStation) ask(req models.ChargingRequest, tick int, stats *ChargingStats) models.ChargingResponse {
+	if _, charging := cs.OnPads[req.DroneID]; charging {
+		return models.ChargingResponse{Authorized: true, Reason: "Already on a pad"}
+	}
+
+	i := cs.slot(req.DroneID)
+	if i < 0 {
+		cs.Queue = append(cs.Queue, chargingSlot{DroneID: req.DroneID, Since: tick, ETA: tick})
+		i = len(cs.Queue) - 1
+	}
+	if !cs.Queue[i].Present {
+		cs.Queue[i].Present = true
+		cs.Queue[i].ArrivalTick = tick
+	}
+	cs.Queue[i].ChargeTicks = req.ChargeTicks
+
+	ahead := 0
+	for _, slot := range cs.Queue[:i] {
+		if slot.holdsPad(tick) {
+			ahead++
+		}
+	}
+	// Un type de drone sans batterie de réserve à la station recharge sur le pad
+	hotSwap := cs.HotSwap && cs.hasSpares(req.DroneType)
+	available := cs.Pads - len(cs.OnPads)
+	if hotSwap {
+		available = min(available, cs.readySpares(req.DroneType))
+	}
+	if ahead >= available {
+		return models.ChargingResponse{
+			Authorized:    false,
+			Reason:        "All pads are taken",
+			QueuePosition: ahead - available + 1,
+		}
+	}
+
+	stats.Charges++
+	stats.Waits = append(stats.Waits, tick-cs.Queue[i].ArrivalTick)
+	cs.Queue = append(cs.Queue[:i], cs.Queue[i+1:]...)
+	if !hotSwap {
+		cs.OnPads[req.DroneID] = tick + req.ChargeTicks
+		return models.ChargingResponse{Authorized: true, Reason: "Charging pad available"}
+	}
+
+	// Échange contre la batterie de réserve la plus chargée du type du drone
+	best := -1
+	for j, spare := range cs.Spares {
+		if spare.DroneType == req.DroneType && (best < 0 || spare.Battery > cs.Spares[best].Battery) {
+			best = j
+		}
+	}
+	spare := cs.Spares[best].Battery
+	cs.Spares[best].Battery = req.Battery
+	cs.OnPads[req.DroneID] = tick + SWAP_TICKS
+	stats.Swaps++
+	return models.ChargingResponse{Authorized: true, Reason: "Battery swapped", Swapped: true, Battery: spare, SwapTicks: SWAP_TICKS}
+}
+
+// hasSpares tells if the station keeps spare batteries for the drone type.
+func (cs *ChargingStation) hasSpares(droneType string) bool {
+	for _, spare := range cs.Spares {
+		if spare.DroneType == droneType {
+			return true
+		}
+	}
+	return false
+}
+
+// readySpares counts the spare batteries of the drone type charged enough to be swapped.
+func (cs *ChargingStation) readySpares(droneType string) int {
+	ready := 0
+	for _, spare := range cs.Spares {
+		if spare.DroneType == droneType && spare.Battery >= drones.CHARGE_TARGET {
+			ready++
+		}
+	}
+	return ready
+}
+
+// chargeSpares charges for a tick of tickSeconds the spare batteries closest to ready, one per pad,
+// each with the energy model of its drone type.
+func (cs *ChargingStation) chargeSpares(tickSeconds float64) {
+	charging := make([]int, 0, len(cs.Spares))
+	for j, spare := range cs.Spares {
+		if spare.Battery < 100 {
+			charging = append(charging, j)
+		}
+	}
+	sort.Slice(charging, func(a, b int) bool { return cs.Spares[charging[a]].Battery > cs.Spares[charging[b]].Battery })
+	for _, j := range charging[:min(cs.Pads, len(charging))] {
+		cs.Spares[j].Battery = cs.Spares[j].Energy.ChargeStep(cs.Spares[j].Battery, tickSeconds)
+	}
+}
+
+// reserve books a pad for a drone flying to the station.
+func (cs *ChargingStation) reserve(req models.ChargingRequest, tick int) models.ChargingResponse {
+	if i := cs.slot(req.DroneID); i >= 0 {
+		cs.Queue[i].ETA = req.ArrivalTick
+		cs.Queue[i].ChargeTicks = req.ChargeTicks
+	} else {
+		cs.Queue = append(cs.Queue, chargingSlot{DroneID: req.DroneID, Since: tick, ETA: req.ArrivalTick, ChargeTicks: req.ChargeTicks})
+	}
+	return models.ChargingResponse{Authorized: true, Reason: "Pad reserved"}
+}
+
+func (cs *ChargingStation) release(droneID int) {
+	delete(cs.OnPads, droneID)
+	if i := cs.slot(droneID); i >= 0 {
+		cs.Queue = append(cs.Queue[:i], cs.Queue[i+1:]...)
+	}
+}
+
+// dropExpiredReservations forgets the drones that never came.
+func (cs *ChargingStation) dropExpiredReservations(tick int) {
+	kept := cs.Queue[:0]
+	for _, slot := range cs.Queue {
+		if slot.Present || tick <= slot.ETA+RESERVATION_TIMEOUT {
+			kept = append(kept, slot)
+		}
+	}
+	cs.Queue = kept
+}
+
+// expectedWait estimates the ticks a drone arriving at arrivalTick would wait for a pad,
+// by giving the pads in turn to the drones charging, queued, or expected before it.
+func (cs *ChargingStation) expectedWait(droneID, tick, arrivalTick int) int {
+	padsFree := make([]int, 0, cs.Pads)
+	for id, end := range cs.OnPads {
+		if id != droneID {
+			padsFree = append(padsFree, max(end, tick))
+		}
+	}
+	for len(padsFree) < cs.Pads {
+		padsFree = append(padsFree, tick)
+	}
+	sort.Ints(padsFree)
+
+	for _, slot := range cs.Queue {
+		if slot.DroneID == droneID {
+			// Les drones derrière dans la file ne passent pas devant
+			break
+		}
+		if !slot.Present && slot.ETA > arrivalTick {
+			continue
+		}
+		duration := slot.ChargeTicks
+		if cs.HotSwap {
+			duration = SWAP_TICKS
+		}
+		start := max(padsFree[0], slot.ETA)
+		padsFree[0] = start + duration
+		sort.Ints(padsFree)
+	}
+	return max(0, padsFree[0]-arrivalTick)
+}
+
+// InitializeChargingStations gives each charging station of the map its number of pads,
+// from the capacity of the POI, and in hot-swap mode its spare batteries for each drone type.
+func (s *Simulation) InitializeChargingStations() {
+	s.chargingMu.Lock()
+	defer s.chargingMu.Unlock()
+
+	s.ChargingStations = make(map[models.Position]*ChargingStation)
+	for _, pos := range s.poiMap[models.ChargingStation] {
+		station := NewChargingStation(pos, s.getPOICapacity(pos))
+		if s.FleetSchedule.HotSwap {
+			station.HotSwap = true
+			spares := s.FleetSchedule.SpareBatteries
+			if spares <= 0 {
+				spares = station.Pads
+			}
+			for _, droneType := range s.fleetTypes() {
+				for j := 0; j < spares; j++ {
+					station.Spares = append(station.Spares, spareBattery{DroneType: droneType.Name, Energy: droneType.Energy, Battery: 100})
+				}
+			}
+		}
+		s.ChargingStations[pos] = station
+	}
+}
+
+// UpdateChargingReservations allows the drones to book a pad before flying to a station.
+func (s *Simulation) UpdateChargingReservations(enabled bool) {
+	s.chargingMu.Lock()
+	defer s.chargingMu.Unlock()
+	s.ChargingReservations = enabled
+}
+
+func (s *Simulation) handleChargingRequests() {
+	for req := range s.ChargingChan {
+		s.chargingMu.Lock()
+		station, isChargingStation := s.ChargingStations[req.Position]
+		var response models.ChargingResponse
+		switch {
+		case !isChargingStation:
+			response = models.ChargingResponse{Authorized: false, Reason: "Not at a charging station"}
+		case req.Action == models.ChargingAsk:
+			response = station.ask(req, s.currentTick, &s.ChargingStats)
+		case req.Action == models.ChargingReserve && s.ChargingReservations:
+			response = station.reserve(req, s.currentTick)
+		case req.Action == models.ChargingReserve:
+			response = models.ChargingResponse{Authorized: false, Reason: "Reservations disabled"}
+		case req.Action == models.ChargingRelease:
+			station.release(req.DroneID)
+			response = models.ChargingResponse{Authorized: true, Reason: "Released"}
+		}
+		s.chargingMu.Unlock()
+		if s.debug && response.QueuePosition > 0 {
+			fmt.Printf("[CHARGING] Drone %d queued at (%.0f, %.0f), position %d\n", req.DroneID, req.Position.X, req.Position.Y, response.QueuePosition)
+		}
+		req.ResponseChan <- response
+	}
+}
+
+// chargingWait is given to the drones to choose a station by expected wait plus travel time.
+func (s *Simulation) chargingWait(station models.Position, droneID, arrivalTick int) int {
+	s.chargingMu.Lock()
+	defer s.chargingMu.Unlock()
+	cs, exists := s.ChargingStations[station]
+	if !exists {
+		return math.MaxInt32
+	}
+	return cs.expectedWait(droneID, s.currentTick, arrivalTick)
+}
+
+// collectChargingStats counts the busy pads and the drones waiting, and drops the stale reservations.
+func (s *Simulation) collectChargingStats() {
+	s.chargingMu.Lock()
+	defer s.chargingMu.Unlock()
+	for _, station := range s.ChargingStations {
+		station.dropExpiredReservations(s.currentTick)
+		if station.HotSwap {
+			station.chargeSpares(s.tickSeconds)
+		}
+		s.ChargingStats.PadTicks += station.Pads
+		s.ChargingStats.BusyPadTicks += len(station.OnPads)
+		waiting := 0
+		for _, slot := range station.Queue {
+			if slot.Present {
+				waiting++
+			}
+		}
+		s.ChargingStats.WaitTicks += waiting
+		s.ChargingStats.MaxQueue = max(s.ChargingStats.MaxQueue, waiting)
+	}
+}
@@ -0,0 +1,101 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/models"
+	"testing"
+)
+
+const testTick = 100
+
+// newTestStation returns a station with the given drones charging until their end tick, and the given queue.
+func newTestStation(pads int, onPads map[int]int, queue []chargingSlot) *ChargingStation {
+	cs := NewChargingStation(models.Position{X: 0, Y: 0}, pads)
+	for id, end := range onPads {
+		cs.OnPads[id] = end
+	}
+	cs.Queue = append(cs.Queue, queue...)
+	return cs
+}
+
+func TestChargingStationAsk(t *testing.T) {
+	cases := []struct {
+		name         string
+		pads         int
+		onPads       map[int]int
+		queue        []chargingSlot
+		droneID      int
+		wantOK       bool
+		wantPosition int
+	}{
+		{"free pad", 1, nil, nil, 1, true, 0},
+		{"already on a pad", 1, map[int]int{1: testTick + 5}, nil, 1, true, 0},
+		{"pad taken", 1, map[int]int{1: testTick + 5}, nil, 2, false, 1},
+		{"behind a landed drone", 1, map[int]int{1: testTick + 5},
+			[]chargingSlot{{DroneID: 2, Since: testTick - 1, ETA: testTick - 1, Present: true}}, 3, false, 2},
+		{"second pad free", 2, map[int]int{1: testTick + 5}, nil, 2, true, 0},
+		{"reservation due holds the pad", 1, nil,
+			[]chargingSlot{{DroneID: 2, Since: testTick - 3, ETA: testTick + RESERVATION_HOLD}}, 3, false, 1},
+		{"reservation far off leaves the pad", 1, nil,
+			[]chargingSlot{{DroneID: 2, Since: testTick - 3, ETA: testTick + RESERVATION_HOLD + 1}}, 3, true, 0},
+		{"own reservation is served", 1, nil,
+			[]chargingSlot{{DroneID: 2, Since: testTick - 3, ETA: testTick}}, 2, true, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cs := newTestStation(c.pads, c.onPads, c.queue)
+			var stats ChargingStats
+			response := cs.ask(models.ChargingRequest{DroneID: c.droneID, ChargeTicks: 4}, testTick, &stats)
+			if response.Authorized != c.wantOK || response.QueuePosition != c.wantPosition {
+				t.Fatalf("ask() = authorized %v, position %d, want %v, %d", response.Authorized, response.QueuePosition, c.wantOK, c.wantPosition)
+			}
+			_, onPad := cs.OnPads[c.droneID]
+			if onPad != c.wantOK {
+				t.Fatalf("drone on a pad = %v, want %v", onPad, c.wantOK)
+			}
+			if queued := cs.slot(c.droneID) >= 0; queued == c.wantOK {
+				t.Fatalf("drone queued = %v, want %v", queued, !c.wantOK)
+			}
+		})
+	}
+}
+
+func TestChargingStationExpectedWait(t *testing.T) {
+	cases := []struct {
+		name        string
+		pads        int
+		onPads      map[int]int
+		queue       []chargingSlot
+		arrivalTick int
+		want        int
+	}{
+		{"empty station", 1, nil, nil, testTick + 3, 0},
+		{"charge ends before the arrival", 1, map[int]int{1: testTick + 2}, nil, testTick + 3, 0},
+		{"charge ends after the arrival", 1, map[int]int{1: testTick + 8}, nil, testTick + 3, 5},
+		{"landed drone charges first", 1, map[int]int{1: testTick + 8},
+			[]chargingSlot{{DroneID: 2, Since: testTick, ETA: testTick, Present: true, ChargeTicks: 4}}, testTick + 3, 9},
+		{"later reservation does not count", 1, map[int]int{1: testTick + 8},
+			[]chargingSlot{{DroneID: 2, Since: testTick, ETA: testTick + 20, ChargeTicks: 4}}, testTick + 3, 5},
+		{"two pads", 2, map[int]int{1: testTick + 8},
+			[]chargingSlot{{DroneID: 2, Since: testTick, ETA: testTick, Present: true, ChargeTicks: 4}}, testTick + 3, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cs := newTestStation(c.pads, c.onPads, c.queue)
+			if got := cs.expectedWait(9, testTick, c.arrivalTick); got != c.want {
+				t.Fatalf("expectedWait() = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestChargingStationDropExpiredReservations(t *testing.T) {
+	cs := newTestStation(1, nil, []chargingSlot{
+		{DroneID: 1, ETA: testTick - RESERVATION_TIMEOUT - 1},
+		{DroneID: 2, ETA: testTick - RESERVATION_TIMEOUT},
+		{DroneID: 3, ETA: testTick - RESERVATION_TIMEOUT - 1, Present: true},
+	})
+	cs.dropExpiredReservations(testTick)
+	if cs.slot(1) >= 0 || cs.slot(2) < 0 || cs.slot(3) < 0 {
+		t.Fatalf("queue after expiry = %+v, want drones 2 and 3", cs.Queue)
+	}
+}
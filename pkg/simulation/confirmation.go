@@ -0,0 +1,97 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/entities/persons"
+	"fmt"
+)
+
+// ConfirmationStats weighs the delay of confirming the uncertain reads against the missions they spare.
+type ConfirmationStats struct {
+	Suspicions      int // Reads held for a confirmation
+	Confirmed       int
+	Dismissed       int
+	PeerChecks      int // Confirmations asked to a neighbour
+	ConfirmTicks    int // Summed over the confirmed incidents
+	Dispatched      int // People taken in charge by a rescue point
+	FalseDispatches int // Of which were not in distress, the wasted missions
+	DispatchTicks   int // From the first distress read to the rescue point, summed over the people in distress
+	Timed           int // People in distress with a dispatch delay
+}
+
+// AverageConfirm returns the ticks to confirm an incident.
+func (c ConfirmationStats) AverageConfirm() float64 {
+	if c.Confirmed == 0 {
+		return 0
+	}
+	return float64(c.ConfirmTicks) / float64(c.Confirmed)
+}
+
+// AverageDispatch returns the ticks between the first read of a person in distress and the rescue point.
+func (c ConfirmationStats) AverageDispatch() float64 {
+	if c.Timed == 0 {
+		return 0
+	}
+	return float64(c.DispatchTicks) / float64(c.Timed)
+}
+
+// WastedShare returns the share of the missions sent to people who were fine.
+func (c ConfirmationStats) WastedShare() float64 {
+	if c.Dispatched == 0 {
+		return 0
+	}
+	return float64(c.FalseDispatches) / float64(c.Dispatched)
+}
+
+// UpdateConfirmationPolicy sets when the drones confirm a read before reporting it, a zero threshold reports every read.
+func (s *Simulation) UpdateConfirmationPolicy(policy drones.ConfirmationPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ConfirmationPolicy = policy
+	for i := range s.Drones {
+		s.Drones[i].Confirmation = policy
+	}
+	if policy.Enabled() {
+		fmt.Printf("Confirmation under %.0f%% of confidence, %d ticks, ask a peer: %t\n", policy.Threshold*100, policy.Ticks, policy.AskPeer)
+	}
+}
+
+// recordDispatches counts the people taken in charge and the delay since their first distress read.
+func (s *Simulation) recordDispatches(dispatched []*persons.Person) {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	for _, person := range dispatched {
+		s.ConfirmationStats.Dispatched++
+		if !person.IsInDistress() {
+			s.ConfirmationStats.FalseDispatches++
+			continue
+		}
+		if first, exists := s.firstReads[person.ID]; exists {
+			s.ConfirmationStats.DispatchTicks += s.currentTick - first
+			s.ConfirmationStats.Timed++
+			delete(s.firstReads, person.ID)
+		}
+	}
+}
+
+func (s *Simulation) collectConfirmationStats() {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	// Les personnes sorties de détresse sans secours ne comptent pas dans le délai
+	for personID := range s.firstReads {
+		if person := s.findPerson(personID); person == nil || !person.IsInDistress() {
+			delete(s.firstReads, personID)
+		}
+	}
+	stats := &s.ConfirmationStats
+	stats.Suspicions, stats.Confirmed, stats.Dismissed = 0, 0, 0
+	stats.PeerChecks, stats.ConfirmTicks = 0, 0
+	for i := range s.Drones {
+		counters := s.Drones[i].Confirmations
+		stats.Suspicions += counters.Suspicions
+		stats.Confirmed += counters.Confirmed
+		stats.Dismissed += counters.Dismissed
+		stats.PeerChecks += counters.PeerChecks
+		stats.ConfirmTicks += counters.ConfirmTicks
+	}
+}
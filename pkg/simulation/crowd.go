@@ -0,0 +1,141 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
+	"math"
+	"math/rand"
+)
+
+// CrowdRiskStats summarizes the crowd crush risk over a run.
+type CrowdRiskStats struct {
+	PeakDensity       float64 // persons/m²
+	PeakDensityTick   int
+	PeakPressure      float64 // 1/s²
+	CriticalCellTicks int     // Cumulated cell-ticks at the critical level
+	Alerts            int
+	CriticalAlerts    int
+}
+
+// updateCrowdField computes the density and pressure around every occupied cell,
+// velocities come from the displacement of each person since the previous tick.
+func (s *Simulation) updateCrowdField() {
+	type velocity struct{ x, y float64 }
+	counts := make(map[models.Position]int)
+	velocities := make(map[models.Position][]velocity)
+
+	for i := range s.Persons {
+		p := &s.Persons[i]
+		if !p.Arrived || !p.StillInSim || p.IsDead() || p.Position.X < 0 {
+			delete(s.lastPositions, p.ID)
+			continue
+		}
+		cell := models.Position{X: math.Floor(p.Position.X), Y: math.Floor(p.Position.Y)}
+		v := velocity{}
+		if prev, ok := s.lastPositions[p.ID]; ok {
+			v.x = (p.Position.X - prev.X) * models.METERS_PER_UNIT / s.tickSeconds
+			v.y = (p.Position.Y - prev.Y) * models.METERS_PER_UNIT / s.tickSeconds
+		}
+		s.lastPositions[p.ID] = p.Position
+		counts[cell]++
+		velocities[cell] = append(velocities[cell], v)
+	}
+
+	field := make(map[models.Position]models.CrowdCell, len(counts))
+	for cell := range counts {
+		// Moyenne sur le voisinage 3x3 de la case
+		n, area := 0, 0.0
+		local := make([]velocity, 0)
+		for dx := -1.0; dx <= 1; dx++ {
+			for dy := -1.0; dy <= 1; dy++ {
+				neighbor := models.Position{X: cell.X + dx, Y: cell.Y + dy}
+				if neighbor.X < 0 || neighbor.Y < 0 || neighbor.X >= float64(s.Map.Width) || neighbor.Y >= float64(s.Map.Height) {
+					continue
+				}
+				area += models.METERS_PER_UNIT * models.METERS_PER_UNIT
+				n += counts[neighbor]
+				local = append(local, velocities[neighbor]...)
+			}
+		}
+		density := float64(n) * models.PEOPLE_PER_AGENT / area
+
+		var meanX, meanY float64
+		for _, v := range local {
+			meanX += v.x
+			meanY += v.y
+		}
+		meanX /= float64(len(local))
+		meanY /= float64(len(local))
+		variance := 0.0
+		for _, v := range local {
+			variance += (v.x-meanX)*(v.x-meanX) + (v.y-meanY)*(v.y-meanY)
+		}
+		variance /= float64(len(local))
+
+		crowdCell := models.CrowdCell{Position: cell, Density: density, Pressure: density * variance}
+		field[cell] = crowdCell
+
+		stats := &s.CrowdRiskStats
+		if density > stats.PeakDensity {
+			stats.PeakDensity = density
+			stats.PeakDensityTick = s.currentTick
+		}
+		stats.PeakPressure = math.Max(stats.PeakPressure, crowdCell.Pressure)
+		if s.CrowdThresholds.Level(crowdCell) == models.CrowdRiskCritical {
+			stats.CriticalCellTicks++
+		}
+	}
+
+	s.mu.Lock()
+	s.CrowdField = field
+	s.mu.Unlock()
+}
+
+// crowdRiskSeenBy returns the hotspots in the vision range of a drone, heavy rain hides some of them.
+func (s *Simulation) crowdRiskSeenBy(d *drones.Drone) []models.CrowdRiskReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	visibility := s.GetWeather().VisibilityFactor()
+	reports := make([]models.CrowdRiskReport, 0)
+	for _, cell := range s.CrowdField {
+		if cell.Position.CalculateDistance(d.Position) > float64(d.DroneSeeRange) {
+			continue
+		}
+		level := s.CrowdThresholds.Level(cell)
+		if level == models.CrowdRiskNone || rand.Float64() > visibility {
+			continue
+		}
+		reports = append(reports, models.CrowdRiskReport{
+			DroneID: d.ID,
+			Cell:    cell,
+			Level:   level,
+			Tick:    s.currentTick,
+		})
+	}
+	return reports
+}
+
+// GetCrowdAlerts returns the open crowd alerts of every rescue point.
+func (s *Simulation) GetCrowdAlerts() []models.CrowdAlert {
+	alerts := make([]models.CrowdAlert, 0)
+	for _, rp := range s.RescuePoints {
+		alerts = append(alerts, rp.OpenCrowdAlerts()...)
+	}
+	return alerts
+}
+
+// countCrowdAlerts counts every alert raised during the run.
+func (s *Simulation) countCrowdAlerts() {
+	total, critical := 0, 0
+	for _, rp := range s.RescuePoints {
+		for _, alert := range rp.CrowdAlertsSnapshot() {
+			total++
+			if alert.Level == models.CrowdRiskCritical {
+				critical++
+			}
+		}
+	}
+	s.CrowdRiskStats.Alerts = total
+	s.CrowdRiskStats.CriticalAlerts = critical
+}
@@ -0,0 +1,187 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+// Stock de chaque tente médicale pour les drones, à part du matériel des secouristes
+const (
+	FIRST_AID_KITS_PER_TENT = 4
+	AEDS_PER_TENT           = 1
+)
+
+// DeliveryStats counts the kits the drones dropped before the rescuers, and what became of the people.
+type DeliveryStats struct {
+	Loaded        int // Kits picked up at the medical tents
+	OutOfStock    int // Pick-ups refused, the tent had no such kit left
+	Delivered     int // Kits dropped to a person in distress who needed them
+	WrongKit      int // Kits dropped to a person in distress who needed the other kit
+	Wasted        int // Kits dropped to a person who was fine, after a false read
+	Late          int // Kits dropped to a person already rescued, or recovered
+	DeliveryTicks int // From the claim to the drop, summed over the delivered kits
+	ExtraTicks    int // Survival ticks given by the kits
+	Stabilised    int // People kept alive by their kit until the rescuers arrive
+	KitRescued    int // People with a kit reached by a rescuer
+	KitDeaths     int // People with a kit who died before the rescuer
+	KitsLeft      int // In the tents at the last tick
+}
+
+// AverageDelivery returns the ticks between the claim of a person and the drop of the kit.
+func (d DeliveryStats) AverageDelivery() float64 {
+	if d.Delivered == 0 {
+		return 0
+	}
+	return float64(d.DeliveryTicks) / float64(d.Delivered)
+}
+
+// kitClaim reserves a person for the drone bringing a kit, so that two drones do not fly there.
+type kitClaim struct {
+	DroneID int
+	Tick    int
+}
+
+func newKitStock(firstAidKits, aeds int) map[models.Equipment]int {
+	return map[models.Equipment]int{models.FirstAidKit: firstAidKits, models.Defibrillator: aeds}
+}
+
+// UpdateKitStock sets the kits each medical tent keeps for the drones.
+func (s *Simulation) UpdateKitStock(firstAidKits, aeds int) {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	s.kitsPerTent = newKitStock(firstAidKits, aeds)
+	for pos := range s.KitStocks {
+		s.KitStocks[pos] = newKitStock(firstAidKits, aeds)
+	}
+	fmt.Printf("Kit stock: %d first aid kits and %d AEDs per medical tent\n", firstAidKits, aeds)
+}
+
+// initKitStocks fills the stock of each medical tent.
+func (s *Simulation) initKitStocks() {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	s.KitStocks = make(map[models.Position]map[models.Equipment]int)
+	for _, pos := range s.poiMap[models.MedicalTent] {
+		s.KitStocks[pos] = newKitStock(s.kitsPerTent[models.FirstAidKit], s.kitsPerTent[models.Defibrillator])
+	}
+}
+
+// loadKit claims a person for the drone, and gives it the kit at the medical tent if it does not carry it.
+// A drone carrying the other kit leaves it at the tent.
+func (s *Simulation) loadKit(drone *drones.Drone, req models.MedicalDeliveryRequest) (bool, string) {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	person := s.findPerson(req.PersonID)
+	if person == nil || person.HasReceivedMedical {
+		return false, "Already served"
+	}
+	if claim, exists := s.kitClaims[req.PersonID]; exists && claim.DroneID != drone.ID {
+		return false, "Already served"
+	}
+	if !drone.HasMedicalGear || drone.MedicalKit != req.Kit {
+		stock, atTent := s.KitStocks[drone.Position]
+		if !atTent {
+			return false, "Not at a medical tent"
+		}
+		if stock[req.Kit] <= 0 {
+			s.DeliveryStats.OutOfStock++
+			return false, "Out of stock"
+		}
+		if drone.HasMedicalGear {
+			stock[drone.MedicalKit]++
+		}
+		stock[req.Kit]--
+		s.DeliveryStats.Loaded++
+	}
+	s.kitClaims[req.PersonID] = kitClaim{DroneID: drone.ID, Tick: s.currentTick}
+	return true, "Kit loaded"
+}
+
+// releaseKit frees the person claimed by the drone, which gave up the delivery and keeps the kit.
+func (s *Simulation) releaseKit(drone *drones.Drone, req models.MedicalDeliveryRequest) (bool, string) {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	if claim, exists := s.kitClaims[req.PersonID]; !exists || claim.DroneID != drone.ID {
+		return false, "Not claimed"
+	}
+	delete(s.kitClaims, req.PersonID)
+	return true, "Claim released"
+}
+
+// dropKit leaves the kit of the drone to the person, it only helps if it is the kit the incident needs.
+func (s *Simulation) dropKit(drone *drones.Drone, req models.SavePersonRequest) (bool, string) {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	person := s.findPerson(req.PersonID)
+	if person == nil || !drone.HasMedicalGear || drone.Position.CalculateDistance(person.Position) > 1 {
+		return false, "Drop failed"
+	}
+	if person.HasReceivedMedical {
+		return false, "Already served"
+	}
+	claim := s.kitClaims[req.PersonID]
+	delete(s.kitClaims, req.PersonID)
+
+	switch {
+	case !person.IsInDistress() && s.falseReadOf(person):
+		s.DeliveryStats.Wasted++
+		return true, "False alarm"
+	case !person.IsInDistress():
+		s.DeliveryStats.Late++
+		return true, "Too late"
+	case models.DistressProfiles[person.DistressType].Kit != req.Kit:
+		s.DeliveryStats.WrongKit++
+		return true, "Wrong kit"
+	}
+	profile := models.DistressProfiles[person.DistressType]
+	person.ReceiveKit()
+	s.DeliveryStats.Delivered++
+	s.DeliveryStats.DeliveryTicks += s.currentTick - claim.Tick
+	s.DeliveryStats.ExtraTicks += profile.KitExtraTicks
+	if profile.KitStabilises {
+		s.DeliveryStats.Stabilised++
+	}
+	if s.debug {
+		fmt.Printf("[DELIVERY] Drone %d dropped a %s to person %d (%s)\n", drone.ID, req.Kit, person.ID, person.DistressType)
+	}
+	return true, "Kit delivered"
+}
+
+func (s *Simulation) falseReadOf(person *persons.Person) bool {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	return s.falseReads[person.ID]
+}
+
+// recordKitOutcome counts whether a person with a kit lived until the rescuer.
+func (s *Simulation) recordKitOutcome(person *persons.Person, rescued bool) {
+	if !person.HasReceivedMedical {
+		return
+	}
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	if rescued {
+		s.DeliveryStats.KitRescued++
+	} else {
+		s.DeliveryStats.KitDeaths++
+	}
+}
+
+func (s *Simulation) collectDeliveryStats() {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	// Les personnes secourues ou mortes avant le kit libèrent leur réservation
+	for personID := range s.kitClaims {
+		if person := s.findPerson(personID); person == nil || !person.IsInDistress() {
+			delete(s.kitClaims, personID)
+		}
+	}
+	s.DeliveryStats.KitsLeft = 0
+	for _, stock := range s.KitStocks {
+		for _, count := range stock {
+			s.DeliveryStats.KitsLeft += count
+		}
+	}
+}
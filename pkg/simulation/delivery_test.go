@@ -0,0 +1,147 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"testing"
+)
+
+var testTent = models.Position{X: 0, Y: 0}
+
+const noKit models.Equipment = -1
+
+// newDeliverySim returns a simulation with a medical tent holding a first aid kit and no AED, and four people:
+// 0 intoxicated, 1 fine but read in distress, 2 fine, 3 in cardiac arrest.
+func newDeliverySim() *Simulation {
+	s := &Simulation{
+		Persons: []persons.Person{
+			{ID: 0, Position: models.Position{X: 5, Y: 5}, InDistress: true, DistressType: models.Intoxication},
+			{ID: 1, Position: models.Position{X: 5, Y: 5}},
+			{ID: 2, Position: models.Position{X: 5, Y: 5}},
+			{ID: 3, Position: models.Position{X: 5, Y: 5}, InDistress: true, DistressType: models.CardiacArrest},
+		},
+		KitStocks:  map[models.Position]map[models.Equipment]int{testTent: newKitStock(1, 0)},
+		kitClaims:  make(map[int]kitClaim),
+		falseReads: map[int]bool{1: true},
+	}
+	return s
+}
+
+// newCarrier returns a drone at pos, carrying kit unless it is noKit.
+func newCarrier(id int, pos models.Position, kit models.Equipment) *drones.Drone {
+	return &drones.Drone{ID: id, Position: pos, HasMedicalGear: kit != noKit, MedicalKit: kit}
+}
+
+func TestLoadKit(t *testing.T) {
+	cases := []struct {
+		name      string
+		drone     *drones.Drone
+		personID  int
+		kit       models.Equipment
+		claimedBy int // Drone holding a claim on the person beforehand, -1 for none
+		received  bool
+		wantOK    bool
+		wantWhy   string
+		wantStock map[models.Equipment]int
+	}{
+		{"loads at the tent", newCarrier(1, testTent, noKit), 0, models.FirstAidKit, -1, false,
+			true, "Kit loaded", newKitStock(0, 0)},
+		{"claimed by another drone", newCarrier(1, testTent, noKit), 0, models.FirstAidKit, 2, false,
+			false, "Already served", newKitStock(1, 0)},
+		{"own claim with the kit on board", newCarrier(1, models.Position{X: 9, Y: 9}, models.FirstAidKit), 0, models.FirstAidKit, 1, false,
+			true, "Kit loaded", newKitStock(1, 0)},
+		{"already has a kit", newCarrier(1, testTent, noKit), 0, models.FirstAidKit, -1, true,
+			false, "Already served", newKitStock(1, 0)},
+		{"away from the tent", newCarrier(1, models.Position{X: 9, Y: 9}, noKit), 0, models.FirstAidKit, -1, false,
+			false, "Not at a medical tent", newKitStock(1, 0)},
+		{"out of stock", newCarrier(1, testTent, noKit), 3, models.Defibrillator, -1, false,
+			false, "Out of stock", newKitStock(1, 0)},
+		{"swaps the other kit", newCarrier(1, testTent, models.Defibrillator), 0, models.FirstAidKit, -1, false,
+			true, "Kit loaded", newKitStock(0, 1)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newDeliverySim()
+			if c.claimedBy >= 0 {
+				s.kitClaims[c.personID] = kitClaim{DroneID: c.claimedBy}
+			}
+			s.Persons[c.personID].HasReceivedMedical = c.received
+			ok, why := s.loadKit(c.drone, models.MedicalDeliveryRequest{PersonID: c.personID, DroneID: c.drone.ID, Kit: c.kit})
+			if ok != c.wantOK || why != c.wantWhy {
+				t.Fatalf("loadKit() = %v, %q, want %v, %q", ok, why, c.wantOK, c.wantWhy)
+			}
+			for kit, want := range c.wantStock {
+				if got := s.KitStocks[testTent][kit]; got != want {
+					t.Fatalf("stock of %s = %d, want %d", kit, got, want)
+				}
+			}
+			claim, claimed := s.kitClaims[c.personID]
+			if c.wantOK && (!claimed || claim.DroneID != c.drone.ID) {
+				t.Fatalf("claim = %+v, %v, want drone %d", claim, claimed, c.drone.ID)
+			}
+		})
+	}
+}
+
+func TestReleaseKit(t *testing.T) {
+	cases := []struct {
+		name      string
+		claimedBy int
+		wantOK    bool
+		wantClaim bool // The claim is still there after the release
+	}{
+		{"own claim", 1, true, false},
+		{"claim of another drone", 2, false, true},
+		{"no claim", -1, false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newDeliverySim()
+			if c.claimedBy >= 0 {
+				s.kitClaims[0] = kitClaim{DroneID: c.claimedBy}
+			}
+			ok, _ := s.releaseKit(newCarrier(1, testTent, models.FirstAidKit), models.MedicalDeliveryRequest{PersonID: 0, DroneID: 1})
+			if _, claimed := s.kitClaims[0]; ok != c.wantOK || claimed != c.wantClaim {
+				t.Fatalf("releaseKit() = %v, claim kept %v, want %v, %v", ok, claimed, c.wantOK, c.wantClaim)
+			}
+		})
+	}
+}
+
+func TestDropKit(t *testing.T) {
+	atPerson := models.Position{X: 5, Y: 5}
+	cases := []struct {
+		name          string
+		drone         *drones.Drone
+		personID      int
+		wantOK        bool
+		wantWhy       string
+		wantDelivered int
+		wantReceived  bool
+	}{
+		{"delivered", newCarrier(1, atPerson, models.FirstAidKit), 0, true, "Kit delivered", 1, true},
+		{"wrong kit", newCarrier(1, atPerson, models.FirstAidKit), 3, true, "Wrong kit", 0, false},
+		{"false alarm", newCarrier(1, atPerson, models.FirstAidKit), 1, true, "False alarm", 0, false},
+		{"too late", newCarrier(1, atPerson, models.FirstAidKit), 2, true, "Too late", 0, false},
+		{"too far", newCarrier(1, models.Position{X: 8, Y: 5}, models.FirstAidKit), 0, false, "Drop failed", 0, false},
+		{"no kit on board", newCarrier(1, atPerson, noKit), 0, false, "Drop failed", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newDeliverySim()
+			s.kitClaims[c.personID] = kitClaim{DroneID: c.drone.ID}
+			ok, why := s.dropKit(c.drone, models.SavePersonRequest{PersonID: c.personID, DroneID: c.drone.ID, Kit: c.drone.MedicalKit})
+			if ok != c.wantOK || why != c.wantWhy {
+				t.Fatalf("dropKit() = %v, %q, want %v, %q", ok, why, c.wantOK, c.wantWhy)
+			}
+			if s.DeliveryStats.Delivered != c.wantDelivered || s.Persons[c.personID].HasReceivedMedical != c.wantReceived {
+				t.Fatalf("delivered %d, kit received %v, want %d, %v", s.DeliveryStats.Delivered,
+					s.Persons[c.personID].HasReceivedMedical, c.wantDelivered, c.wantReceived)
+			}
+			if _, claimed := s.kitClaims[c.personID]; claimed == c.wantOK {
+				t.Fatalf("claim kept %v after the drop", claimed)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadDroneType reads a drone type, the fields missing from the file keep the values of drones.DefaultDroneType.
+func LoadDroneType(typePath string) (*drones.DroneType, error) {
+	absPath, err := filepath.Abs(typePath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path: %v", err)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading drone type file: %v", err)
+	}
+
+	droneType := drones.DefaultDroneType
+	if err := json.Unmarshal(data, &droneType); err != nil {
+		return nil, fmt.Errorf("error parsing drone type file: %v", err)
+	}
+
+	return &droneType, nil
+}
+
+// UpdateDroneTypes gives the drones the types of configs/drones/<name>.json in turn, from a
+// comma-separated list; "standard" is drones.DefaultDroneType.
+func (s *Simulation) UpdateDroneTypes(spec string) {
+	names, err := drones.ParseTypeMix(spec)
+	if err != nil {
+		fmt.Printf("Warning: Could not set drone types %q: %v\n", spec, err)
+		return
+	}
+	types := make([]drones.DroneType, 0, len(names))
+	sensors := make(map[string]models.SensorModel)
+	for _, name := range names {
+		droneType := drones.DefaultDroneType
+		if name != droneType.Name {
+			configPath := "configs/drones/" + name + ".json"
+			loaded, err := LoadDroneType(configPath)
+			if err != nil {
+				fmt.Printf("Warning: Could not load drone type from %s: %v\n", configPath, err)
+				return
+			}
+			droneType = *loaded
+			droneType.Name = name
+		}
+		if droneType.Sensor != "" {
+			configPath := "configs/sensors/" + droneType.Sensor + ".json"
+			sensor, err := LoadSensorModel(configPath)
+			if err != nil {
+				fmt.Printf("Warning: Could not load sensor model from %s: %v\n", configPath, err)
+				return
+			}
+			sensors[name] = *sensor
+		}
+		types = append(types, droneType)
+	}
+
+	s.sensorMu.Lock()
+	s.typeSensors = sensors
+	s.sensorMu.Unlock()
+
+	s.mu.Lock()
+	s.DroneTypes = types
+	for i := range s.Drones {
+		s.Drones[i].ApplyType(types[i%len(types)])
+	}
+	hotSwap := s.FleetSchedule.HotSwap
+	s.mu.Unlock()
+	if hotSwap {
+		// Les stations gardent des batteries de réserve pour chaque type
+		s.InitializeChargingStations()
+	}
+	fmt.Printf("Drone types: %s\n", strings.Join(names, ", "))
+}
+
+// fleetTypes returns the drone types of the fleet, each once.
+func (s *Simulation) fleetTypes() []drones.DroneType {
+	if len(s.DroneTypes) == 0 {
+		return []drones.DroneType{drones.DefaultDroneType}
+	}
+	types := make([]drones.DroneType, 0, len(s.DroneTypes))
+	seen := make(map[string]bool)
+	for _, droneType := range s.DroneTypes {
+		if !seen[droneType.Name] {
+			seen[droneType.Name] = true
+			types = append(types, droneType)
+		}
+	}
+	return types
+}
+
+// sensorOf returns the camera of a drone, the one of its type or the one of the simulation.
+// The caller holds sensorMu.
+func (s *Simulation) sensorOf(d *drones.Drone) models.SensorModel {
+	if model, exists := s.typeSensors[d.Type.Name]; exists {
+		return model
+	}
+	return s.SensorModel
+}
+
+func seeArea(d *drones.Drone) float64 {
+	return math.Pi * float64(d.DroneSeeRange*d.DroneSeeRange)
+}
+
+// averageSeeArea returns the area a drone of the fleet sees on average.
+func (s *Simulation) averageSeeArea() float64 {
+	if len(s.Drones) == 0 {
+		return math.Pi * float64(s.DroneSeeRange*s.DroneSeeRange)
+	}
+	total := 0.0
+	for i := range s.Drones {
+		total += seeArea(&s.Drones[i])
+	}
+	return total / float64(len(s.Drones))
+}
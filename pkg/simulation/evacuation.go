@@ -0,0 +1,334 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const DEFAULT_EXIT_CAPACITY = 4
+
+// Evacuation tracks an evacuation, from its trigger to the moment everybody who can walk is out.
+type Evacuation struct {
+	Active          bool
+	Scenario        *models.EvacuationScenario // Armed scenario, nil once triggered
+	StartTick       int
+	EndTick         int // 0 while people are still inside
+	Hazard          *models.HazardArea
+	Exits           []models.EmergencyExit
+	Throughput      map[string]int // People evacuated by exit
+	exitedThisTick  map[string]int
+	PeakDensity     int // Maximum number of people on a single cell
+	PeakDensityTick int
+}
+
+// LoadEvacuationScenario loads an evacuation scenario from a JSON file
+func LoadEvacuationScenario(scenarioPath string) (*models.EvacuationScenario, error) {
+	absPath, err := filepath.Abs(scenarioPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path: %v", err)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading evacuation file: %v", err)
+	}
+
+	var scenario models.EvacuationScenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("error parsing evacuation file: %v", err)
+	}
+
+	return &scenario, nil
+}
+
+// UpdateEvacuation arms the scenario configs/evacuation/<name>.json, it is triggered at its tick.
+func (s *Simulation) UpdateEvacuation(nomScenario string) {
+	configPath := "configs/evacuation/" + nomScenario + ".json"
+	scenario, err := LoadEvacuationScenario(configPath)
+	if err != nil {
+		fmt.Printf("Warning: Could not load evacuation scenario from %s: %v\n", configPath, err)
+		return
+	}
+	if scenario.Name == "" {
+		scenario.Name = nomScenario
+	}
+	s.evacMu.Lock()
+	s.Evacuation = &Evacuation{Scenario: scenario}
+	s.evacMu.Unlock()
+	fmt.Printf("Evacuation scenario %s armed for tick %d\n", scenario.Name, scenario.TriggerTick)
+}
+
+// TriggerEvacuation sends everybody to the nearest emergency exit, around the hazard area if any.
+// The evacuation starts at the next tick so that it does not interrupt the agents.
+func (s *Simulation) TriggerEvacuation(hazard *models.HazardArea) {
+	s.evacMu.Lock()
+	defer s.evacMu.Unlock()
+	if s.isEvacuating() {
+		return
+	}
+	s.Evacuation = &Evacuation{Scenario: &models.EvacuationScenario{
+		Name:        "manual",
+		TriggerTick: s.currentTick,
+		Hazard:      hazard,
+	}}
+}
+
+func (s *Simulation) IsEvacuating() bool {
+	s.evacMu.Lock()
+	defer s.evacMu.Unlock()
+	return s.isEvacuating()
+}
+
+// ActiveEvacuation returns the running evacuation, nil if there is none. Its Hazard and Exits
+// no longer change once it is active.
+func (s *Simulation) ActiveEvacuation() *Evacuation {
+	s.evacMu.Lock()
+	defer s.evacMu.Unlock()
+	if !s.isEvacuating() {
+		return nil
+	}
+	return s.Evacuation
+}
+
+// isEvacuating is IsEvacuating for the callers holding s.evacMu.
+func (s *Simulation) isEvacuating() bool {
+	return s.Evacuation != nil && s.Evacuation.Active
+}
+
+// triggerEvacuation starts the evacuation, the caller holds s.evacMu.
+func (s *Simulation) triggerEvacuation(hazard *models.HazardArea, exits []models.EmergencyExit) {
+	if s.isEvacuating() {
+		return
+	}
+	if len(exits) == 0 {
+		exits = s.emergencyExits()
+	}
+
+	// Les sorties dans la zone dangereuse sont condamnées
+	usable := make([]models.EmergencyExit, 0, len(exits))
+	for _, exit := range exits {
+		if !hazard.Contains(exit.Position) {
+			usable = append(usable, exit)
+		}
+	}
+	if len(usable) == 0 {
+		usable = exits
+	}
+
+	s.Evacuation = &Evacuation{
+		Active:         true,
+		StartTick:      s.currentTick,
+		Hazard:         hazard,
+		Exits:          usable,
+		Throughput:     make(map[string]int),
+		exitedThisTick: make(map[string]int),
+	}
+	fmt.Printf("[EVACUATION] Triggered at tick %d with %d exits\n", s.currentTick, len(usable))
+
+	avoid := s.hazardCells(hazard)
+	for i := range s.Persons {
+		p := &s.Persons[i]
+		if !p.Arrived {
+			// Les entrées sont fermées
+			p.StillInSim = false
+			continue
+		}
+		if !p.StillInSim || p.IsDead() {
+			continue
+		}
+		exit := s.nearestExit(p.Position)
+		p.ExitTarget = &exit.Position
+		p.AvoidCells = avoid
+		p.CurrentPath = nil
+		if hazard.Contains(p.Position) {
+			// Sortir d'abord de la zone dangereuse, au plus court
+			p.CurrentPath = models.FindPath(p.Position, s.escapePoint(p.Position, hazard),
+				s.Map.Width, s.Map.Height, make(map[models.Position]bool))
+		}
+		p.SeekingExit = true
+	}
+
+	order := models.EvacuationOrder{Exits: usable, Hazard: hazard}
+	for i := range s.Drones {
+		s.Drones[i].Evacuation = &order
+	}
+}
+
+// emergencyExits returns the exits of the festival config, or three exits on the regular exit line.
+func (s *Simulation) emergencyExits() []models.EmergencyExit {
+	if s.FestivalConfig != nil && len(s.FestivalConfig.EmergencyExits) > 0 {
+		return s.FestivalConfig.EmergencyExits
+	}
+	x := math.Floor(float64(s.Map.Width)/10*9) + 1
+	exits := make([]models.EmergencyExit, 0, 3)
+	for i := 1; i <= 3; i++ {
+		exits = append(exits, models.EmergencyExit{
+			Name:     fmt.Sprintf("Exit %d", i),
+			Position: models.Position{X: x, Y: math.Floor(float64(s.Map.Height*i) / 4)},
+			Capacity: DEFAULT_EXIT_CAPACITY,
+		})
+	}
+	return exits
+}
+
+// nearestExit returns the closest usable exit, going around the hazard costs the detour.
+func (s *Simulation) nearestExit(pos models.Position) models.EmergencyExit {
+	evac := s.Evacuation
+	best := evac.Exits[0]
+	bestDist := math.Inf(1)
+	for _, exit := range evac.Exits {
+		dist := pos.CalculateDistance(exit.Position)
+		if evac.Hazard != nil && segmentCrossesHazard(pos, exit.Position, evac.Hazard) {
+			dist += math.Pi * evac.Hazard.Radius
+		}
+		if dist < bestDist {
+			bestDist = dist
+			best = exit
+		}
+	}
+	return best
+}
+
+func segmentCrossesHazard(from, to models.Position, hazard *models.HazardArea) bool {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := dx*dx + dy*dy
+	t := 0.0
+	if length > 0 {
+		t = ((hazard.Center.X-from.X)*dx + (hazard.Center.Y-from.Y)*dy) / length
+		t = math.Max(0, math.Min(1, t))
+	}
+	closest := models.Position{X: from.X + t*dx, Y: from.Y + t*dy}
+	return hazard.Contains(closest)
+}
+
+// escapePoint returns the closest point just outside the hazard area.
+func (s *Simulation) escapePoint(pos models.Position, hazard *models.HazardArea) models.Position {
+	dx, dy := pos.X-hazard.Center.X, pos.Y-hazard.Center.Y
+	dist := math.Hypot(dx, dy)
+	if dist == 0 {
+		dx, dy, dist = 1, 0, 1
+	}
+	scale := (hazard.Radius + 1) / dist
+	return models.Position{
+		X: math.Max(0, math.Min(float64(s.Map.Width-1), hazard.Center.X+dx*scale)),
+		Y: math.Max(0, math.Min(float64(s.Map.Height-1), hazard.Center.Y+dy*scale)),
+	}
+}
+
+// hazardCells returns the pathfinding cells covered by the hazard area.
+func (s *Simulation) hazardCells(hazard *models.HazardArea) map[models.Position]bool {
+	cells := make(map[models.Position]bool)
+	if hazard == nil {
+		return cells
+	}
+	for x := 0; x < s.Map.Width; x++ {
+		for y := 0; y < s.Map.Height; y++ {
+			pos := models.Position{X: float64(x), Y: float64(y)}
+			if hazard.Contains(pos) {
+				cells[pos] = true
+			}
+		}
+	}
+	return cells
+}
+
+// exitAuthorized checks the capacity of the emergency exit used by the person during this tick,
+// any exit is open outside of an evacuation.
+func (s *Simulation) exitAuthorized(p *persons.Person) bool {
+	s.evacMu.Lock()
+	defer s.evacMu.Unlock()
+	if !s.isEvacuating() || p.ExitTarget == nil {
+		return true
+	}
+	evac := s.Evacuation
+	for _, exit := range evac.Exits {
+		if exit.Position != *p.ExitTarget {
+			continue
+		}
+		if exit.Capacity > 0 && evac.exitedThisTick[exit.Name] >= exit.Capacity {
+			return false
+		}
+		evac.exitedThisTick[exit.Name]++
+		evac.Throughput[exit.Name]++
+		return true
+	}
+	return true
+}
+
+// updateEvacuation triggers the armed scenario and follows the running evacuation.
+func (s *Simulation) updateEvacuation() {
+	s.evacMu.Lock()
+	defer s.evacMu.Unlock()
+	if s.Evacuation == nil {
+		return
+	}
+	if scenario := s.Evacuation.Scenario; scenario != nil && !s.Evacuation.Active && s.currentTick >= scenario.TriggerTick {
+		s.triggerEvacuation(scenario.Hazard, scenario.Exits)
+	}
+	evac := s.Evacuation
+	if !evac.Active || evac.EndTick != 0 {
+		return
+	}
+	evac.exitedThisTick = make(map[string]int)
+
+	remaining := 0
+	cellCounts := make(map[models.Position]int)
+	for i := range s.Persons {
+		p := &s.Persons[i]
+		if !p.Arrived || !p.StillInSim || p.IsDead() {
+			continue
+		}
+		p.Guided = s.isGuidedByDrone(p.Position)
+		if !p.InDistress {
+			remaining++
+		}
+		cell := models.Position{X: math.Floor(p.Position.X), Y: math.Floor(p.Position.Y)}
+		cellCounts[cell]++
+	}
+	for _, count := range cellCounts {
+		if count > evac.PeakDensity {
+			evac.PeakDensity = count
+			evac.PeakDensityTick = s.currentTick
+		}
+	}
+
+	if remaining == 0 {
+		evac.EndTick = s.currentTick
+		fmt.Printf("[EVACUATION] Completed in %d ticks\n", evac.EndTick-evac.StartTick)
+	}
+}
+
+func (s *Simulation) isGuidedByDrone(pos models.Position) bool {
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		if d.IsCharging || !d.IsGuiding() {
+			continue
+		}
+		if d.Position.CalculateDistance(pos) <= float64(d.DroneSeeRange) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Simulation) evacuationTime() int {
+	s.evacMu.Lock()
+	defer s.evacMu.Unlock()
+	return s.Evacuation.EvacuationTime(s.currentTick)
+}
+
+// EvacuationTime returns the number of ticks the evacuation took, or has taken so far.
+func (e *Evacuation) EvacuationTime(currentTick int) int {
+	if e == nil || !e.Active {
+		return 0
+	}
+	if e.EndTick != 0 {
+		return e.EndTick - e.StartTick
+	}
+	return currentTick - e.StartTick
+}
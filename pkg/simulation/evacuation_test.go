@@ -0,0 +1,47 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"testing"
+)
+
+func TestExitAuthorized(t *testing.T) {
+	gate := models.Position{X: 10, Y: 0}
+	other := models.Position{X: 0, Y: 10}
+	exits := []models.EmergencyExit{
+		{Name: "gate", Position: gate, Capacity: 2},
+		{Name: "open", Position: other},
+	}
+	cases := []struct {
+		name       string
+		active     bool
+		target     *models.Position
+		exitedGate int // People through the gate earlier in the tick
+		want       bool
+		wantGate   int // Gate count after the call
+	}{
+		{"no evacuation", false, &gate, 2, true, 2},
+		{"no exit target", true, nil, 2, true, 2},
+		{"under capacity", true, &gate, 1, true, 2},
+		{"capacity reached", true, &gate, 2, false, 2},
+		{"exit without capacity", true, &other, 2, true, 2},
+		{"unknown exit", true, &models.Position{X: 5, Y: 5}, 2, true, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := &Simulation{Evacuation: &Evacuation{
+				Active:         c.active,
+				Exits:          exits,
+				Throughput:     make(map[string]int),
+				exitedThisTick: map[string]int{"gate": c.exitedGate},
+			}}
+			if got := s.exitAuthorized(&persons.Person{ExitTarget: c.target}); got != c.want {
+				t.Fatalf("exitAuthorized() = %v, want %v", got, c.want)
+			}
+			if got := s.Evacuation.exitedThisTick["gate"]; got != c.wantGate {
+				t.Fatalf("people through the gate = %d, want %d", got, c.wantGate)
+			}
+		})
+	}
+}
@@ -0,0 +1,448 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/entities/rescue"
+	"UTC_IA04/pkg/models"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	DEFAULT_FAULT_DURATION = 30  // Ticks of a failure when the scenario gives none
+	DEFAULT_GPS_DRIFT      = 0.1 // Cells per tick
+	GPS_MAX_ERROR          = 6.0 // Cells, the GPS error stops growing beyond
+)
+
+// FaultStats measures the failures of a run and how the fleet held up.
+type FaultStats struct {
+	Injected           map[models.FaultType]int
+	FaultTicks         int // Ticks with at least one failure in progress, a crash is a one-off event
+	DegradedDroneTicks int // Drone-ticks under a sensor, radio or GPS failure
+	IsolatedDroneTicks int // Flying drone-ticks without any path to an online rescue point
+	NominalTicks       int
+	GapUnderFaults     float64 // Average share of the map seen by no drone during the fault ticks
+	NominalGap         float64 // Same during the nominal ticks
+}
+
+// faultEvent is a failure of a drone or of a rescue point, planned or in progress.
+type faultEvent struct {
+	Type   models.FaultType
+	Target int // Drone or rescue point ID
+	Start  int
+	Until  int             // Last tick of the failure
+	Drift  models.Position // Cells per tick added to the GPS error
+}
+
+// FaultInjector plays a fault scenario: failures planned at given ticks, and random failures.
+type FaultInjector struct {
+	Scenario *models.FaultScenario
+	pending  []faultEvent
+	active   []faultEvent
+}
+
+func newFaultEvent(faultType models.FaultType, target, start, duration int, drift float64) faultEvent {
+	event := faultEvent{Type: faultType, Target: target, Start: start, Until: start + duration - 1}
+	switch faultType {
+	case models.FaultGPSDrift:
+		if drift <= 0 {
+			drift = DEFAULT_GPS_DRIFT
+		}
+		// Dérive dans une direction fixe, comme un biais de multitrajet
+		angle := rand.Float64() * 2 * math.Pi
+		event.Drift = models.Position{X: drift * math.Cos(angle), Y: drift * math.Sin(angle)}
+	}
+	return event
+}
+
+func NewFaultInjector(scenario *models.FaultScenario) *FaultInjector {
+	if scenario.Duration <= 0 {
+		scenario.Duration = DEFAULT_FAULT_DURATION
+	}
+	f := &FaultInjector{Scenario: scenario}
+	for _, fault := range scenario.Faults {
+		duration := fault.Duration
+		if duration <= 0 {
+			duration = scenario.Duration
+		}
+		targets := fault.Drones
+		if fault.Type == models.FaultRescuePointOffline {
+			targets = []int{fault.RescuePoint}
+		}
+		for _, target := range targets {
+			f.pending = append(f.pending, newFaultEvent(fault.Type, target, fault.Tick, duration, fault.Drift))
+		}
+	}
+	sort.SliceStable(f.pending, func(i, j int) bool { return f.pending[i].Start < f.pending[j].Start })
+	return f
+}
+
+// LoadFaultScenario loads a fault scenario from a JSON file
+func LoadFaultScenario(scenarioPath string) (*models.FaultScenario, error) {
+	absPath, err := filepath.Abs(scenarioPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path: %v", err)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fault file: %v", err)
+	}
+
+	var scenario models.FaultScenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("error parsing fault file: %v", err)
+	}
+
+	return &scenario, nil
+}
+
+// UpdateFaults arms the failures of configs/faults/<name>.json.
+func (s *Simulation) UpdateFaults(nomScenario string) {
+	configPath := "configs/faults/" + nomScenario + ".json"
+	scenario, err := LoadFaultScenario(configPath)
+	if err != nil {
+		fmt.Printf("Warning: Could not load fault scenario from %s: %v\n", configPath, err)
+		return
+	}
+	if scenario.Name == "" {
+		scenario.Name = nomScenario
+	}
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	rates := models.FaultRates{}
+	if s.Faults != nil {
+		rates = s.Faults.Scenario.Rates
+	}
+	s.Faults = NewFaultInjector(scenario)
+	if scenario.Rates == (models.FaultRates{}) {
+		s.Faults.Scenario.Rates = rates
+	}
+	fmt.Printf("Fault scenario %s armed with %d failures\n", scenario.Name, len(s.Faults.pending))
+}
+
+// UpdateFaultRates draws random failures at the given rates, on top of the armed scenario if any.
+func (s *Simulation) UpdateFaultRates(rates models.FaultRates) {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	if s.Faults == nil {
+		s.Faults = NewFaultInjector(&models.FaultScenario{Name: "random"})
+	}
+	s.Faults.Scenario.Rates = rates
+}
+
+// updateFaults ends the failures over, starts the planned and random ones, and lets the GPS drift.
+func (s *Simulation) updateFaults() {
+	if s.Faults == nil {
+		return
+	}
+	s.faultsMu.Lock()
+	f := s.Faults
+
+	kept := f.active[:0]
+	var ended []faultEvent
+	for _, event := range f.active {
+		if event.Until < s.currentTick {
+			ended = append(ended, event)
+			continue
+		}
+		kept = append(kept, event)
+	}
+	f.active = kept
+	fixed := make(map[*drones.Drone]models.Position)
+	for _, event := range ended {
+		if d := s.endFault(event); d != nil {
+			fixed[d] = d.TruePosition()
+			d.GPSError = models.Position{}
+		}
+	}
+
+	pending := f.pending[:0]
+	for _, event := range f.pending {
+		if event.Start > s.currentTick || !s.startFault(event) {
+			pending = append(pending, event)
+		}
+	}
+	f.pending = pending
+
+	rates := f.Scenario.Rates
+	duration := rates.Duration
+	if duration <= 0 {
+		duration = f.Scenario.Duration
+	}
+	draws := []struct {
+		faultType models.FaultType
+		rate      float64
+	}{
+		{models.FaultCrash, rates.Crash},
+		{models.FaultSensor, rates.Sensor},
+		{models.FaultRadio, rates.Radio},
+		{models.FaultGPSDrift, rates.GPSDrift},
+	}
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		if !d.Airborne() {
+			continue
+		}
+		for _, draw := range draws {
+			if draw.rate > 0 && rand.Float64() < draw.rate && !s.hasFault(d.ID, draw.faultType) {
+				s.startFault(newFaultEvent(draw.faultType, d.ID, s.currentTick, duration, rates.Drift))
+			}
+		}
+	}
+	if rates.RescuePointOffline > 0 {
+		for _, rp := range s.RescuePoints {
+			if rand.Float64() < rates.RescuePointOffline && !s.hasFault(rp.ID, models.FaultRescuePointOffline) {
+				s.startFault(newFaultEvent(models.FaultRescuePointOffline, rp.ID, s.currentTick, duration, 0))
+			}
+		}
+	}
+
+	for _, event := range f.active {
+		if event.Type != models.FaultGPSDrift {
+			continue
+		}
+		if d := s.droneByID(event.Target); d != nil {
+			d.GPSError.X += event.Drift.X
+			d.GPSError.Y += event.Drift.Y
+			if norm := math.Hypot(d.GPSError.X, d.GPSError.Y); norm > GPS_MAX_ERROR {
+				d.GPSError.X *= GPS_MAX_ERROR / norm
+				d.GPSError.Y *= GPS_MAX_ERROR / norm
+			}
+		}
+	}
+	s.faultsMu.Unlock()
+
+	// Hors du verrou des pannes, la carte prend s.mu
+	for d, truePos := range fixed {
+		s.mu.Lock()
+		s.Map.MoveEntity(d, truePos)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Simulation) droneByID(id int) *drones.Drone {
+	for i := range s.Drones {
+		if s.Drones[i].ID == id {
+			return &s.Drones[i]
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) rescuePointByID(id int) *rescue.RescuePoint {
+	for _, rp := range s.RescuePoints {
+		if rp.ID == id {
+			return rp
+		}
+	}
+	return nil
+}
+
+// startFault injects a failure, it returns false to postpone it: a drone crashes in flight only.
+// A crash is over once the drone is down, the other failures stay in progress until their end.
+func (s *Simulation) startFault(event faultEvent) bool {
+	if event.Type == models.FaultRescuePointOffline {
+		rp := s.rescuePointByID(event.Target)
+		if rp == nil {
+			fmt.Printf("Warning: No rescue point %d to take offline\n", event.Target)
+			return true
+		}
+		if s.debug {
+			fmt.Printf("[FAULT] Rescue point %d offline until tick %d\n", rp.ID, event.Until)
+		}
+	} else {
+		d := s.droneByID(event.Target)
+		if d == nil {
+			fmt.Printf("Warning: No drone %d for a %s failure\n", event.Target, event.Type)
+			return true
+		}
+		if event.Type == models.FaultCrash {
+			if !d.Airborne() {
+				return false
+			}
+			d.Crashed = true
+			if s.debug {
+				fmt.Printf("[FAULT] Drone %d crashed at (%.0f, %.0f)\n", d.ID, d.Position.X, d.Position.Y)
+			}
+		} else if s.debug {
+			fmt.Printf("[FAULT] Drone %d %s failure until tick %d\n", d.ID, event.Type, event.Until)
+		}
+	}
+
+	if event.Type != models.FaultCrash {
+		s.Faults.active = append(s.Faults.active, event)
+	}
+	if s.FaultStats.Injected == nil {
+		s.FaultStats.Injected = make(map[models.FaultType]int)
+	}
+	s.FaultStats.Injected[event.Type]++
+	return true
+}
+
+// endFault repairs a failure. It returns the drone getting its GPS fix back, which learns its
+// true position.
+func (s *Simulation) endFault(event faultEvent) *drones.Drone {
+	if s.debug {
+		fmt.Printf("[FAULT] %s failure of %d over\n", event.Type, event.Target)
+	}
+	if event.Type != models.FaultGPSDrift || s.hasFault(event.Target, models.FaultGPSDrift) {
+		return nil
+	}
+	return s.droneByID(event.Target)
+}
+
+// hasFault tells if a drone, or a rescue point, has a failure of the given type in progress.
+func (s *Simulation) hasFault(target int, faultType models.FaultType) bool {
+	if s.Faults == nil {
+		return false
+	}
+	for _, event := range s.Faults.active {
+		if event.Target == target && event.Type == faultType {
+			return true
+		}
+	}
+	return false
+}
+
+// DroneFaults returns the failures in progress of a drone.
+func (s *Simulation) DroneFaults(id int) []models.FaultType {
+	s.faultsMu.RLock()
+	defer s.faultsMu.RUnlock()
+	faults := make([]models.FaultType, 0)
+	if s.Faults == nil {
+		return faults
+	}
+	for _, event := range s.Faults.active {
+		if event.Target == id && event.Type != models.FaultRescuePointOffline {
+			faults = append(faults, event.Type)
+		}
+	}
+	return faults
+}
+
+func (s *Simulation) activeFaultCount() int {
+	s.faultsMu.RLock()
+	defer s.faultsMu.RUnlock()
+	if s.Faults == nil {
+		return 0
+	}
+	return len(s.Faults.active)
+}
+
+func (s *Simulation) sensorDown(d *drones.Drone) bool {
+	s.faultsMu.RLock()
+	defer s.faultsMu.RUnlock()
+	return d.Crashed || s.hasFault(d.ID, models.FaultSensor)
+}
+
+func (s *Simulation) radioDown(d *drones.Drone) bool {
+	s.faultsMu.RLock()
+	defer s.faultsMu.RUnlock()
+	return d.Crashed || s.hasFault(d.ID, models.FaultRadio)
+}
+
+func (s *Simulation) rescuePointOffline(rp *rescue.RescuePoint) bool {
+	s.faultsMu.RLock()
+	defer s.faultsMu.RUnlock()
+	return s.hasFault(rp.ID, models.FaultRescuePointOffline)
+}
+
+// snapshotDronePositions freezes the positions of the drones: the links are computed from the true ones,
+// the drones read the beacons of the others. Called while no drone takes its turn.
+func (s *Simulation) snapshotDronePositions() {
+	positions := make(map[int]models.Position, len(s.Drones))
+	flights := make(map[int]droneFlight, len(s.Drones))
+	for i := range s.Drones {
+		positions[s.Drones[i].ID] = s.Drones[i].TruePosition()
+		flights[s.Drones[i].ID] = flightOf(&s.Drones[i])
+		s.Drones[i].Beacon = s.Drones[i].Position
+	}
+	s.dronePositions = positions
+	s.mu.Lock()
+	s.flights = flights
+	s.mu.Unlock()
+}
+
+// dronePosition returns the true position of the drone of the snapshot, a drone added since is read directly.
+func (s *Simulation) dronePosition(d *drones.Drone) models.Position {
+	if pos, exists := s.dronePositions[d.ID]; exists {
+		return pos
+	}
+	return d.TruePosition()
+}
+
+// linked tells if two drones can talk: both radios work and their true positions are in range.
+func (s *Simulation) linked(a, b *drones.Drone) bool {
+	posA, posB := s.dronePosition(a), s.dronePosition(b)
+	return !s.radioDown(a) && !s.radioDown(b) && posA.CalculateDistance(posB) <= linkRange(a, b)
+}
+
+// linkRange returns the range of a link between two drones, the antenna of a relay carries both ways.
+func linkRange(a, b *drones.Drone) float64 {
+	return float64(max(a.DroneCommRange, b.DroneCommRange))
+}
+
+// rescuePointLinked tells if a drone can talk to a rescue point.
+func (s *Simulation) rescuePointLinked(d *drones.Drone, rp *rescue.RescuePoint) bool {
+	pos := d.TruePosition()
+	return !s.radioDown(d) && !s.rescuePointOffline(rp) && pos.CalculateDistance(rp.Position) <= float64(d.DroneCommRange)
+}
+
+// closestOnlineRescuePoint is the rescue point the drones report to, offline ones are ignored.
+func (s *Simulation) closestOnlineRescuePoint(pos models.Position) *rescue.RescuePoint {
+	var closest *rescue.RescuePoint
+	minDist := math.Inf(1)
+	for _, rp := range s.RescuePoints {
+		if s.rescuePointOffline(rp) {
+			continue
+		}
+		if dist := pos.CalculateDistance(rp.Position); dist < minDist {
+			minDist = dist
+			closest = rp
+		}
+	}
+	return closest
+}
+
+// collectFaultStats measures the drones hit by a failure, the drones cut from every rescue point,
+// and the coverage gap with and without failures.
+func (s *Simulation) collectFaultStats() {
+	s.faultsMu.RLock()
+	inProgress := s.Faults != nil && len(s.Faults.active) > 0
+	degraded := 0
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		if !d.Crashed && (s.hasFault(d.ID, models.FaultSensor) || s.hasFault(d.ID, models.FaultRadio) || s.hasFault(d.ID, models.FaultGPSDrift)) {
+			degraded++
+		}
+	}
+	s.faultsMu.RUnlock()
+
+	isolated := 0
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		if !d.Airborne() {
+			continue
+		}
+		if !s.connectedToRescuePoint(d) {
+			isolated++
+		}
+	}
+
+	stats := &s.FaultStats
+	stats.DegradedDroneTicks += degraded
+	stats.IsolatedDroneTicks += isolated
+	if inProgress {
+		stats.FaultTicks++
+		stats.GapUnderFaults += (s.coverageGap - stats.GapUnderFaults) / float64(stats.FaultTicks)
+	} else {
+		stats.NominalTicks++
+		stats.NominalGap += (s.coverageGap - stats.NominalGap) / float64(stats.NominalTicks)
+	}
+}
@@ -5,6 +5,19 @@ import (
 	"time"
 )
 
+// FESTIVAL_START_HOUR is the hour of the first tick, unless the festival config gives its own.
+const FESTIVAL_START_HOUR = 12.0
+
+// festivalStartTime returns the time of the first tick, at the given hour.
+func festivalStartTime(hour float64) time.Time {
+	return time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(hour * float64(time.Hour)))
+}
+
+// FestivalClock returns the time of the festival at a tick, a tick lasts s.tickSeconds.
+func (s *Simulation) FestivalClock(tick int) time.Time {
+	return s.festivalStart.Add(time.Duration(float64(tick) * s.tickSeconds * float64(time.Second)))
+}
+
 type FestivalTime struct {
 	startTime   time.Time
 	currentTime time.Time
@@ -0,0 +1,128 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// FleetSchedule staggers the charges of the fleet so that enough drones stay airborne.
+// Without a minimum, each drone decides alone to charge when it reaches its reserve.
+type FleetSchedule struct {
+	MinAirborne    int     // Drones to keep in the air
+	MinCoverage    float64 // Share of the map to keep in sight, turned into a number of drones
+	HotSwap        bool    // Swap the battery for a charged spare instead of charging on a pad
+	SpareBatteries int     // Spare batteries per station and drone type in hot-swap mode, one per pad if 0
+}
+
+// FleetStats measures how many drones stayed airborne during a run.
+type FleetStats struct {
+	Ticks          int
+	Airborne       float64 // Average number of drones in the air
+	LowestAirborne int
+	TicksBelowMin  int // Ticks with fewer drones in the air than the schedule requires
+	EarlyCharges   int // Drones sent to charge by the schedule before their reserve
+}
+
+// UpdateFleetSchedule sets the charging schedule of the fleet. The charging stations are
+// rebuilt, so it must be called before the first tick.
+func (s *Simulation) UpdateFleetSchedule(schedule FleetSchedule) {
+	s.mu.Lock()
+	s.FleetSchedule = schedule
+	s.mu.Unlock()
+	s.InitializeChargingStations()
+}
+
+// requiredAirborne returns the number of drones the schedule keeps in the air.
+func (s *Simulation) requiredAirborne() int {
+	required := s.FleetSchedule.MinAirborne
+	if s.FleetSchedule.MinCoverage > 0 {
+		droneArea := s.averageSeeArea()
+		totalArea := float64(s.Map.Width * s.Map.Height)
+		required = max(required, int(math.Ceil(s.FleetSchedule.MinCoverage*totalArea/droneArea)))
+	}
+	return min(required, len(s.Drones))
+}
+
+// scheduleCharging sends drones to charge before their reserve so that at most
+// len(Drones)-requiredAirborne of them are down at the same time.
+//
+// The patrolling drones are sorted by endurance. Each one must be back from charging before the
+// drone placed `capacity` ranks later in that order has to leave, which gives its latest
+// departure, computed backwards. The drones whose latest departure has come leave now while
+// the capacity allows it. Drones reaching their reserve always leave, safety first.
+func (s *Simulation) scheduleCharging() {
+	required := s.requiredAirborne()
+	if required == 0 || s.FestivalState == Ended {
+		return
+	}
+	capacity := len(s.Drones) - required
+	if capacity <= 0 {
+		return
+	}
+
+	patrolling := make([]*drones.Drone, 0, len(s.Drones))
+	for i := range s.Drones {
+		if isPatrolling(&s.Drones[i]) {
+			patrolling = append(patrolling, &s.Drones[i])
+		}
+	}
+	down := len(s.Drones) - len(patrolling)
+	free := capacity - down
+	if free <= 0 {
+		return
+	}
+
+	endurance := make(map[int]float64, len(patrolling))
+	for _, d := range patrolling {
+		endurance[d.ID] = d.Endurance()
+	}
+	sort.SliceStable(patrolling, func(i, j int) bool {
+		return endurance[patrolling[i].ID] < endurance[patrolling[j].ID]
+	})
+
+	latest := make([]float64, len(patrolling))
+	for k := len(patrolling) - 1; k >= 0; k-- {
+		latest[k] = endurance[patrolling[k].ID]
+		if k+capacity < len(patrolling) {
+			latest[k] = math.Min(latest[k], latest[k+capacity]-float64(patrolling[k].ChargingCycle()))
+		}
+	}
+
+	for k, d := range patrolling {
+		if free == 0 {
+			break
+		}
+		if latest[k] > 1 {
+			continue
+		}
+		if endurance[d.ID] > 1 {
+			s.FleetStats.EarlyCharges++
+			if s.debug {
+				fmt.Printf("[FLEET] Drone %d sent to charge early, %.0f ticks of endurance left\n", d.ID, endurance[d.ID])
+			}
+		}
+		d.SendToCharge()
+		free--
+	}
+}
+
+// collectFleetStats counts the drones in the air.
+func (s *Simulation) collectFleetStats() {
+	airborne := 0
+	for i := range s.Drones {
+		if s.Drones[i].Airborne() {
+			airborne++
+		}
+	}
+	stats := &s.FleetStats
+	if stats.Ticks == 0 || airborne < stats.LowestAirborne {
+		stats.LowestAirborne = airborne
+	}
+	stats.Ticks++
+	stats.Airborne += (float64(airborne) - stats.Airborne) / float64(stats.Ticks)
+	if s.currentTick < s.festivalTotalTicks && airborne < s.requiredAirborne() {
+		stats.TicksBelowMin++
+	}
+}
@@ -95,7 +95,7 @@ func (m *Map) AddCrowdMember(member *persons.Person) {
 func (m *Map) AddDrone(drone *drones.Drone) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	cell := m.Cells[drone.Position]
+	cell := m.Cells[drone.Position.Cell()]
 	cell.Drones = append(cell.Drones, drone)
 }
 
@@ -105,8 +105,8 @@ func (m *Map) MoveEntity(entity interface{}, newPosition models.Position) {
 	m.mu.RLock()
 	switch e := entity.(type) {
 	case *drones.Drone:
-		currentCell = m.Cells[e.Position]
-		newCell = m.Cells[newPosition]
+		currentCell = m.Cells[e.Position.Cell()]
+		newCell = m.Cells[newPosition.Cell()]
 		removeDroneFromCell(currentCell, e)
 		newCell.Drones = append(newCell.Drones, e)
 		e.Position = newPosition
@@ -136,7 +136,7 @@ func (m *Map) RemoveEntity(entity interface{}) {
 	var currentCell *MapCell
 	switch e := entity.(type) {
 	case *drones.Drone:
-		currentCell = m.Cells[e.Position]
+		currentCell = m.Cells[e.Position.Cell()]
 		removeDroneFromCell(currentCell, e)
 
 	case *persons.Person:
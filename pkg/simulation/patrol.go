@@ -0,0 +1,122 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+// PatrolStats measures how soon the patrol finds the people in distress, with or without the
+// planner, and the work of the planners.
+type PatrolStats struct {
+	Incidents      int // People in distress read for the first time
+	DetectionTicks int // Ticks in distress before the first read, summed over the incidents
+	Waypoints      int // Waypoints chosen by the planners
+	Shared         int // Cell observations sent to the neighbours
+	Merged         int // Cell observations received newer than the belief of the drone
+}
+
+// AverageDetectionTicks returns the average ticks a person stays in distress before a drone reads it.
+func (p PatrolStats) AverageDetectionTicks() float64 {
+	if p.Incidents == 0 {
+		return 0
+	}
+	return float64(p.DetectionTicks) / float64(p.Incidents)
+}
+
+// UpdatePatrolPlanner gives each drone a belief map shared with its neighbours: the patrol flies to the
+// waypoints with the most expected detections instead of sweeping the zone or moving randomly.
+func (s *Simulation) UpdatePatrolPlanner(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PatrolPlanner = enabled
+	for i := range s.Drones {
+		s.Drones[i].Belief = s.newBeliefMap()
+	}
+	if enabled {
+		fmt.Println("Patrol planner: shared belief maps")
+	}
+}
+
+// newBeliefMap returns the belief map of a new drone, nil without the planner.
+func (s *Simulation) newBeliefMap() *drones.BeliefMap {
+	if !s.PatrolPlanner {
+		return nil
+	}
+	return drones.NewBeliefMap(s.Map.Width, s.Map.Height, s.poiMap, s.beliefPrior())
+}
+
+// beliefPrior returns the prior shared by the belief maps, made for the size of the map.
+func (s *Simulation) beliefPrior() []float64 {
+	if len(s.crowdPrior) != s.Map.Width*s.Map.Height {
+		s.crowdPrior = make([]float64, s.Map.Width*s.Map.Height)
+		s.fillCrowdPrior()
+	}
+	return s.crowdPrior
+}
+
+// refreshCrowdPrior updates the prior of the belief maps every BELIEF_CROWD_TICKS, as the crowd moves
+// on. It runs between the turns of the drones, which only read it.
+func (s *Simulation) refreshCrowdPrior() {
+	if !s.PatrolPlanner || s.currentTick%int(drones.BELIEF_CROWD_TICKS) != 0 {
+		return
+	}
+	s.beliefPrior()
+	s.fillCrowdPrior()
+}
+
+// fillCrowdPrior turns the crowd density around each cell into people expected in the cell, like a
+// density map of the organisers: the drones know where the crowd is, not who is in distress. Before
+// the crowd arrives, the attendees are spread evenly over the map.
+func (s *Simulation) fillCrowdPrior() {
+	prior := s.crowdPrior
+	if len(s.CrowdField) == 0 {
+		even := float64(len(s.Persons)) / float64(len(prior))
+		for i := range prior {
+			prior[i] = even
+		}
+		return
+	}
+	for i := range prior {
+		prior[i] = 0
+	}
+	for cell, crowd := range s.CrowdField {
+		x, y := int(cell.X), int(cell.Y)
+		if x < 0 || y < 0 || x >= s.Map.Width || y >= s.Map.Height {
+			continue
+		}
+		prior[y*s.Map.Width+x] = crowd.Density * models.METERS_PER_UNIT * models.METERS_PER_UNIT / models.PEOPLE_PER_AGENT
+	}
+}
+
+// recordDetection counts the first read of a person in distress, and how long the incident went
+// unseen. The caller holds s.sensorMu.
+func (s *Simulation) recordDetection(person *persons.Person) {
+	if s.detectedIncidents[person.ID] {
+		return
+	}
+	s.detectedIncidents[person.ID] = true
+	s.PatrolStats.Incidents++
+	s.PatrolStats.DetectionTicks += person.CurrentDistressDuration
+}
+
+func (s *Simulation) collectPatrolStats() {
+	s.sensorMu.Lock()
+	// Un nouvel épisode de détresse compte comme un nouvel incident
+	for personID := range s.detectedIncidents {
+		if person := s.findPerson(personID); person == nil || !person.IsInDistress() {
+			delete(s.detectedIncidents, personID)
+		}
+	}
+	s.sensorMu.Unlock()
+
+	stats := &s.PatrolStats
+	stats.Waypoints, stats.Shared, stats.Merged = 0, 0, 0
+	for i := range s.Drones {
+		counters := s.Drones[i].Beliefs
+		stats.Waypoints += counters.Waypoints
+		stats.Shared += counters.Shared
+		stats.Merged += counters.Merged
+	}
+}
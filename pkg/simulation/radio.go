@@ -0,0 +1,266 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/bus"
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// RadioModel describes the links between the drones, and between a drone and a rescue point.
+type RadioModel struct {
+	Latency      int     // Ticks between the sending and the delivery of a message, 0 to deliver at once
+	BaseLoss     float64 // Loss probability of a short link
+	RangeLoss    float64 // Extra loss at the edge of the comm range, grows with the square of the distance
+	DensityLoss  float64 // Extra loss per person/m² along the link, bodies absorb the signal
+	ObstacleLoss float64 // Loss of a link crossing a stage
+	Bandwidth    int     // Messages a drone sends per tick, the others wait; 0 for no limit
+}
+
+// DefaultRadioModel is a 2.4 GHz mesh link: a message is relayed at the next tick.
+var DefaultRadioModel = RadioModel{
+	Latency:      1,
+	BaseLoss:     0.02,
+	RangeLoss:    0.25,
+	DensityLoss:  0.04,
+	ObstacleLoss: 0.6,
+	Bandwidth:    4,
+}
+
+// IdealRadioModel delivers every message at once, like the drones did before the radio layer.
+var IdealRadioModel = RadioModel{}
+
+// RadioStats counts the messages of the drones and what happened to them.
+type RadioStats struct {
+	Sent         int
+	Delivered    int
+	Faded        int // Lost to distance and crowd density
+	Obstructed   int // Lost behind a stage
+	OutOfRange   int // Lost because no chain of links reached the peer at the transmission
+	Hops         int // Summed over the delivered messages
+	QueuedTicks  int // Message-ticks waiting for bandwidth
+	LatencyTicks int // Summed over the delivered messages
+	Reports      int // Transmissions to a rescue point
+	LostReports  int // Transmissions to a rescue point lost, retried at the next tick
+}
+
+// AverageHops returns the links crossed, averaged over the delivered messages.
+func (r RadioStats) AverageHops() float64 {
+	if r.Delivered == 0 {
+		return 0
+	}
+	return float64(r.Hops) / float64(r.Delivered)
+}
+
+// AverageLatency returns the ticks between the sending and the delivery, averaged over the delivered messages.
+func (r RadioStats) AverageLatency() float64 {
+	if r.Delivered == 0 {
+		return 0
+	}
+	return float64(r.LatencyTicks) / float64(r.Delivered)
+}
+
+// DeliveryRate returns the share of the drone messages delivered.
+func (r RadioStats) DeliveryRate() float64 {
+	if r.Sent == 0 {
+		return 1
+	}
+	return float64(r.Delivered) / float64(r.Sent)
+}
+
+type radioMessage struct {
+	From        *drones.Drone
+	To          *drones.Drone
+	Msg         bus.Message
+	SentTick    int
+	DeliverTick int // 0 while waiting for bandwidth
+}
+
+// Radio carries the messages of the drones with the RadioModel of the simulation.
+type Radio struct {
+	Model    RadioModel
+	outbox   []radioMessage // Waiting for bandwidth
+	inFlight []radioMessage
+	mu       sync.Mutex
+}
+
+func NewRadio(model RadioModel) *Radio {
+	return &Radio{Model: model}
+}
+
+// UpdateRadioModel sets the radio links of the drones.
+func (s *Simulation) UpdateRadioModel(model RadioModel) {
+	s.Radio.mu.Lock()
+	defer s.Radio.mu.Unlock()
+	s.Radio.Model = model
+}
+
+// radioSend is given to the drones: the message waits for the next transmission of the radio,
+// or is delivered at once with an ideal radio.
+func (s *Simulation) radioSend(from, to *drones.Drone, msg bus.Message) {
+	s.Radio.mu.Lock()
+	model := s.Radio.Model
+	s.RadioStats.Sent++
+	s.BusStats.Messages[msg.Type]++
+	if model == IdealRadioModel {
+		s.RadioStats.Delivered++
+		s.Radio.mu.Unlock()
+		from.Send(to, msg)
+		return
+	}
+	s.Radio.outbox = append(s.Radio.outbox, radioMessage{From: from, To: to, Msg: msg, SentTick: s.currentTick})
+	s.Radio.mu.Unlock()
+}
+
+// radioLink is given to the drones: it tells if a transmission to a rescue point at pos gets through.
+func (s *Simulation) radioLink(from *drones.Drone, pos models.Position) bool {
+	s.Radio.mu.Lock()
+	defer s.Radio.mu.Unlock()
+	s.RadioStats.Reports++
+	if s.radioDown(from) {
+		s.RadioStats.LostReports++
+		return false
+	}
+	if faded, obstructed := s.linkLost(from.TruePosition(), pos, float64(from.DroneCommRange)); faded || obstructed {
+		s.RadioStats.LostReports++
+		return false
+	}
+	return true
+}
+
+// linkLost draws the fate of a transmission between two cells, for a link of the given range.
+func (s *Simulation) linkLost(from, to models.Position, commRange float64) (faded, obstructed bool) {
+	model := s.Radio.Model
+	if model == IdealRadioModel {
+		return false, false
+	}
+	dist := from.CalculateDistance(to)
+	steps := int(math.Ceil(math.Max(math.Abs(to.X-from.X), math.Abs(to.Y-from.Y))))
+	density, crossesStage := 0.0, false
+	for k := 1; k < steps; k++ {
+		cell := models.Position{
+			X: math.Round(from.X + (to.X-from.X)*float64(k)/float64(steps)),
+			Y: math.Round(from.Y + (to.Y-from.Y)*float64(k)/float64(steps)),
+		}
+		density += s.CrowdField[cell].Density
+		if mapCell, exists := s.Map.Cells[cell]; exists {
+			for _, obstacle := range mapCell.Obstacles {
+				if poi := obstacle.GetPOIType(); poi == models.MainStage || poi == models.SecondaryStage {
+					crossesStage = true
+				}
+			}
+		}
+	}
+	if steps > 1 {
+		density /= float64(steps - 1)
+	}
+
+	if crossesStage && rand.Float64() < model.ObstacleLoss {
+		return false, true
+	}
+	ratio := dist / commRange
+	loss := model.BaseLoss + model.RangeLoss*ratio*ratio + model.DensityLoss*density
+	return rand.Float64() < math.Min(1, loss), false
+}
+
+// radioRoute returns the shortest chain of links from a drone to another, both included,
+// nil when they are not connected.
+func (s *Simulation) radioRoute(from, to *drones.Drone) []*drones.Drone {
+	previous := map[*drones.Drone]*drones.Drone{from: nil}
+	queue := []*drones.Drone{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			route := make([]*drones.Drone, 0)
+			for d := to; d != nil; d = previous[d] {
+				route = append([]*drones.Drone{d}, route...)
+			}
+			return route
+		}
+		for i := range s.Drones {
+			next := &s.Drones[i]
+			if _, seen := previous[next]; !seen && s.linked(current, next) {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+func isStatusMessage(msg bus.Message) bool {
+	return msg.Type == bus.Heartbeat || msg.Type == bus.PositionBeacon
+}
+
+// updateRadio transmits the messages waiting in the outbox within the bandwidth of each drone,
+// heartbeats and beacons last, and delivers the messages due. A message is relayed link by link along the shortest route,
+// each link can lose it and adds Latency. The delivery happens before the drones think, so a
+// message sent to a neighbour during a tick is read at the next one with the default latency.
+func (s *Simulation) updateRadio() {
+	s.Radio.mu.Lock()
+	model := s.Radio.Model
+	sentBy := make(map[*drones.Drone]int)
+	// Les heartbeats et les balises cèdent la bande passante aux autres messages
+	sort.SliceStable(s.Radio.outbox, func(i, j int) bool {
+		return !isStatusMessage(s.Radio.outbox[i].Msg) && isStatusMessage(s.Radio.outbox[j].Msg)
+	})
+	waiting := s.Radio.outbox[:0]
+	for _, rm := range s.Radio.outbox {
+		if model.Bandwidth > 0 && sentBy[rm.From] >= model.Bandwidth {
+			s.RadioStats.QueuedTicks++
+			waiting = append(waiting, rm)
+			continue
+		}
+		sentBy[rm.From]++
+
+		route := s.radioRoute(rm.From, rm.To)
+		lost := route == nil
+		if lost {
+			s.RadioStats.OutOfRange++
+		}
+		for hop := 1; hop < len(route) && !lost; hop++ {
+			faded, obstructed := s.linkLost(route[hop-1].TruePosition(), route[hop].TruePosition(), linkRange(route[hop-1], route[hop]))
+			if obstructed {
+				s.RadioStats.Obstructed++
+				lost = true
+			} else if faded {
+				s.RadioStats.Faded++
+				lost = true
+			}
+		}
+		if lost {
+			if s.debug {
+				fmt.Printf("[RADIO] %s of drone %d to drone %d lost\n", rm.Msg.Type, rm.From.ID, rm.To.ID)
+			}
+			continue
+		}
+		hops := len(route) - 1
+		rm.DeliverTick = rm.SentTick + model.Latency*hops
+		s.RadioStats.Hops += hops
+		s.Radio.inFlight = append(s.Radio.inFlight, rm)
+	}
+	s.Radio.outbox = waiting
+
+	due := make([]radioMessage, 0)
+	inFlight := s.Radio.inFlight[:0]
+	for _, rm := range s.Radio.inFlight {
+		if rm.DeliverTick <= s.currentTick {
+			due = append(due, rm)
+			s.RadioStats.Delivered++
+			s.RadioStats.LatencyTicks += s.currentTick - rm.SentTick
+		} else {
+			inFlight = append(inFlight, rm)
+		}
+	}
+	s.Radio.inFlight = inFlight
+	s.Radio.mu.Unlock()
+
+	for _, rm := range due {
+		rm.From.Send(rm.To, rm.Msg)
+	}
+}
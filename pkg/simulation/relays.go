@@ -0,0 +1,232 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	RELAY_PLAN_INTERVAL = 5   // Ticks between two placements of the relays
+	RELAY_SPACING       = 0.8 // Share of the comm range between two posts, margin for the fading and the GPS
+	RELAY_KEEP_DISTANCE = 2.0 // A relay keeps a post moved by less than this, instead of being replaced
+)
+
+// RelayStats counts the drones taken off patrol to keep every patrol zone linked to a rescue point.
+type RelayStats struct {
+	Plans         int // Placements that sent drones from patrol to a post, or back
+	Posts         int // Posts of these placements, summed
+	Unreachable   int // Cut-off zones left without relays at a placement, the chain needed more drones than allowed
+	RelayTicks    int // Drone-ticks spent holding a post instead of patrolling
+	IsolatedTicks int // Drone-ticks of patrolling drones without a route to a rescue point
+}
+
+// UpdateRelayPlacement lets the simulation take up to maxRelays drones off patrol to link the
+// cut-off zones to a rescue point, 0 to disable it.
+func (s *Simulation) UpdateRelayPlacement(maxRelays int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MaxRelays = maxRelays
+	if maxRelays == 0 {
+		for i := range s.Drones {
+			s.Drones[i].RelayPost = nil
+		}
+	}
+	fmt.Printf("Relay placement: up to %d drones\n", maxRelays)
+}
+
+// isAvailable tells if the drone is flying and free for a zone or a post.
+func isAvailable(d *drones.Drone) bool {
+	return d.Airborne() && d.DroneState == drones.NoDefinedState &&
+		d.Evacuation == nil && d.Search == nil
+}
+
+// connectedToRescuePoint tells if a rescue point is reached from the drone, itself or through its network.
+func (s *Simulation) connectedToRescuePoint(d *drones.Drone) bool {
+	network := append(s.calculateSingleDroneNetwork(d).Drones, d)
+	for _, relay := range network {
+		for _, rp := range s.RescuePoints {
+			if s.rescuePointLinked(relay, rp) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// placeRelays keeps a multi-hop path from the centre of every patrol zone to a rescue point. A zone
+// is linked when its centre is in range of a rescue point, of a post or of a linked zone; the other
+// zones get a chain of posts from the closest rescue point or post. The posts go to the closest free
+// drones, relay drones first, the posts still needed keep their drone. The zones are then split
+// among the drones left on patrol.
+func (s *Simulation) placeRelays() {
+	if s.MaxRelays == 0 || s.currentTick%RELAY_PLAN_INTERVAL != 0 {
+		return
+	}
+	candidates := make([]*drones.Drone, 0, len(s.Drones))
+	step := math.Inf(1)
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		if !isAvailable(d) {
+			d.RelayPost = nil
+			continue
+		}
+		candidates = append(candidates, d)
+		step = math.Min(step, float64(d.DroneCommRange)*RELAY_SPACING)
+	}
+	anchors := make([]models.Position, 0, len(s.RescuePoints))
+	for _, rp := range s.RescuePoints {
+		if !s.rescuePointOffline(rp) {
+			anchors = append(anchors, rp.Position)
+		}
+	}
+	if len(candidates) == 0 || len(anchors) == 0 {
+		return
+	}
+
+	centres := make([]models.Position, 0, len(candidates))
+	for _, d := range candidates {
+		if d.RelayPost == nil {
+			centres = append(centres, zoneCentre(d.MyWatch))
+		}
+	}
+	posts := make([]models.Position, 0)
+	linked := make([]models.Position, len(anchors))
+	copy(linked, anchors)
+	for len(centres) > 0 {
+		// Propager le lien de proche en proche entre les centres des zones
+		progress := true
+		for progress {
+			progress = false
+			for i := 0; i < len(centres); i++ {
+				if centres[i].CalculateDistance(closestPosition(linked, centres[i])) <= step {
+					linked = append(linked, centres[i])
+					centres = append(centres[:i], centres[i+1:]...)
+					i--
+					progress = true
+				}
+			}
+		}
+		if len(centres) == 0 {
+			break
+		}
+		// La zone coupée la plus proche reçoit une chaîne depuis un point de secours ou un poste
+		sort.SliceStable(centres, func(i, j int) bool {
+			return centres[i].CalculateDistance(closestPosition(anchors, centres[i])) <
+				centres[j].CalculateDistance(closestPosition(anchors, centres[j]))
+		})
+		centre := centres[0]
+		chain := relayChain(closestPosition(anchors, centre), centre, step)
+		if len(posts)+len(chain) > s.MaxRelays || len(posts)+len(chain) >= len(candidates) {
+			s.RelayStats.Unreachable++
+			centres = centres[1:]
+			continue
+		}
+		posts = append(posts, chain...)
+		anchors = append(anchors, chain...)
+		linked = append(linked, chain...)
+	}
+
+	changed := s.assignPosts(candidates, posts)
+	if changed {
+		s.RelayStats.Plans++
+		s.RelayStats.Posts += len(posts)
+	}
+}
+
+// assignPosts gives each post to the relay holding a post close to it, or to the closest free drone,
+// and sends the relays no longer needed back to patrol. It tells if a drone changed of task.
+func (s *Simulation) assignPosts(candidates []*drones.Drone, posts []models.Position) bool {
+	changed := false
+	held := make(map[int]*drones.Drone)
+	for _, d := range candidates {
+		if d.RelayPost == nil {
+			continue
+		}
+		kept := false
+		for i, post := range posts {
+			if _, taken := held[i]; !taken && d.RelayPost.CalculateDistance(post) <= RELAY_KEEP_DISTANCE {
+				post := post
+				d.RelayPost = &post
+				held[i] = d
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			d.RelayPost = nil
+			changed = true
+		}
+	}
+
+	free := make([]*drones.Drone, 0, len(candidates))
+	for _, d := range candidates {
+		if d.RelayPost == nil {
+			free = append(free, d)
+		}
+	}
+	for i, post := range posts {
+		if _, taken := held[i]; taken || len(free) == 0 {
+			continue
+		}
+		sort.SliceStable(free, func(a, b int) bool {
+			if free[a].IsRelay() != free[b].IsRelay() {
+				return free[a].IsRelay()
+			}
+			return free[a].Position.CalculateDistance(post) < free[b].Position.CalculateDistance(post)
+		})
+		relay := free[0]
+		free = free[1:]
+		post := post
+		relay.RelayPost = &post
+		changed = true
+		if s.debug {
+			fmt.Printf("[DRONE %d] - Relay post (%.0f, %.0f)\n", relay.ID, post.X, post.Y)
+		}
+	}
+	return changed
+}
+
+// relayChain returns the posts between from and to, spaced by step at most, both ends excluded.
+func relayChain(from, to models.Position, step float64) []models.Position {
+	dist := from.CalculateDistance(to)
+	count := int(math.Ceil(dist/step)) - 1
+	chain := make([]models.Position, 0, max(count, 0))
+	for k := 1; k <= count; k++ {
+		ratio := float64(k) / float64(count+1)
+		chain = append(chain, models.Position{
+			X: math.Round(from.X + (to.X-from.X)*ratio),
+			Y: math.Round(from.Y + (to.Y-from.Y)*ratio),
+		})
+	}
+	return chain
+}
+
+func closestPosition(positions []models.Position, pos models.Position) models.Position {
+	closest := positions[0]
+	for _, candidate := range positions[1:] {
+		if candidate.CalculateDistance(pos) < closest.CalculateDistance(pos) {
+			closest = candidate
+		}
+	}
+	return closest
+}
+
+func (s *Simulation) collectRelayStats() {
+	if s.MaxRelays == 0 {
+		return
+	}
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		if !d.Airborne() {
+			continue
+		}
+		if d.RelayPost != nil {
+			s.RelayStats.RelayTicks++
+		} else if isPatrolling(d) && !s.connectedToRescuePoint(d) {
+			s.RelayStats.IsolatedTicks++
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/entities/rescue"
+	"UTC_IA04/pkg/models"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	LOST_PROBABILITY          = 0.00005 // Per present person and per tick
+	CHILD_LOST_FACTOR         = 5       // Children get lost more often
+	LOST_REPORT_DELAY         = 5       // Ticks before the group notices and tells a rescue point
+	SEARCH_DRONES_PER_MISSION = 2
+)
+
+// SearchStats summarizes the missing-person searches of a run.
+type SearchStats struct {
+	Missions       int
+	Found          int
+	Cancelled      int
+	TimeToFind     []int // Ticks between the report and the confirmed sighting
+	FalseSightings int   // Sighted people matching the description who were not the missing person
+}
+
+// MarkPersonLost separates a person from their group, the search starts once it is reported.
+func (s *Simulation) MarkPersonLost(personID int) bool {
+	p := s.findPerson(personID)
+	if p == nil || !p.Arrived || !p.StillInSim || p.IsDead() || p.Lost {
+		return false
+	}
+	p.GetLost(s.currentTick)
+	s.missingReports[p.ID] = p.Position
+	return true
+}
+
+// updateSearchMissions loses people, reports them to a rescue point, reunites the ones found
+// and broadcasts the open missions to the fleet.
+func (s *Simulation) updateSearchMissions() {
+	open := make([]models.SearchMission, 0)
+	for _, rp := range s.RescuePoints {
+		for _, mission := range rp.SearchMissionsSnapshot() {
+			p := s.findPerson(mission.PersonID)
+			if mission.Found && p != nil && p.Lost && p.LostTick <= mission.ReportedTick {
+				// Retrouvé : la personne rejoint son groupe
+				p.Lost = false
+			}
+			if !mission.Open() {
+				continue
+			}
+			if p == nil || !p.StillInSim || p.IsDead() {
+				rp.CancelSearchMission(mission.ID)
+				continue
+			}
+			open = append(open, mission)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].ID < open[j].ID })
+
+	for i := range s.Persons {
+		p := &s.Persons[i]
+		if !p.Arrived || !p.StillInSim || p.IsDead() || p.Lost || p.InDistress || p.SeekingExit || s.IsEvacuating() {
+			continue
+		}
+		probability := LOST_PROBABILITY
+		if p.Appearance.Child {
+			probability *= CHILD_LOST_FACTOR
+		}
+		if rand.Float64() < probability {
+			s.MarkPersonLost(p.ID)
+		}
+	}
+
+	for personID, lastSeen := range s.missingReports {
+		p := s.findPerson(personID)
+		if p == nil || !p.Lost {
+			delete(s.missingReports, personID)
+			continue
+		}
+		if s.currentTick-p.LostTick < LOST_REPORT_DELAY {
+			continue
+		}
+		rp := s.closestRescuePoint(lastSeen)
+		if rp == nil {
+			continue
+		}
+		mission := rp.OpenSearchMission(models.SearchMission{
+			ID:           s.nextSearchMissionID,
+			PersonID:     p.ID,
+			Description:  p.Appearance,
+			LastSeen:     lastSeen,
+			LostTick:     p.LostTick,
+			ReportedTick: s.currentTick,
+		})
+		s.nextSearchMissionID++
+		delete(s.missingReports, personID)
+		open = append(open, *mission)
+	}
+
+	s.broadcastSearchMissions(open)
+}
+
+// broadcastSearchMissions gives every drone the open missions and dedicates the closest free drones to each of them.
+func (s *Simulation) broadcastSearchMissions(open []models.SearchMission) {
+	assigned := make(map[int]int)
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		d.SearchMissions = open
+		if d.Search != nil && !s.isOpenMission(d.Search.MissionID, open) {
+			d.Search = nil
+		}
+		if d.Search != nil {
+			assigned[d.Search.MissionID]++
+		}
+	}
+
+	for _, mission := range open {
+		for assigned[mission.ID] < SEARCH_DRONES_PER_MISSION {
+			var best *drones.Drone
+			bestDist := math.Inf(1)
+			for i := range s.Drones {
+				d := &s.Drones[i]
+				if d.Search != nil || d.IsCharging || d.DroneState != drones.NoDefinedState || d.Evacuation != nil {
+					continue
+				}
+				if dist := d.Position.CalculateDistance(mission.LastSeen); dist < bestDist {
+					bestDist = dist
+					best = d
+				}
+			}
+			if best == nil {
+				break
+			}
+			best.AssignSearch(mission, assigned[mission.ID])
+			assigned[mission.ID]++
+		}
+	}
+}
+
+func (s *Simulation) isOpenMission(missionID int, open []models.SearchMission) bool {
+	for _, mission := range open {
+		if mission.ID == missionID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Simulation) findPerson(personID int) *persons.Person {
+	if personID >= 0 && personID < len(s.Persons) && s.Persons[personID].ID == personID {
+		return &s.Persons[personID]
+	}
+	for i := range s.Persons {
+		if s.Persons[i].ID == personID {
+			return &s.Persons[i]
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) closestRescuePoint(pos models.Position) *rescue.RescuePoint {
+	var closest *rescue.RescuePoint
+	minDist := math.Inf(1)
+	for _, rp := range s.RescuePoints {
+		dist := pos.CalculateDistance(rp.Position)
+		if dist < minDist {
+			minDist = dist
+			closest = rp
+		}
+	}
+	return closest
+}
+
+// GetSearchMissions returns the open search missions of every rescue point.
+func (s *Simulation) GetSearchMissions() []models.SearchMission {
+	missions := make([]models.SearchMission, 0)
+	for _, rp := range s.RescuePoints {
+		for _, mission := range rp.SearchMissionsSnapshot() {
+			if mission.Open() {
+				missions = append(missions, mission)
+			}
+		}
+	}
+	return missions
+}
+
+// collectSearchStats recounts the missions of every rescue point.
+func (s *Simulation) collectSearchStats() {
+	stats := SearchStats{TimeToFind: make([]int, 0)}
+	for _, rp := range s.RescuePoints {
+		for _, mission := range rp.SearchMissionsSnapshot() {
+			stats.Missions++
+			if mission.Found {
+				stats.Found++
+				stats.TimeToFind = append(stats.TimeToFind, mission.TimeToFind())
+			}
+			if mission.Cancelled {
+				stats.Cancelled++
+			}
+			for _, sighting := range mission.Sightings {
+				if !sighting.Confirmed {
+					stats.FalseSightings++
+				}
+			}
+		}
+	}
+	s.SearchStats = stats
+}
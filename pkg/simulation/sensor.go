@@ -0,0 +1,212 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/entities/persons"
+	"UTC_IA04/pkg/models"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+// Lumière du jour, selon l'horloge du festival (FestivalClock)
+const (
+	SUNSET_HOUR    = 21.5
+	SUNRISE_HOUR   = 6.0
+	TWILIGHT_TICKS = 45 // Ticks for the light to fade, or to come back
+)
+
+// SensorStats counts what the cameras of the drones read, and what their false alarms cost.
+type SensorStats struct {
+	Detections         int // People detected, summed over the drones and the ticks
+	DistressReads      int // People in distress read as in distress
+	MissedDistress     int // People in distress read as fine
+	FalseReads         int // People who are fine read as in distress
+	FalseAlarms        int // Rescuers who reached a person read in distress by mistake
+	WastedRescuerTicks int // Rescuer-ticks spent walking to a false alarm
+	NightTicks         int
+}
+
+// Precision returns the share of the distress reads that were right.
+func (s SensorStats) Precision() float64 {
+	if s.DistressReads+s.FalseReads == 0 {
+		return 1
+	}
+	return float64(s.DistressReads) / float64(s.DistressReads+s.FalseReads)
+}
+
+// Recall returns the share of the people in distress detected that were read as such.
+func (s SensorStats) Recall() float64 {
+	if s.DistressReads+s.MissedDistress == 0 {
+		return 1
+	}
+	return float64(s.DistressReads) / float64(s.DistressReads+s.MissedDistress)
+}
+
+func LoadSensorModel(modelPath string) (*models.SensorModel, error) {
+	absPath, err := filepath.Abs(modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path: %v", err)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sensor file: %v", err)
+	}
+
+	var model models.SensorModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("error parsing sensor file: %v", err)
+	}
+
+	return &model, nil
+}
+
+// UpdateSensorModel gives the drones the camera of configs/sensors/<name>.json, "default" for DefaultSensorModel.
+func (s *Simulation) UpdateSensorModel(nomModele string) {
+	model := models.DefaultSensorModel
+	if nomModele != "" && nomModele != model.Name {
+		configPath := "configs/sensors/" + nomModele + ".json"
+		loaded, err := LoadSensorModel(configPath)
+		if err != nil {
+			fmt.Printf("Warning: Could not load sensor model from %s: %v\n", configPath, err)
+			return
+		}
+		model = *loaded
+		if model.Name == "" {
+			model.Name = nomModele
+		}
+	}
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	s.SensorModel = model
+	fmt.Printf("Sensor model %s loaded\n", model.Name)
+}
+
+// Daylight returns 1 in daylight, 0 at night, and fades in between.
+func (s *Simulation) Daylight() float64 {
+	return s.DaylightAt(s.currentTick)
+}
+
+// DaylightAt returns the daylight of a tick with the festival clock.
+func (s *Simulation) DaylightAt(tick int) float64 {
+	clock := s.FestivalClock(tick)
+	hour := float64(clock.Hour()) + float64(clock.Minute())/60 + float64(clock.Second())/3600
+	twilight := TWILIGHT_TICKS * s.tickSeconds / 3600
+	switch {
+	case hour >= SUNRISE_HOUR && hour < SUNSET_HOUR:
+		return math.Min(1, (hour-SUNRISE_HOUR)/twilight)
+	case hour >= SUNSET_HOUR:
+		return math.Max(0, 1-(hour-SUNSET_HOUR)/twilight)
+	default:
+		return 0
+	}
+}
+
+// detectPeople is the camera of a drone: each person in range is detected with the chance of
+// the sensor model, lower far from the drone, in a dense crowd, in the rain and in the dark.
+func (s *Simulation) detectPeople(d *drones.Drone) []*persons.Person {
+	if s.sensorDown(d) {
+		return []*persons.Person{}
+	}
+	s.sensorMu.Lock()
+	model := s.sensorOf(d)
+	s.sensorMu.Unlock()
+
+	currentCell := d.TruePosition()
+	vector := models.Vector(currentCell)
+	circle, _ := vector.GenerateCircleValues(d.DroneSeeRange)
+	visibility := s.GetWeather().VisibilityFactor()
+	daylight := s.Daylight()
+
+	detected := make([]*persons.Person, 0)
+	for _, offset := range circle {
+		position := models.Position{X: currentCell.X + offset.X, Y: currentCell.Y + offset.Y}
+		cell, exists := s.Map.Cells[position]
+		if !exists {
+			continue
+		}
+		chance := model.DetectionChance(currentCell.CalculateDistance(position), float64(d.DroneSeeRange),
+			s.CrowdField[position].Density, daylight) * visibility
+		for _, member := range cell.Persons {
+			if rand.Float64() < chance {
+				detected = append(detected, member)
+			}
+		}
+	}
+
+	s.sensorMu.Lock()
+	s.SensorStats.Detections += len(detected)
+	s.sensorMu.Unlock()
+	return detected
+}
+
+// classifyDistress reads a detected person as in distress or not with the rates of the sensor model,
+// and returns the confidence of the read. A person who is fine but read in distress is remembered
+// to spot the false alarm.
+func (s *Simulation) classifyDistress(d *drones.Drone, person *persons.Person) (bool, float64) {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	inDistress := person.IsInDistress()
+	daylight := s.Daylight()
+	model := s.sensorOf(d)
+	read := rand.Float64() < model.DistressChance(inDistress, daylight)
+	switch {
+	case inDistress && read:
+		s.SensorStats.DistressReads++
+		if _, exists := s.firstReads[person.ID]; !exists {
+			s.firstReads[person.ID] = s.currentTick
+		}
+		s.recordDetection(person)
+	case inDistress:
+		s.SensorStats.MissedDistress++
+	case read:
+		s.SensorStats.FalseReads++
+		s.falseReads[person.ID] = true
+		if s.debug {
+			fmt.Printf("[SENSOR] Drone %d reads person %d in distress by mistake\n", d.ID, person.ID)
+		}
+	}
+	return read, model.Confidence(read, daylight)
+}
+
+// isFalseAlarm tells if a rescuer reaching a person who is fine came for a false read.
+func (s *Simulation) isFalseAlarm(person *persons.Person) bool {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	if person.IsInDistress() || !s.falseReads[person.ID] {
+		return false
+	}
+	s.SensorStats.FalseAlarms++
+	delete(s.falseReads, person.ID)
+	return true
+}
+
+// forgetFalseRead clears the false reads of a person now really in distress, the rescuers sent for
+// the new distress do not count as wasted.
+func (s *Simulation) forgetFalseRead(person *persons.Person) {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	delete(s.falseReads, person.ID)
+}
+
+func (s *Simulation) collectSensorStats() {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	if s.Daylight() < 0.5 {
+		s.SensorStats.NightTicks++
+	}
+	for _, rp := range s.RescuePoints {
+		for _, personID := range rp.RescuerTargets() {
+			if !s.falseReads[personID] {
+				continue
+			}
+			if person := s.findPerson(personID); person != nil && !person.IsInDistress() {
+				s.SensorStats.WastedRescuerTicks++
+			}
+		}
+	}
+}
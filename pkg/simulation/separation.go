@@ -0,0 +1,110 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
+	"fmt"
+)
+
+// NEAR_MISS_DISTANCE is the distance (cells) under which two drones of the same layer count as a near-miss,
+// measured with or without the separation rule to compare them.
+const NEAR_MISS_DISTANCE = 1.0
+
+// SeparationStats counts the deconfliction of the fleet.
+type SeparationStats struct {
+	NearMisses    int // Pairs of drones of the same layer coming closer than NEAR_MISS_DISTANCE
+	NearMissTicks int // Pair-ticks spent that close
+	Refused       int // Moves refused by the simulation because they broke the separation
+	Manoeuvres    int // Targets changed by the drones because of an intruder
+	Holds         int // Ticks held in place to give way
+	Climbs        int // Changes of altitude layer
+}
+
+// UpdateSeparation sets the minimum distance between the drones of a layer and the number of layers,
+// a zero distance lets the drones share a cell.
+func (s *Simulation) UpdateSeparation(rule drones.SeparationRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Separation = rule
+	for i := range s.Drones {
+		s.Drones[i].Separation = rule
+		s.Drones[i].Layer = rule.HomeLayer(s.Drones[i].ID)
+	}
+	if rule.Enabled() {
+		fmt.Printf("Separation: %.1f cells, %d layers\n", rule.MinDistance, max(rule.Layers, 1))
+	}
+}
+
+// droneFlight is a drone as the separation rule sees it during the tick, from its true position.
+type droneFlight struct {
+	Position models.Position // True position, between the cells
+	Layer    int
+	Flying   bool // Airborne and not landing at its charging station
+}
+
+func flightOf(d *drones.Drone) droneFlight {
+	return droneFlight{
+		Position: d.TruePositionOf(d.Position),
+		Layer:    d.Layer,
+		Flying:   d.Airborne() && !d.OnFinalApproach(),
+	}
+}
+
+// moveFlight records the move of the drone, waiting for its response. The caller holds s.mu.
+func (s *Simulation) moveFlight(d *drones.Drone) {
+	if s.flights != nil {
+		s.flights[d.ID] = flightOf(d)
+	}
+}
+
+// breaksSeparation tells if the move of the drone to the true position pos on the layer brings it too close
+// to another flying drone of the layer, the drones landing at a charging station excepted. The others are
+// read from their last move, they take their turn meanwhile. The caller holds s.mu.
+func (s *Simulation) breaksSeparation(d *drones.Drone, pos models.Position, layer int) bool {
+	if !s.Separation.Enabled() || d.OnFinalApproach() {
+		return false
+	}
+	from := d.TruePositionOf(d.Position)
+	for id, other := range s.flights {
+		if id == d.ID || other.Layer != layer || !other.Flying {
+			continue
+		}
+		if s.Separation.Breaks(from, pos, other.Position) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Simulation) collectSeparationStats() {
+	nearby := make(map[[2]int]bool)
+	for i := range s.Drones {
+		a := &s.Drones[i]
+		if !a.Airborne() {
+			continue
+		}
+		posA := a.TruePositionOf(a.Position)
+		for j := i + 1; j < len(s.Drones); j++ {
+			b := &s.Drones[j]
+			if !b.Airborne() || a.Layer != b.Layer || posA.CalculateDistance(b.TruePositionOf(b.Position)) >= NEAR_MISS_DISTANCE {
+				continue
+			}
+			pair := [2]int{a.ID, b.ID}
+			nearby[pair] = true
+			s.SeparationStats.NearMissTicks++
+			if !s.nearMisses[pair] {
+				s.SeparationStats.NearMisses++
+			}
+		}
+	}
+	s.nearMisses = nearby
+
+	stats := &s.SeparationStats
+	stats.Manoeuvres, stats.Holds, stats.Climbs = 0, 0, 0
+	for i := range s.Drones {
+		counters := s.Drones[i].Avoidance
+		stats.Manoeuvres += counters.Manoeuvres
+		stats.Holds += counters.Holds
+		stats.Climbs += counters.Climbs
+	}
+}
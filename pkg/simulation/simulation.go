@@ -1,6 +1,7 @@
 package simulation
 
 import (
+	"UTC_IA04/pkg/entities/bus"
 	"UTC_IA04/pkg/entities/drones"
 	"UTC_IA04/pkg/entities/obstacles"
 	"UTC_IA04/pkg/entities/persons"
@@ -22,7 +23,8 @@ import (
 const (
 	LIFESPAN                     = 200
 	DEFAULT_DISTRESS_PROBABILITY = 0.1
-	DEFAULT_PROTOCOL_MODE        = 4
+	DEFAULT_PROTOCOL             = drones.ProtocolMultiHopOptimized
+	MAP_PROTOCOLS                = "map" // Protocol choice standing for the protocols of the festival config
 	FESTIVALTICKS                = 500
 )
 
@@ -37,6 +39,7 @@ type Simulation struct {
 	Map                        *Map
 	DroneSeeRange              int
 	DroneCommRange             int
+	DroneTypes                 []drones.DroneType // Given to the drones in turn, empty for the standard drone only
 	MoveChan                   chan models.MovementRequest
 	DeadChan                   chan models.DeadRequest
 	ExitChan                   chan models.ExitRequest
@@ -54,6 +57,8 @@ type Simulation struct {
 	festivalTotalTicks         int
 	DefaultDistressProbability float64
 	festivalTime               *FestivalTime
+	festivalStart              time.Time // Festival clock at the first tick
+	tickSeconds                float64   // Seconds simulated by a tick, fixed: the other durations are counted in ticks
 	poiMap                     map[models.POIType][]models.Position
 	mu                         sync.RWMutex
 	treatedCases               int
@@ -61,6 +66,59 @@ type Simulation struct {
 	RescuePoints               map[models.Position]*rescue.RescuePoint
 	FestivalState              FestivalState
 	SimulationRescueStats      SimulationRescueStats
+	Weather                    *Weather
+	Evacuation                 *Evacuation
+	evacMu                     sync.Mutex // Guards Evacuation, triggered from the GUI and read by the exits
+	CrowdField                 map[models.Position]models.CrowdCell
+	CrowdThresholds            models.CrowdThresholds
+	CrowdRiskStats             CrowdRiskStats
+	lastPositions              map[int]models.Position
+	SearchStats                SearchStats
+	missingReports             map[int]models.Position // Last-seen position of the lost people not reported yet
+	nextSearchMissionID        int
+	protocolMix                []string // Protocols given to the drones in turn, DEFAULT_PROTOCOL if empty
+	ZoneRebalancing            bool     // Re-partition the patrol zones among the flying drones
+	CoverageStats              CoverageStats
+	coverageGap                float64     // Share of the map seen by no drone at the last tick
+	zoneTree                   *zoneNode   // Cuts of the patrol zones, nil before the first rebalance
+	zoneLeftTick               map[int]int // Tick each drone of the fleet left its zone, by drone
+	lastRebalanceTick          int
+	MaxRelays                  int // Drones the simulation may take off patrol to link the cut-off zones, 0 to never
+	RelayStats                 RelayStats
+	ChargingStations           map[models.Position]*ChargingStation
+	ChargingReservations       bool // Drones book a pad before flying to a station
+	ChargingStats              ChargingStats
+	chargingMu                 sync.Mutex
+	FleetSchedule              FleetSchedule
+	FleetStats                 FleetStats
+	Radio                      *Radio
+	RadioStats                 RadioStats
+	BusStats                   BusStats
+	SensorModel                models.SensorModel
+	typeSensors                map[string]models.SensorModel // Cameras of the drone types that have their own, by type
+	SensorStats                SensorStats
+	falseReads                 map[int]bool // People who are fine read in distress at least once
+	firstReads                 map[int]int  // Tick of the first distress read of the people in distress not yet taken in charge
+	ConfirmationPolicy         drones.ConfirmationPolicy
+	ConfirmationStats          ConfirmationStats
+	KitStocks                  map[models.Position]map[models.Equipment]int // Kits of each medical tent for the drones
+	kitsPerTent                map[models.Equipment]int
+	kitClaims                  map[int]kitClaim // People a drone is bringing a kit to
+	DeliveryStats              DeliveryStats
+	Separation                 drones.SeparationRule
+	SeparationStats            SeparationStats
+	nearMisses                 map[[2]int]bool     // Pairs of drones in a near-miss at the last tick
+	flights                    map[int]droneFlight // Drones seen by the separation rule, by ID, moved with them under mu
+	PatrolPlanner              bool                // Drones plan their patrol on a shared belief map
+	PatrolStats                PatrolStats
+	detectedIncidents          map[int]bool // People in distress read at least once during their episode
+	crowdPrior                 []float64    // People expected in each cell, the prior shared by the belief maps
+	deliveryMu                 sync.Mutex
+	sensorMu                   sync.Mutex
+	Faults                     *FaultInjector // nil when nothing can fail
+	FaultStats                 FaultStats
+	faultsMu                   sync.RWMutex
+	dronePositions             map[int]models.Position // True positions by drone ID, frozen while the drones take their turn
 }
 
 type SimulationStatistics struct {
@@ -72,12 +130,36 @@ type SimulationStatistics struct {
 	AverageCoverage float64
 	PeopleDensity   models.DensityGrid
 	DroneNetwork    models.DroneNetwork
+	Weather         models.WeatherConditions
+	PresentPeople   int
+	Evacuating      bool
+	EvacuationTime  int
+	MaxDensity      float64 // persons/m²
+	MaxPressure     float64
+	CrowdAlerts     []models.CrowdAlert
+	LostPeople      int
+	SearchMissions  []models.SearchMission
+	CoverageGap     float64 // Share of the map seen by no drone
+	Airborne        int
+	ActiveFaults    int // Failures in progress
+	AvgHydration    float64
+	Dehydrated      int
+	Intoxicated     int
 }
 
 type SimulationRescueStats struct {
-	PersonsInDistress map[int]int
-	PersonsRescued    map[int]int
-	AvgRescueTime     map[int][]int
+	PersonsInDistress    map[int]int
+	PersonsRescued       map[int]int
+	AvgRescueTime        map[int][]int
+	DistressBySeverity   map[models.Severity]int
+	RescuedBySeverity    map[models.Severity]int
+	DeadBySeverity       map[models.Severity]int
+	RescueTimeBySeverity map[models.Severity][]int
+	WrongDistressTypes   int // Rescuers who found another incident type than the one reported by the drone
+	POIVisits            map[models.POIType]int
+	PersonsPresent       map[int]int // Attendance at each tick
+	DehydratedTicks      int         // Cumulated person-ticks below the dehydration threshold
+	IntoxicatedTicks     int         // Cumulated person-ticks above the intoxication threshold
 }
 
 func NewSimulation(numDrones, numCrowdMembers, numObstacles int) *Simulation {
@@ -96,15 +178,39 @@ func NewSimulation(numDrones, numCrowdMembers, numObstacles int) *Simulation {
 		festivalTotalTicks:      FESTIVALTICKS,
 		deadCases:               0,
 		festivalTime:            NewFestivalTime(),
+		festivalStart:           festivalStartTime(FESTIVAL_START_HOUR),
+		tickSeconds:             models.SECONDS_PER_TICK,
 		poiMap:                  make(map[models.POIType][]models.Position),
 		MedicalDeliveryChan:     make(chan models.MedicalDeliveryRequest),
 		SavePeopleByRescuerChan: make(chan models.RescuePeopleRequest),
 		RescuePoints:            make(map[models.Position]*rescue.RescuePoint),
 		FestivalState:           Active,
+		Weather:                 defaultWeather(),
+		CrowdField:              make(map[models.Position]models.CrowdCell),
+		CrowdThresholds:         models.DefaultCrowdThresholds,
+		lastPositions:           make(map[int]models.Position),
+		missingReports:          make(map[int]models.Position),
+		ZoneRebalancing:         true,
+		zoneLeftTick:            make(map[int]int),
+		Radio:                   NewRadio(DefaultRadioModel),
+		BusStats:                BusStats{Messages: make(map[bus.MessageType]int)},
+		SensorModel:             models.DefaultSensorModel,
+		falseReads:              make(map[int]bool),
+		firstReads:              make(map[int]int),
+		detectedIncidents:       make(map[int]bool),
+		KitStocks:               make(map[models.Position]map[models.Equipment]int),
+		kitsPerTent:             newKitStock(FIRST_AID_KITS_PER_TENT, AEDS_PER_TENT),
+		kitClaims:               make(map[int]kitClaim),
 		SimulationRescueStats: SimulationRescueStats{
-			PersonsInDistress: make(map[int]int),
-			PersonsRescued:    make(map[int]int),
-			AvgRescueTime:     make(map[int][]int),
+			PersonsInDistress:    make(map[int]int),
+			PersonsRescued:       make(map[int]int),
+			AvgRescueTime:        make(map[int][]int),
+			DistressBySeverity:   make(map[models.Severity]int),
+			RescuedBySeverity:    make(map[models.Severity]int),
+			DeadBySeverity:       make(map[models.Severity]int),
+			RescueTimeBySeverity: make(map[models.Severity][]int),
+			POIVisits:            make(map[models.POIType]int),
+			PersonsPresent:       make(map[int]int),
 		},
 	}
 	s.Initialize(numDrones, numCrowdMembers, numObstacles)
@@ -170,12 +276,46 @@ func (s *Simulation) handleSavePersonByRescuer() {
 			continue
 		}
 
+		// Le drone a vu une détresse qui n'existait pas
+		if s.isFalseAlarm(personToSave) {
+			req.ResponseChan <- models.RescuePeopleResponse{
+				Authorized: false,
+				Reason:     "False alarm",
+			}
+			continue
+		}
+
+		if !req.Done {
+			// Le secouriste arrive : il constate le type de détresse et soigne la personne sur place
+			if req.DistressType != personToSave.DistressType {
+				s.SimulationRescueStats.WrongDistressTypes++
+			}
+			personToSave.UnderTreatment = true
+			req.ResponseChan <- models.RescuePeopleResponse{
+				Authorized:   true,
+				Reason:       "Treatment started",
+				DistressType: personToSave.DistressType,
+			}
+			continue
+		}
+
+		s.recordKitOutcome(personToSave, true)
 		s.SimulationRescueStats.PersonsRescued[s.currentTick]++
 		s.SimulationRescueStats.AvgRescueTime[s.currentTick] = append(
 			s.SimulationRescueStats.AvgRescueTime[s.currentTick],
 			personToSave.CurrentDistressDuration)
+		// Un drone a pu soigner la personne avant l'arrivée du secouriste
+		if personToSave.Severity != models.SeverityNone {
+			s.SimulationRescueStats.RescuedBySeverity[personToSave.Severity]++
+			s.SimulationRescueStats.RescueTimeBySeverity[personToSave.Severity] = append(
+				s.SimulationRescueStats.RescueTimeBySeverity[personToSave.Severity],
+				personToSave.CurrentDistressDuration)
+		}
 
 		personToSave.InDistress = false
+		personToSave.UnderTreatment = false
+		personToSave.DistressType = models.NoDistress
+		personToSave.Severity = models.SeverityNone
 		s.mu.Lock()
 		s.treatedCases++
 		s.mu.Unlock()
@@ -193,83 +333,36 @@ func (s *Simulation) handleSavePersonByRescuer() {
 
 func (s *Simulation) handleSavePerson() {
 	for req := range s.SavePersonChan {
-		authorized := false
-		for _, drone := range s.Drones {
-			if drone.ID == req.DroneID {
-				if !drone.HasMedicalGear {
-					break
-				}
-				for i := range s.Persons {
-					person := &s.Persons[i]
-					if person.ID == req.PersonID {
-						if math.Round(person.Position.X) == drone.Position.X && math.Round(person.Position.Y) == drone.Position.Y {
-							if person.InDistress {
-								authorized = true
-								person.InDistress = false
-								s.mu.Lock()
-								s.treatedCases++
-								s.mu.Unlock()
-								person.CurrentDistressDuration = 0
-								person.State.CurrentState = 2
-								person.Profile.StaminaLevel = 1.0
-								person.State.UpdateState(person)
-								break
-							}
-						}
-					}
-				}
+		authorized, reason := false, "Drop failed"
+		for i := range s.Drones {
+			if s.Drones[i].ID == req.DroneID {
+				authorized, reason = s.dropKit(&s.Drones[i], req)
+				break
 			}
 		}
 		req.ResponseChan <- models.SavePersonResponse{
 			Authorized: authorized,
-			Reason:     map[bool]string{true: "Person saved", false: "Save failed"}[authorized],
+			Reason:     reason,
 		}
 	}
 }
 
 func (s *Simulation) handleMedicalDelivery() {
 	for req := range s.MedicalDeliveryChan {
-		authorized := false
-		for _, drone := range s.Drones {
-			if drone.ID == req.DroneID {
-				for _, pos := range s.poiMap[models.MedicalTent] {
-					if pos.X == drone.Position.X && pos.Y == drone.Position.Y {
-						authorized = true
-						break
-					}
+		authorized, reason := false, "Delivery failed"
+		for i := range s.Drones {
+			if s.Drones[i].ID == req.DroneID {
+				if req.Release {
+					authorized, reason = s.releaseKit(&s.Drones[i], req)
+				} else {
+					authorized, reason = s.loadKit(&s.Drones[i], req)
 				}
+				break
 			}
 		}
 		req.ResponseChan <- models.MedicalDeliveryResponse{
 			Authorized: authorized,
-			Reason:     map[bool]string{true: "Medical delivered", false: "Delivery failed"}[authorized],
-		}
-	}
-}
-
-func (s *Simulation) handleChargingRequests() {
-	for req := range s.ChargingChan {
-		s.mu.RLock()
-		// Check if position is actually a charging station
-		isChargingStation := false
-		for _, pos := range s.poiMap[models.ChargingStation] {
-			if pos == req.Position {
-				isChargingStation = true
-				break
-			}
-		}
-		s.mu.RUnlock()
-
-		if isChargingStation {
-			req.ResponseChan <- models.ChargingResponse{
-				Authorized: true,
-				Reason:     "Charging station available",
-			}
-		} else {
-			req.ResponseChan <- models.ChargingResponse{
-				Authorized: false,
-				Reason:     "Not at a charging station",
-			}
+			Reason:     reason,
 		}
 	}
 }
@@ -308,14 +401,29 @@ func (s *Simulation) handleMovementRequests() {
 			continue
 		}
 
-		if req.NewPosition.X < 0 || req.NewPosition.Y < 0 || req.NewPosition.X >= float64(s.Map.Width) || req.NewPosition.Y >= float64(s.Map.Height) {
+		pos := req.NewPosition
+		if req.MemberType == "drone" {
+			// Les drones volent entre les cases, leur case sur la carte est la plus proche
+			pos = pos.Cell()
+		}
+		if pos.X < 0 || pos.Y < 0 || pos.X >= float64(s.Map.Width) || pos.Y >= float64(s.Map.Height) {
 			req.ResponseChan <- models.MovementResponse{Authorized: false, Reason: "Position is out of bounds"}
 			continue
 		}
 
 		if req.MemberType == "drone" {
+			drone := entity.(*drones.Drone)
 			s.mu.Lock()
+			if s.breaksSeparation(drone, drone.TruePositionOf(req.NewPosition), req.Layer) {
+				s.SeparationStats.Refused++
+				s.mu.Unlock()
+				req.ResponseChan <- models.MovementResponse{Authorized: false, Reason: "Too close to another drone"}
+				continue
+			}
+			// La couche change avec le déplacement, sous le verrou où les autres déplacements la lisent
+			drone.Layer = req.Layer
 			s.Map.MoveEntity(entity, req.NewPosition)
+			s.moveFlight(drone)
 			s.mu.Unlock()
 			req.ResponseChan <- models.MovementResponse{Authorized: true, Reason: "Drones can move above obstacles"}
 		} else {
@@ -339,10 +447,14 @@ func (s *Simulation) handleDeadPerson() {
 		}
 
 		var entity interface{}
+		severity := models.SeverityNone
 		s.mu.RLock()
-		for _, person := range s.Persons {
-			if person.ID == req.MemberID {
-				entity = &person
+		for i := range s.Persons {
+			// Les autres personnes jouent leur tour, seule celle qui meurt est lue
+			if person := &s.Persons[i]; person.ID == req.MemberID {
+				entity = person
+				severity = person.Severity
+				s.recordKitOutcome(person, false)
 				break
 			}
 		}
@@ -352,7 +464,11 @@ func (s *Simulation) handleDeadPerson() {
 			s.mu.Lock()
 			s.Map.MoveEntity(entity, models.Position{X: -10, Y: -10})
 			s.deadCases++
+			s.SimulationRescueStats.DeadBySeverity[severity]++
 			s.mu.Unlock()
+			for _, rp := range s.RescuePoints {
+				rp.CancelRequest(req.MemberID)
+			}
 			req.ResponseChan <- models.DeadResponse{Authorized: true}
 		} else {
 			req.ResponseChan <- models.DeadResponse{Authorized: false}
@@ -368,16 +484,21 @@ func (s *Simulation) handleExitRequest() {
 		}
 
 		var entity interface{}
+		authorized := true
 		s.mu.RLock()
-		for _, person := range s.Persons {
-			if person.ID == req.MemberID {
-				entity = &person
+		for i := range s.Persons {
+			if person := &s.Persons[i]; person.ID == req.MemberID {
+				entity = person
+				authorized = s.exitAuthorized(person)
 				break
 			}
 		}
 		s.mu.RUnlock()
 
-		if entity != nil {
+		if entity != nil && !authorized {
+			// Sortie de secours saturée, la personne attend son tour
+			req.ResponseChan <- models.ExitResponse{Authorized: false}
+		} else if entity != nil {
 			s.mu.Lock()
 			s.Map.RemoveEntity(entity)
 			s.mu.Unlock()
@@ -410,6 +531,7 @@ func (s *Simulation) Initialize(numDrones, numCrowdMembers, numObstacles int) {
 		}
 	}
 	s.InitializeRescuePoints()
+	s.InitializeChargingStations()
 	s.createDrones(numDrones)
 	s.createInitialCrowd(numCrowdMembers)
 	s.festivalTime.Start()
@@ -434,7 +556,23 @@ func (s *Simulation) UpdateMap(nomConfig string) {
 			fmt.Println("Successfully applied festival configuration")
 			s.buildPOIMap()
 		}
+		if len(config.Weather) > 0 {
+			s.Weather = NewWeather(models.WeatherConfig{Name: nomConfig, Samples: config.Weather})
+		}
+		if config.CrowdThresholds != nil {
+			s.CrowdThresholds = *config.CrowdThresholds
+		}
+		if config.Protocols != "" {
+			s.UpdateDroneProtocole(config.Protocols)
+		}
+		if config.StartHour != nil {
+			s.festivalStart = festivalStartTime(*config.StartHour)
+		}
+		if s.currentTick == 0 {
+			s.scheduleAttendance()
+		}
 		s.InitializeRescuePoints()
+		s.InitializeChargingStations()
 	}
 }
 
@@ -473,6 +611,15 @@ func (s *Simulation) getNearestPOI(personPos models.Position, poiType models.POI
 	return nearest
 }
 
+func (s *Simulation) getPOICapacity(pos models.Position) int {
+	for _, obstacle := range s.Map.Obstacles {
+		if obstacle.Position == pos {
+			return obstacle.Capacity
+		}
+	}
+	return 0
+}
+
 func (s *Simulation) initializeDefaultObstacles(nObstacles int) {
 	for i := 0; i < nObstacles; i++ {
 		randomPOIType := models.POIType(rand.Intn(8))
@@ -502,41 +649,14 @@ func (s *Simulation) initializeDefaultObstacles(nObstacles int) {
 }
 
 func (s *Simulation) createDrones(n int) {
-	droneSeeFunction := func(d *drones.Drone) []*persons.Person {
-		currentCell := d.Position
-		rangeDrone := s.DroneSeeRange
-		Vector := models.Vector(currentCell)
-		cercleValuesFloat, _ := Vector.GenerateCircleValues(rangeDrone)
-
-		droneInformations := make([]*persons.Person, 0)
-		nbPersDetected := 0
-
-		for z := 0; z < len(cercleValuesFloat); z++ {
-			positionInCercle := cercleValuesFloat[z]
-			position := models.Position{X: d.Position.X + positionInCercle.X, Y: d.Position.Y + positionInCercle.Y}
-			if cell, exists := s.Map.Cells[position]; exists {
-				for _, member := range cell.Persons {
-					probaDetection := max(0, 1.0/float64(s.DroneSeeRange)-(float64(nbPersDetected)*0.03))
-					if rand.Float64() < probaDetection {
-						droneInformations = append(droneInformations, member)
-						nbPersDetected++
-					}
-				}
-			}
-		}
-		return droneInformations
-	}
-
 	droneInComRange := func(d *drones.Drone) []*drones.Drone {
-		rangeDrone := s.DroneCommRange
 		droneInformations := make([]*drones.Drone, 0)
 		for i := range s.Drones {
 			drone := &s.Drones[i]
 			if drone == d {
 				continue
 			}
-			dist := drone.Position.CalculateDistance(d.Position)
-			if dist <= float64(rangeDrone) {
+			if s.linked(drone, d) {
 				droneInformations = append(droneInformations, drone)
 			}
 		}
@@ -544,20 +664,6 @@ func (s *Simulation) createDrones(n int) {
 		return droneInformations
 	}
 
-	droneGetRescuePoint := func(pos models.Position) *rescue.RescuePoint {
-		var closest *rescue.RescuePoint
-		minDist := math.Inf(1)
-		for _, rp := range s.RescuePoints {
-			dist := pos.CalculateDistance(rp.Position)
-			if dist < minDist {
-				minDist = dist
-				closest = rp
-			}
-		}
-
-		return closest
-	}
-
 	getDroneNetwork := func(d *drones.Drone) drones.DroneEffectiveNetwork {
 		return s.calculateSingleDroneNetwork(d)
 	}
@@ -567,14 +673,38 @@ func (s *Simulation) createDrones(n int) {
 	for i := 0; i < n; i++ {
 		zone := positionsDrone[i]
 		battery := 60 + rand.Float64()*(100-60)
+		protocol, _ := drones.NewProtocol(s.protocolFor(i))
+		droneID := i
+		droneGetRescuePoint := func(pos models.Position) *rescue.RescuePoint {
+			// Sans radio, le drone ne joint aucun point de secours
+			if d := s.droneByID(droneID); d != nil && s.radioDown(d) {
+				return nil
+			}
+			return s.closestOnlineRescuePoint(pos)
+		}
 		d := drones.NewSurveillanceDrone(i, models.Position{X: float64((zone[0][0] + zone[1][0]) / 2), Y: float64((zone[0][1] + zone[1][1]) / 2)},
 			models.MyWatch{CornerBottomLeft: models.Position{X: float64(zone[0][0]), Y: float64(zone[0][1])}, CornerTopRight: models.Position{X: float64(zone[1][0]), Y: float64(zone[1][1])}},
 			battery, s.DroneSeeRange, s.DroneCommRange,
-			droneSeeFunction, droneInComRange, droneGetRescuePoint, getDroneNetwork,
+			s.detectPeople, droneInComRange, droneGetRescuePoint, getDroneNetwork,
 			s.MoveChan, s.poiMap, s.ChargingChan, s.MedicalDeliveryChan,
-			s.SavePersonChan, DEFAULT_PROTOCOL_MODE,
+			s.SavePersonChan, protocol,
 			s.SavePeopleByRescuerChan, s.Map.Width, s.Map.Height,
 			s.debug)
+		d.GetWeather = s.GetWeather
+		d.CrowdRiskFunc = s.crowdRiskSeenBy
+		d.GetTick = s.GetCurrentTick
+		d.ChargingWait = s.chargingWait
+		d.RadioSend = s.radioSend
+		d.RadioLink = s.radioLink
+		d.ReadDistressFunc = s.classifyDistress
+		d.TickSeconds = s.tickSeconds
+		d.Confirmation = s.ConfirmationPolicy
+		d.Separation = s.Separation
+		d.Layer = s.Separation.HomeLayer(d.ID)
+		d.Belief = s.newBeliefMap()
+		if len(s.DroneTypes) > 0 {
+			d.ApplyType(s.DroneTypes[i%len(s.DroneTypes)])
+		}
 		s.Drones = append(s.Drones, d)
 		s.Map.AddDrone(&s.Drones[len(s.Drones)-1])
 	}
@@ -615,11 +745,12 @@ func (s *Simulation) createInitialCrowd(n int) {
 	fmt.Println("Creating initial crowd")
 	for i := 0; i < n; i++ {
 		member := persons.NewCrowdMember(i,
-			models.Position{X: 0, Y: float64(rand.Intn(s.Map.Height))},
-			s.DefaultDistressProbability, LIFESPAN, s.Map.Width, s.Map.Height, s.MoveChan, s.DeadChan, s.ExitChan)
+			models.Position{X: -1, Y: -1},
+			s.DefaultDistressProbability, LIFESPAN, s.Map.Width, s.Map.Height, s.MoveChan, s.DeadChan, s.ExitChan, s.GetWeather, s.GetCurrentTick)
 		s.Persons = append(s.Persons, member)
-		s.Map.AddCrowdMember(&s.Persons[len(s.Persons)-1])
 	}
+	// Les festivaliers entrent sur la carte à leur heure d'arrivée
+	s.scheduleAttendance()
 }
 
 func (s *Simulation) Update() {
@@ -628,14 +759,12 @@ func (s *Simulation) Update() {
 
 		for i := range s.Persons {
 			p := &s.Persons[i]
-			path := models.FindPath(p.Position, models.Position{
-				X: (float64(s.Map.Width)/10)*9 + 0.1,
-				Y: p.Position.Y},
-				s.Map.Width,
-				s.Map.Height,
-				make(map[models.Position]bool))
-			p.CurrentPath = path
-			s.Persons[i].SeekingExit = true
+			if !p.Arrived {
+				// N'est jamais venu
+				p.StillInSim = false
+				continue
+			}
+			s.sendToExit(p)
 		}
 	}
 
@@ -650,6 +779,7 @@ func (s *Simulation) Update() {
 	}
 
 	rpWg.Wait()
+	s.readRescuePointInboxes()
 
 	if s.FestivalState == Ended {
 		return
@@ -660,6 +790,17 @@ func (s *Simulation) Update() {
 		fmt.Println("New Tick")
 	}
 	s.currentTick++
+	if !s.IsEvacuating() {
+		s.admitArrivals()
+	}
+	s.updateEvacuation()
+	s.updateFaults()
+	s.updateRadio()
+	if s.currentTick < s.festivalTotalTicks {
+		s.sendDepartures()
+	}
+	s.updateSearchMissions()
+	s.SimulationRescueStats.PersonsPresent[s.currentTick] = s.CountPresentPeople()
 	var wg sync.WaitGroup
 
 	if s.currentTick%1 == 0 {
@@ -678,7 +819,7 @@ func (s *Simulation) Update() {
 				wg.Add(1)
 				go func(p *persons.Person) {
 					defer wg.Done()
-					if !p.IsDead() && p.StillInSim {
+					if !p.IsDead() && p.StillInSim && p.Arrived {
 						if p.CurrentPOI != nil && p.TargetPOIPosition == nil {
 							if pos := s.getNearestPOI(p.Position, *p.CurrentPOI); pos != nil {
 								p.SetTargetPOI(*p.CurrentPOI, *pos)
@@ -695,6 +836,12 @@ func (s *Simulation) Update() {
 
 	wg.Wait()
 
+	s.updateCrowdField()
+	s.refreshCrowdPrior()
+	for _, rp := range s.RescuePoints {
+		rp.ResolveCrowdAlerts(s.currentTick)
+	}
+
 	allPeopleAreOut := true
 	for i := range s.Persons {
 		if s.Persons[i].StillInSim {
@@ -707,7 +854,7 @@ func (s *Simulation) Update() {
 	if allPeopleAreOut {
 		for i := range s.Drones {
 			s.Drones[i].DroneState = drones.FinalGoingToDock
-			if !s.Drones[i].IsCharging {
+			if !s.Drones[i].Landed() && !s.Drones[i].Crashed {
 				allDronesAreCharging = false
 			}
 		}
@@ -720,11 +867,35 @@ func (s *Simulation) Update() {
 	}
 
 	for i := range s.Persons {
-		if s.Persons[i].InDistress {
+		p := &s.Persons[i]
+		if p.InDistress {
 			s.SimulationRescueStats.PersonsInDistress[s.currentTick]++
+			if p.CurrentDistressDuration == 0 {
+				s.SimulationRescueStats.DistressBySeverity[p.Severity]++
+				s.forgetFalseRead(p)
+			}
+		}
+		if !p.StillInSim || p.Dead {
+			continue
+		}
+		// Arrivée au POI pendant ce tick
+		if p.State.CurrentState == persons.Resting && p.State.TimeInState == 0 && p.CurrentPOI != nil {
+			s.SimulationRescueStats.POIVisits[*p.CurrentPOI]++
+		}
+		if p.Needs.Hydration < persons.DEHYDRATION_THRESHOLD {
+			s.SimulationRescueStats.DehydratedTicks++
+		}
+		if p.Needs.Alcohol > persons.INTOXICATION_THRESHOLD {
+			s.SimulationRescueStats.IntoxicatedTicks++
 		}
 	}
 
+	s.scheduleCharging()
+	s.placeRelays()
+	s.rebalanceZones()
+	// Les drones lisent les positions des autres pendant que la simulation les déplace
+	s.snapshotDronePositions()
+
 	var wgDroneRecive sync.WaitGroup
 
 	updatedDrones := make(map[int]struct{})
@@ -764,6 +935,20 @@ func (s *Simulation) Update() {
 	}
 
 	wgDrone.Wait()
+	s.snapshotDronePositions()
+	s.countCrowdAlerts()
+	s.collectSearchStats()
+	s.collectCoverageStats()
+	s.collectChargingStats()
+	s.collectFleetStats()
+	s.collectFaultStats()
+	s.collectRelayStats()
+	s.collectSeparationStats()
+	s.collectPatrolStats()
+	s.collectBusStats()
+	s.collectSensorStats()
+	s.collectConfirmationStats()
+	s.collectDeliveryStats()
 }
 
 func (s *Simulation) UpdateDroneSize(newSize int) {
@@ -792,14 +977,38 @@ func (s *Simulation) UpdateDroneSize(newSize int) {
 	}
 }
 
-func (s *Simulation) UpdateDroneProtocole(newprot int) {
+// UpdateDroneProtocole gives the fleet the protocols of a comma-separated list of registered
+// names, in turn: "multi-hop,basic" alternates both protocols. MAP_PROTOCOLS gives the protocols
+// of the festival config, DEFAULT_PROTOCOL when it has none. The drones added later follow the mix.
+func (s *Simulation) UpdateDroneProtocole(protocols string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if protocols == MAP_PROTOCOLS {
+		protocols = DEFAULT_PROTOCOL
+		if s.FestivalConfig != nil && s.FestivalConfig.Protocols != "" {
+			protocols = s.FestivalConfig.Protocols
+		}
+	}
+	names, err := drones.ParseProtocolMix(protocols)
+	if err != nil {
+		fmt.Printf("Warning: Could not set drone protocols %q: %v\n", protocols, err)
+		return
+	}
+	s.protocolMix = names
 	for i := range s.Drones {
-		s.Drones[i].UpdateProtocole(newprot)
+		protocol, _ := drones.NewProtocol(s.protocolFor(i))
+		s.Drones[i].SetProtocol(protocol)
 	}
 }
 
+// protocolFor returns the protocol of the i-th drone of the fleet.
+func (s *Simulation) protocolFor(i int) string {
+	if len(s.protocolMix) == 0 {
+		return DEFAULT_PROTOCOL
+	}
+	return s.protocolMix[i%len(s.protocolMix)]
+}
+
 func (s *Simulation) UpdateCrowdSize(newSize int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -811,12 +1020,15 @@ func (s *Simulation) UpdateCrowdSize(newSize int) {
 	currentSize := len(s.Persons)
 
 	if newSize > currentSize {
+		profile := s.attendanceProfile()
+		scans := attendanceScans(profile)
+		scans.skip(currentSize)
 		for i := currentSize; i < newSize; i++ {
 			member := persons.NewCrowdMember(i,
-				models.Position{X: 0, Y: float64(rand.Intn(s.Map.Height))},
-				s.DefaultDistressProbability, LIFESPAN, s.Map.Width, s.Map.Height, s.MoveChan, s.DeadChan, s.ExitChan)
+				models.Position{X: -1, Y: -1},
+				s.DefaultDistressProbability, LIFESPAN, s.Map.Width, s.Map.Height, s.MoveChan, s.DeadChan, s.ExitChan, s.GetWeather, s.GetCurrentTick)
+			s.schedulePerson(&member, profile, scans)
 			s.Persons = append(s.Persons, member)
-			s.Map.AddCrowdMember(&s.Persons[len(s.Persons)-1])
 		}
 	} else if newSize < currentSize {
 		personsToRemove := currentSize - newSize
@@ -899,28 +1111,26 @@ func (s *Simulation) calculateDroneNetwork() models.DroneNetwork {
 		DronePositions: make([]models.Position, len(s.Drones)),
 	}
 
-	for i, drone := range s.Drones {
-		network.DronePositions[i] = drone.Position
+	for i := range s.Drones {
+		network.DronePositions[i] = s.Drones[i].TruePosition()
 	}
 
-	for i, drone1 := range s.Drones {
-		for j, drone2 := range s.Drones {
+	for i := range s.Drones {
+		for j := range s.Drones {
 			if i >= j {
 				continue
 			}
-			dist := drone1.Position.CalculateDistance(drone2.Position)
-			if dist <= float64(s.DroneCommRange) {
-				network.DroneConnections = append(network.DroneConnections, drone1.Position)
-				network.DroneConnections = append(network.DroneConnections, drone2.Position)
+			if s.linked(&s.Drones[i], &s.Drones[j]) {
+				network.DroneConnections = append(network.DroneConnections, network.DronePositions[i])
+				network.DroneConnections = append(network.DroneConnections, network.DronePositions[j])
 			}
 		}
 	}
 
-	for _, drone := range s.Drones {
+	for i := range s.Drones {
 		for _, rp := range s.RescuePoints {
-			dist := drone.Position.CalculateDistance(rp.Position)
-			if dist <= float64(s.DroneCommRange) {
-				network.RescueConnections = append(network.RescueConnections, drone.Position)
+			if s.rescuePointLinked(&s.Drones[i], rp) {
+				network.RescueConnections = append(network.RescueConnections, network.DronePositions[i])
 				network.RescueConnections = append(network.RescueConnections, rp.Position)
 			}
 		}
@@ -936,13 +1146,14 @@ func (s *Simulation) GetStatistics() SimulationStatistics {
 	totalPeople := len(s.Persons)
 	inDistress := s.CountCrowdMembersInDistress()
 
-	totalBattery := 0.0
+	totalBattery, seenArea := 0.0, 0.0
 	droneCount := len(s.Drones)
 
 	if droneCount > 0 {
 		for _, d := range s.Drones {
-			if !d.IsCharging {
+			if !d.Landed() && !d.Crashed {
 				totalBattery += d.Battery
+				seenArea += seeArea(&d)
 			} else {
 				droneCount--
 			}
@@ -953,8 +1164,36 @@ func (s *Simulation) GetStatistics() SimulationStatistics {
 	if droneCount > 0 {
 		avgBattery = totalBattery / float64(droneCount)
 		totalArea := float64(s.Map.Width * s.Map.Height)
-		droneArea := math.Pi * float64(s.DroneSeeRange*s.DroneSeeRange)
-		coverage = math.Min((float64(droneCount)*droneArea/totalArea)*100, 100)
+		coverage = math.Min(seenArea/totalArea*100, 100)
+	}
+
+	var totalHydration float64
+	var present, dehydrated, intoxicated, lost int
+	for _, p := range s.Persons {
+		if !p.StillInSim || p.Dead {
+			continue
+		}
+		present++
+		if p.Lost {
+			lost++
+		}
+		totalHydration += p.Needs.Hydration
+		if p.Needs.Hydration < persons.DEHYDRATION_THRESHOLD {
+			dehydrated++
+		}
+		if p.Needs.Alcohol > persons.INTOXICATION_THRESHOLD {
+			intoxicated++
+		}
+	}
+	var maxDensity, maxPressure float64
+	for _, cell := range s.CrowdField {
+		maxDensity = math.Max(maxDensity, cell.Density)
+		maxPressure = math.Max(maxPressure, cell.Pressure)
+	}
+
+	var avgHydration float64
+	if present > 0 {
+		avgHydration = totalHydration / float64(present)
 	}
 
 	return SimulationStatistics{
@@ -966,6 +1205,21 @@ func (s *Simulation) GetStatistics() SimulationStatistics {
 		AverageCoverage: coverage,
 		PeopleDensity:   s.calculatePeopleDensity(),
 		DroneNetwork:    s.calculateDroneNetwork(),
+		Weather:         s.GetWeather(),
+		PresentPeople:   s.CountPresentPeople(),
+		Evacuating:      s.IsEvacuating(),
+		EvacuationTime:  s.evacuationTime(),
+		MaxDensity:      maxDensity,
+		MaxPressure:     maxPressure,
+		CrowdAlerts:     s.GetCrowdAlerts(),
+		LostPeople:      lost,
+		SearchMissions:  s.GetSearchMissions(),
+		CoverageGap:     s.coverageGap,
+		Airborne:        droneCount,
+		ActiveFaults:    s.activeFaultCount(),
+		AvgHydration:    avgHydration,
+		Dehydrated:      dehydrated,
+		Intoxicated:     intoxicated,
 	}
 }
 
@@ -984,7 +1238,7 @@ func (s *Simulation) GetAvailablePOIs() map[models.POIType][]models.Position {
 func (s *Simulation) InitializeRescuePoints() {
 	fmt.Printf("[SIMULATION] Initializing RescuePoints\n")
 	for i, pos := range s.poiMap[models.MedicalTent] {
-		rp := rescue.NewRescuePoint(i, pos, s.SavePeopleByRescuerChan, s.debug)
+		rp := rescue.NewRescuePoint(i, pos, s.getPOICapacity(pos), s.SavePeopleByRescuerChan, s.debug)
 		s.RescuePoints[pos] = rp
 	}
 
@@ -1000,6 +1254,7 @@ func (s *Simulation) InitializeRescuePoints() {
 		rp.Start() // Démarrer les goroutines de gestion
 	}
 
+	s.initKitStocks()
 	fmt.Printf("[SIMULATION] Initialized %d rescue points\n", len(s.RescuePoints))
 }
 
@@ -1014,15 +1269,15 @@ func (s *Simulation) calculateSingleDroneNetwork(targetDrone *drones.Drone) dron
 	dfs = func(currentDrone *drones.Drone) {
 		visited[currentDrone.ID] = true
 
-		for i, otherDrone := range s.Drones {
+		for i := range s.Drones {
+			otherDrone := &s.Drones[i]
 			if otherDrone.ID == currentDrone.ID {
 				continue
 			}
 
-			dist := currentDrone.Position.CalculateDistance(otherDrone.Position)
-			if dist <= float64(s.DroneCommRange) && !visited[otherDrone.ID] {
-				network.Drones = append(network.Drones, &s.Drones[i])
-				dfs(&otherDrone)
+			if !visited[otherDrone.ID] && s.linked(currentDrone, otherDrone) {
+				network.Drones = append(network.Drones, otherDrone)
+				dfs(otherDrone)
 			}
 		}
 	}
@@ -1031,14 +1286,12 @@ func (s *Simulation) calculateSingleDroneNetwork(targetDrone *drones.Drone) dron
 }
 
 func (s *Simulation) GetRealFestivalTime() string {
-	timeBegin := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
-	return timeBegin.Add(time.Minute * time.Duration(s.currentTick)).Format("15:04")
+	return s.FestivalClock(s.currentTick).Format("15:04")
 }
 
 func (s *Simulation) GetRemaningFestivalTime() string {
-	timeBegin := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
-	timeNow := timeBegin.Add(time.Minute * time.Duration(s.currentTick))
-	timeEnd := timeBegin.Add(time.Minute * time.Duration(s.festivalTotalTicks))
+	timeNow := s.FestivalClock(s.currentTick)
+	timeEnd := s.FestivalClock(s.festivalTotalTicks)
 	if s.currentTick >= s.festivalTotalTicks {
 		return "Festival ended"
 	}
@@ -0,0 +1,118 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	DEFAULT_WEATHER  = "mild"     // Scenario of configs/weather loaded by NewSimulation
+	CONSTANT_WEATHER = "constant" // Name of the weather when DEFAULT_WEATHER cannot be loaded: models.DefaultWeather at every tick
+)
+
+// Weather is the weather time series of a festival.
+type Weather struct {
+	Name    string
+	samples []models.WeatherSample
+}
+
+func NewWeather(config models.WeatherConfig) *Weather {
+	samples := make([]models.WeatherSample, len(config.Samples))
+	copy(samples, config.Samples)
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Tick < samples[j].Tick
+	})
+	return &Weather{Name: config.Name, samples: samples}
+}
+
+// LoadWeatherConfig loads a weather time series from a JSON file
+func LoadWeatherConfig(configPath string) (*models.WeatherConfig, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path: %v", err)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading weather file: %v", err)
+	}
+
+	var config models.WeatherConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing weather file: %v", err)
+	}
+	if len(config.Samples) == 0 {
+		return nil, fmt.Errorf("weather file has no samples")
+	}
+
+	return &config, nil
+}
+
+// At returns the conditions at the given tick, linearly interpolated between samples.
+func (w *Weather) At(tick int) models.WeatherConditions {
+	if w == nil || len(w.samples) == 0 {
+		return models.DefaultWeather
+	}
+	if tick <= w.samples[0].Tick {
+		return w.samples[0].WeatherConditions
+	}
+	for i := 1; i < len(w.samples); i++ {
+		next := w.samples[i]
+		if tick > next.Tick {
+			continue
+		}
+		prev := w.samples[i-1]
+		ratio := float64(tick-prev.Tick) / float64(next.Tick-prev.Tick)
+		lerp := func(a, b float64) float64 { return a + (b-a)*ratio }
+		return models.WeatherConditions{
+			Temperature: lerp(prev.Temperature, next.Temperature),
+			Humidity:    lerp(prev.Humidity, next.Humidity),
+			Rain:        lerp(prev.Rain, next.Rain),
+			Wind:        lerp(prev.Wind, next.Wind),
+		}
+	}
+	return w.samples[len(w.samples)-1].WeatherConditions
+}
+
+// loadWeather reads the scenario configs/weather/<name>.json.
+func loadWeather(nomWeather string) (*Weather, error) {
+	configPath := "configs/weather/" + nomWeather + ".json"
+	config, err := LoadWeatherConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load weather from %s: %v", configPath, err)
+	}
+	if config.Name == "" {
+		config.Name = nomWeather
+	}
+	return NewWeather(*config), nil
+}
+
+// defaultWeather returns the DEFAULT_WEATHER scenario, or a constant models.DefaultWeather named
+// CONSTANT_WEATHER when its file cannot be read, so that the results are not labelled with a
+// scenario that was not played.
+func defaultWeather() *Weather {
+	weather, err := loadWeather(DEFAULT_WEATHER)
+	if err != nil {
+		fmt.Printf("Warning: %v, constant weather used\n", err)
+		return NewWeather(models.WeatherConfig{Name: CONSTANT_WEATHER})
+	}
+	return weather
+}
+
+func (s *Simulation) UpdateWeather(nomWeather string) {
+	weather, err := loadWeather(nomWeather)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	s.Weather = weather
+	fmt.Printf("Successfully loaded weather scenario %s\n", s.Weather.Name)
+}
+
+func (s *Simulation) GetWeather() models.WeatherConditions {
+	return s.Weather.At(s.currentTick)
+}
@@ -0,0 +1,458 @@
+package simulation
+
+import (
+	"UTC_IA04/pkg/entities/drones"
+	"UTC_IA04/pkg/models"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	ZONE_REBALANCE_INTERVAL = 10  // Ticks between two rebalances when the patrolling fleet does not change
+	ZONE_DENSITY_WEIGHT     = 4.0 // Extra weight of the densest cells of the DensityGrid
+	ZONE_LEAVE_TICKS        = 30  // Ticks a drone away from its zone keeps it, a short mission does not hand it over
+)
+
+// CoverageStats measures how much of the festival is seen by no drone.
+type CoverageStats struct {
+	Ticks       int
+	AreaGap     float64 // Average share of the map seen by no drone
+	PeakAreaGap float64
+	CrowdGap    float64 // Average share of the present people seen by no drone
+	Rebalances  int     // Patrol zones recomputed during the run
+	Handovers   int     // Zones handed over to the neighbours of a drone leaving or joining the fleet
+}
+
+// zoneNode is a cut of the tree splitting the map among the patrolling drones, or a leaf holding
+// the zone of its drones. The tree is kept between the rebalances: the cuts move with the crowd,
+// and a drone leaving or joining the fleet only changes the zones next to its own.
+type zoneNode struct {
+	alongX   bool
+	children [2]*zoneNode // nil for a leaf
+	droneIDs []int        // Drones sharing the zone of a leaf
+	area     models.MyWatch
+}
+
+func (n *zoneNode) leaf() bool {
+	return n.children[0] == nil
+}
+
+// drones returns the IDs of the drones of the subtree.
+func (n *zoneNode) drones() []int {
+	if n.leaf() {
+		return n.droneIDs
+	}
+	return append(append([]int{}, n.children[0].drones()...), n.children[1].drones()...)
+}
+
+// UpdateZoneRebalancing enables or disables the online re-partitioning of the patrol zones,
+// disabled drones keep the zones given at their creation.
+func (s *Simulation) UpdateZoneRebalancing(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ZoneRebalancing = enabled
+	s.zoneTree = nil
+}
+
+// isPatrolling tells if the drone is available to watch a zone, and not holding a relay post.
+func isPatrolling(d *drones.Drone) bool {
+	return isAvailable(d) && d.RelayPost == nil
+}
+
+// rebalanceZones keeps the map split among the patrolling drones: the zone of a drone leaving the
+// fleet goes to its neighbours, a drone joining it takes half of the closest zone, and the cuts
+// periodically move to follow the crowd.
+func (s *Simulation) rebalanceZones() {
+	if !s.ZoneRebalancing {
+		return
+	}
+	fleet := s.zoneFleet()
+	if len(fleet) == 0 {
+		return
+	}
+	weights := s.zoneWeights()
+	if s.zoneTree == nil {
+		s.zoneTree = s.buildZoneTree(fleet, weights, 0, 0, s.Map.Width, s.Map.Height)
+		s.layoutZones(s.zoneTree, weights, s.Map.Width, s.Map.Height)
+		s.lastRebalanceTick = s.currentTick
+		s.CoverageStats.Rebalances++
+		return
+	}
+
+	members := make(map[int]*drones.Drone, len(fleet))
+	for _, d := range fleet {
+		members[d.ID] = d
+	}
+	for _, id := range s.zoneTree.drones() {
+		if _, ok := members[id]; !ok {
+			s.zoneTree = s.removeFromZones(s.zoneTree, id)
+			delete(s.zoneLeftTick, id)
+		}
+		delete(members, id)
+	}
+	joined := make([]*zoneNode, 0, len(members))
+	for _, d := range fleet {
+		if _, joining := members[d.ID]; !joining {
+			continue
+		}
+		s.CoverageStats.Handovers++
+		if s.zoneTree == nil {
+			s.zoneTree = &zoneNode{droneIDs: []int{d.ID}, area: s.mapZone()}
+			joined = append(joined, s.zoneTree)
+			continue
+		}
+		joined = append(joined, insertInZones(s.zoneTree, d))
+	}
+
+	if s.currentTick-s.lastRebalanceTick >= ZONE_REBALANCE_INTERVAL {
+		s.lastRebalanceTick = s.currentTick
+		s.CoverageStats.Rebalances++
+		s.layoutZones(s.zoneTree, weights, s.Map.Width, s.Map.Height)
+		return
+	}
+	for _, node := range joined {
+		s.layoutZones(node, weights, s.Map.Width, s.Map.Height)
+	}
+}
+
+// zoneFleet returns the drones sharing the zones. A drone of the fleet away on a mission keeps its
+// zone for ZONE_LEAVE_TICKS, a relay, a crashed or an empty drone leaves it at once.
+func (s *Simulation) zoneFleet() []*drones.Drone {
+	inTree := make(map[int]bool)
+	if s.zoneTree != nil {
+		for _, id := range s.zoneTree.drones() {
+			inTree[id] = true
+		}
+	}
+	fleet := make([]*drones.Drone, 0, len(s.Drones))
+	for i := range s.Drones {
+		d := &s.Drones[i]
+		if isPatrolling(d) {
+			delete(s.zoneLeftTick, d.ID)
+			fleet = append(fleet, d)
+			continue
+		}
+		if !inTree[d.ID] || d.RelayPost != nil || d.Battery <= 0 || d.Crashed {
+			continue
+		}
+		left, away := s.zoneLeftTick[d.ID]
+		if !away {
+			s.zoneLeftTick[d.ID] = s.currentTick
+			left = s.currentTick
+		}
+		if s.currentTick-left < ZONE_LEAVE_TICKS {
+			fleet = append(fleet, d)
+		}
+	}
+	return fleet
+}
+
+func (s *Simulation) mapZone() models.MyWatch {
+	return models.MyWatch{
+		CornerBottomLeft: models.Position{X: 0, Y: 0},
+		CornerTopRight:   models.Position{X: float64(s.Map.Width), Y: float64(s.Map.Height)},
+	}
+}
+
+// removeFromZones takes the drone out of the subtree: the sibling of its leaf takes the zone of
+// their parent, only the zones along their border grow.
+func (s *Simulation) removeFromZones(node *zoneNode, id int) *zoneNode {
+	if node.leaf() {
+		ids := make([]int, 0, len(node.droneIDs))
+		for _, other := range node.droneIDs {
+			if other != id {
+				ids = append(ids, other)
+			}
+		}
+		node.droneIDs = ids
+		if len(ids) == 0 {
+			return nil
+		}
+		return node
+	}
+	for i, child := range node.children {
+		node.children[i] = s.removeFromZones(child, id)
+	}
+	for i, child := range node.children {
+		if child == nil {
+			sibling := node.children[1-i]
+			s.CoverageStats.Handovers++
+			s.stretchZones(sibling, sibling.area, node.area)
+			return sibling
+		}
+	}
+	return node
+}
+
+// stretchZones moves the sides of the subtree's zones lying on the border from to the border to,
+// the cuts inside the subtree stay in place.
+func (s *Simulation) stretchZones(node *zoneNode, from, to models.MyWatch) {
+	area := node.area
+	if area.CornerBottomLeft.X == from.CornerBottomLeft.X {
+		area.CornerBottomLeft.X = to.CornerBottomLeft.X
+	}
+	if area.CornerBottomLeft.Y == from.CornerBottomLeft.Y {
+		area.CornerBottomLeft.Y = to.CornerBottomLeft.Y
+	}
+	if area.CornerTopRight.X == from.CornerTopRight.X {
+		area.CornerTopRight.X = to.CornerTopRight.X
+	}
+	if area.CornerTopRight.Y == from.CornerTopRight.Y {
+		area.CornerTopRight.Y = to.CornerTopRight.Y
+	}
+	node.area = area
+	if node.leaf() {
+		s.assignZone(node)
+		return
+	}
+	for _, child := range node.children {
+		s.stretchZones(child, from, to)
+	}
+}
+
+// insertInZones splits the leaf whose zone holds the drone, or is the closest to it, between the
+// drone and the drones of the leaf. It returns the split node, to lay out.
+func insertInZones(node *zoneNode, d *drones.Drone) *zoneNode {
+	for !node.leaf() {
+		next := node.children[0]
+		first, second := zoneCentre(next.area), zoneCentre(node.children[1].area)
+		if second.CalculateDistance(d.Position) < first.CalculateDistance(d.Position) {
+			next = node.children[1]
+		}
+		for _, child := range node.children {
+			if zoneContains(child.area, d.Position) {
+				next = child
+			}
+		}
+		node = next
+	}
+	width := node.area.CornerTopRight.X - node.area.CornerBottomLeft.X
+	height := node.area.CornerTopRight.Y - node.area.CornerBottomLeft.Y
+	if width < 2 && height < 2 {
+		node.droneIDs = append(node.droneIDs, d.ID)
+		return node
+	}
+	alongX := width >= height
+	held := &zoneNode{droneIDs: node.droneIDs, area: node.area}
+	joined := &zoneNode{droneIDs: []int{d.ID}, area: node.area}
+	centre := zoneCentre(node.area)
+	children := [2]*zoneNode{held, joined}
+	if (alongX && d.Position.X < centre.X) || (!alongX && d.Position.Y < centre.Y) {
+		children = [2]*zoneNode{joined, held}
+	}
+	*node = zoneNode{alongX: alongX, children: children, area: node.area}
+	return node
+}
+
+func (s *Simulation) findDrone(droneID int) *drones.Drone {
+	for i := range s.Drones {
+		if s.Drones[i].ID == droneID {
+			return &s.Drones[i]
+		}
+	}
+	return nil
+}
+
+func zoneContains(zone models.MyWatch, pos models.Position) bool {
+	return pos.X >= zone.CornerBottomLeft.X && pos.X < zone.CornerTopRight.X &&
+		pos.Y >= zone.CornerBottomLeft.Y && pos.Y < zone.CornerTopRight.Y
+}
+
+func zoneCentre(zone models.MyWatch) models.Position {
+	return models.Position{
+		X: math.Floor((zone.CornerBottomLeft.X + zone.CornerTopRight.X) / 2),
+		Y: math.Floor((zone.CornerBottomLeft.Y + zone.CornerTopRight.Y) / 2),
+	}
+}
+
+// zoneWeights returns the weight of each cell, indexed by [x][y]: crowded cells count more
+// so that the drones over the crowd get smaller zones.
+func (s *Simulation) zoneWeights() [][]float64 {
+	density := s.calculatePeopleDensity()
+	gridSize := len(density.Grid)
+	cellWidth := float64(s.Map.Width) / float64(gridSize)
+	cellHeight := float64(s.Map.Height) / float64(gridSize)
+
+	weights := make([][]float64, s.Map.Width)
+	for x := range weights {
+		weights[x] = make([]float64, s.Map.Height)
+		for y := range weights[x] {
+			gridX := min(int(float64(x)/cellWidth), gridSize-1)
+			gridY := min(int(float64(y)/cellHeight), gridSize-1)
+			weights[x][y] = 1 + ZONE_DENSITY_WEIGHT*density.Grid[gridY][gridX]
+		}
+	}
+	return weights
+}
+
+// buildZoneTree cuts the rectangle [x1, x2[ x [y1, y2[ along its longest side, the drones being
+// split by position, so that neighbouring drones get neighbouring zones.
+func (s *Simulation) buildZoneTree(fleet []*drones.Drone, weights [][]float64, x1, y1, x2, y2 int) *zoneNode {
+	if len(fleet) == 1 || (x2-x1 < 2 && y2-y1 < 2) {
+		ids := make([]int, len(fleet))
+		for i, d := range fleet {
+			ids[i] = d.ID
+		}
+		return &zoneNode{droneIDs: ids}
+	}
+	alongX := cutAlongX(x2-x1 >= y2-y1, x1, y1, x2, y2)
+	sort.SliceStable(fleet, func(i, j int) bool {
+		if alongX {
+			return fleet[i].Position.X < fleet[j].Position.X
+		}
+		return fleet[i].Position.Y < fleet[j].Position.Y
+	})
+	half := len(fleet) / 2
+	cut := zoneCut(weights, alongX, float64(half)/float64(len(fleet)), x1, y1, x2, y2)
+	node := &zoneNode{alongX: alongX}
+	if alongX {
+		node.children = [2]*zoneNode{
+			s.buildZoneTree(fleet[:half], weights, x1, y1, cut, y2),
+			s.buildZoneTree(fleet[half:], weights, cut, y1, x2, y2),
+		}
+	} else {
+		node.children = [2]*zoneNode{
+			s.buildZoneTree(fleet[:half], weights, x1, y1, x2, cut),
+			s.buildZoneTree(fleet[half:], weights, x1, cut, x2, y2),
+		}
+	}
+	return node
+}
+
+// layoutZones moves the cuts of the subtree inside its area so that each side carries a weight
+// proportional to its number of drones, and gives the leaves' zones to their drones.
+func (s *Simulation) layoutZones(node *zoneNode, weights [][]float64, width, height int) {
+	x1 := max(int(node.area.CornerBottomLeft.X), 0)
+	y1 := max(int(node.area.CornerBottomLeft.Y), 0)
+	x2 := min(int(node.area.CornerTopRight.X), width)
+	y2 := min(int(node.area.CornerTopRight.Y), height)
+	if node == s.zoneTree {
+		x1, y1, x2, y2 = 0, 0, width, height
+	}
+	node.area = models.MyWatch{
+		CornerBottomLeft: models.Position{X: float64(x1), Y: float64(y1)},
+		CornerTopRight:   models.Position{X: float64(x2), Y: float64(y2)},
+	}
+	if node.leaf() || (x2-x1 < 2 && y2-y1 < 2) {
+		s.assignZone(node)
+		return
+	}
+	alongX := cutAlongX(node.alongX, x1, y1, x2, y2)
+	left := len(node.children[0].drones())
+	cut := zoneCut(weights, alongX, float64(left)/float64(left+len(node.children[1].drones())), x1, y1, x2, y2)
+	first, second := node.area, node.area
+	if alongX {
+		first.CornerTopRight.X, second.CornerBottomLeft.X = float64(cut), float64(cut)
+	} else {
+		first.CornerTopRight.Y, second.CornerBottomLeft.Y = float64(cut), float64(cut)
+	}
+	node.children[0].area, node.children[1].area = first, second
+	s.layoutZones(node.children[0], weights, width, height)
+	s.layoutZones(node.children[1], weights, width, height)
+}
+
+// assignZone gives the zone of the node to the drones of its subtree.
+func (s *Simulation) assignZone(node *zoneNode) {
+	for _, id := range node.drones() {
+		d := s.findDrone(id)
+		if d != nil && d.AssignZone(node.area) && s.debug {
+			fmt.Printf("[DRONE %d] - New patrol zone (%.0f, %.0f) - (%.0f, %.0f)\n", d.ID,
+				node.area.CornerBottomLeft.X, node.area.CornerBottomLeft.Y, node.area.CornerTopRight.X, node.area.CornerTopRight.Y)
+		}
+	}
+}
+
+// cutAlongX keeps the preferred direction of the cut unless the rectangle is too narrow for it.
+func cutAlongX(alongX bool, x1, y1, x2, y2 int) bool {
+	if alongX && x2-x1 < 2 {
+		return false
+	} else if !alongX && y2-y1 < 2 {
+		return true
+	}
+	return alongX
+}
+
+// zoneCut returns where to cut the rectangle so that the first side carries the given share of
+// its weight, at least one cell on each side.
+func zoneCut(weights [][]float64, alongX bool, share float64, x1, y1, x2, y2 int) int {
+	// Poids de chaque tranche perpendiculaire à la coupe
+	start, end := y1, y2
+	if alongX {
+		start, end = x1, x2
+	}
+	slices := make([]float64, end-start)
+	total := 0.0
+	for i := range slices {
+		if alongX {
+			for y := y1; y < y2; y++ {
+				slices[i] += weights[start+i][y]
+			}
+		} else {
+			for x := x1; x < x2; x++ {
+				slices[i] += weights[x][start+i]
+			}
+		}
+		total += slices[i]
+	}
+
+	target := total * share
+	cut, acc := start+1, slices[0]
+	for cut < end-1 && math.Abs(acc+slices[cut-start]-target) < math.Abs(acc-target) {
+		acc += slices[cut-start]
+		cut++
+	}
+	return cut
+}
+
+// collectCoverageStats measures the cells and the present people out of sight of every flying drone.
+func (s *Simulation) collectCoverageStats() {
+	flying := make([]*drones.Drone, 0, len(s.Drones))
+	for i := range s.Drones {
+		if s.Drones[i].Airborne() {
+			flying = append(flying, &s.Drones[i])
+		}
+	}
+	seen := func(pos models.Position) bool {
+		for _, d := range flying {
+			dronePos := d.TruePosition()
+			if dronePos.CalculateDistance(pos) <= float64(d.DroneSeeRange) {
+				return true
+			}
+		}
+		return false
+	}
+
+	unseenCells := 0
+	for x := 0; x < s.Map.Width; x++ {
+		for y := 0; y < s.Map.Height; y++ {
+			if !seen(models.Position{X: float64(x), Y: float64(y)}) {
+				unseenCells++
+			}
+		}
+	}
+	areaGap := float64(unseenCells) / float64(s.Map.Width*s.Map.Height)
+
+	present, unseenPeople := 0, 0
+	for i := range s.Persons {
+		p := &s.Persons[i]
+		if !p.Arrived || !p.StillInSim || p.IsDead() || p.Position.X < 0 {
+			continue
+		}
+		present++
+		if !seen(p.Position) {
+			unseenPeople++
+		}
+	}
+	crowdGap := 0.0
+	if present > 0 {
+		crowdGap = float64(unseenPeople) / float64(present)
+	}
+
+	stats := &s.CoverageStats
+	stats.Ticks++
+	stats.AreaGap += (areaGap - stats.AreaGap) / float64(stats.Ticks)
+	stats.CrowdGap += (crowdGap - stats.CrowdGap) / float64(stats.Ticks)
+	stats.PeakAreaGap = math.Max(stats.PeakAreaGap, areaGap)
+	s.coverageGap = areaGap
+}